@@ -0,0 +1,66 @@
+// issue-token は AUTH_SECRET で署名した認証トークンを発行します。
+// 外部の認証基盤を用意していない環境で、APIの呼び出しに使うトークンを作成するために使用します。
+//
+//	go run ./cmd/issue-token -user user-1 -name "山田"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+func main() {
+	userID := flag.String("user", "", "ユーザーID（必須）")
+	displayName := flag.String("name", "", "表示名")
+	roles := flag.String("roles", "", "カンマ区切りのロール")
+	ttl := flag.Duration("ttl", 0, "有効期間（未指定の場合は AUTH_TOKEN_TTL または 24h）")
+	flag.Parse()
+
+	if *userID == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	secret := os.Getenv("AUTH_SECRET")
+	if secret == "" {
+		logging.Error("AUTH_SECRET が設定されていません。サーバーと同じ値を設定してください。")
+		os.Exit(1)
+	}
+	issuer := os.Getenv("AUTH_ISSUER")
+	if issuer == "" {
+		issuer = "zenn-ai-hackathon"
+	}
+	if *ttl == 0 {
+		if v := os.Getenv("AUTH_TOKEN_TTL"); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				logging.Error("AUTH_TOKEN_TTL が不正です: %v", err)
+				os.Exit(1)
+			}
+			*ttl = parsed
+		}
+	}
+
+	signer, err := auth.NewJWTSigner([]byte(secret), issuer, *ttl)
+	if err != nil {
+		logging.Error("署名の準備に失敗しました: %v", err)
+		os.Exit(1)
+	}
+
+	identity := &auth.Identity{UserID: *userID, DisplayName: *displayName}
+	if *roles != "" {
+		identity.Roles = strings.Split(*roles, ",")
+	}
+	token, err := signer.Sign(identity)
+	if err != nil {
+		logging.Error("トークンの発行に失敗しました: %v", err)
+		os.Exit(1)
+	}
+	fmt.Println(token)
+}
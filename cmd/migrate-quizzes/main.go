@@ -0,0 +1,62 @@
+// migrate-quizzes は metadata/quizzes.json に保存された旧形式のクイズデータを
+// クイズごとのオブジェクト（metadata/quizzes/<id>.json）とインデックスに分割し、
+// インデックスのエントリをクイズ本体の内容（作成者・タグなど）で作り直します。
+// 何度実行しても結果は変わりません。
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/config"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// legacyMigrator は旧形式データの移行に対応したストレージクライアントです
+type legacyMigrator interface {
+	MigrateLegacyQuizzes(ctx context.Context) (int, error)
+	RebuildIndex(ctx context.Context) (int, error)
+}
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		logging.Error("設定の読み込みに失敗しました: %v", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	var storageClient storage.StorageClient
+	switch cfg.StorageBackend {
+	case config.StorageBackendLocal:
+		storageClient, err = storage.NewLocalClient(cfg.LocalStorageDir)
+	default:
+		storageClient, err = storage.NewClient(ctx, cfg.BucketName)
+	}
+	if err != nil {
+		logging.Error("ストレージクライアントの初期化に失敗しました: %v", err)
+		os.Exit(1)
+	}
+
+	migrator, ok := storageClient.(legacyMigrator)
+	if !ok {
+		logging.Error("このストレージクライアントは移行に対応していません: %T", storageClient)
+		os.Exit(1)
+	}
+
+	migrated, err := migrator.MigrateLegacyQuizzes(ctx)
+	if err != nil {
+		logging.Error("クイズデータの移行に失敗しました: %v", err)
+		os.Exit(1)
+	}
+	logging.Info("クイズデータの移行が完了しました。移行数: %d", migrated)
+
+	indexed, err := migrator.RebuildIndex(ctx)
+	if err != nil {
+		logging.Error("インデックスの再構築に失敗しました: %v", err)
+		os.Exit(1)
+	}
+	logging.Info("インデックスの再構築が完了しました。クイズ数: %d", indexed)
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,8 +13,11 @@ import (
 	"time"
 
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/ai"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/config"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/imaging"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/moderation"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/server"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
@@ -81,29 +85,120 @@ func main() {
 	defer cancel()
 
 	// AIクライアントの初期化
-	aiClient, err := ai.NewClient(cfg.ProjectID, cfg.Location)
+	aiClient, err := ai.NewProvider(ctx, cfg.AIProvider, ai.ProviderConfig{
+		ProjectID: cfg.ProjectID,
+		Location:  cfg.Location,
+		Model:     cfg.AIModel,
+		Endpoint:  cfg.AIEndpoint,
+		APIKey:    cfg.AIAPIKey,
+	})
 	if err != nil {
 		logging.Error("AIクライアントの初期化に失敗しました。")
 		dumpError(err)
 		os.Exit(1)
 	}
-	logging.Info("AIクライアントを初期化しました。")
+	// 一時的なエラーはやり直し、障害が続く場合は呼び出しを遮断する
+	aiClient = ai.NewResilientClient(aiClient,
+		ai.WithMaxRetries(cfg.AIMaxRetries),
+		ai.WithCallTimeout(cfg.AICallTimeout),
+	)
+	// 生成された解釈がルールを満たさない場合は、上限まで生成し直す
+	aiClient = ai.NewValidatingClient(aiClient, cfg.AIMaxAttempts)
+	logging.Info("AIクライアントを初期化しました。プロバイダー: %s, 最大試行回数: %d, 生成全体の上限: %s", cfg.AIProvider, cfg.AIMaxAttempts, cfg.AIGenerateTimeout)
 
 	// ストレージクライアントの初期化
-	storageClient, err := storage.NewClient(ctx, cfg.BucketName)
+	var storageClient storage.StorageClient
+	switch cfg.StorageBackend {
+	case config.StorageBackendLocal:
+		storageClient, err = storage.NewLocalClient(cfg.LocalStorageDir)
+	default:
+		storageClient, err = storage.NewClient(ctx, cfg.BucketName,
+			storage.WithSignedURLTTL(cfg.SignedURLTTL),
+			storage.WithSigningMethod(storage.SigningMethod(cfg.SignedURLMethod)),
+		)
+	}
 	if err != nil {
 		logging.Error("ストレージクライアントの初期化に失敗しました。")
 		dumpError(err)
 		os.Exit(1)
 	}
-	logging.Info("ストレージクライアントを初期化しました。")
+	logging.Info("ストレージクライアントを初期化しました。バックエンド: %s, 画像URL: %s", cfg.StorageBackend, cfg.SignedURLMethod)
 
 	// サービスの初期化
-	quizService := service.NewQuizService(aiClient, storageClient)
+	if cfg.AnswerSecret == "" {
+		// Cloud Storage では設定の検証で必須にしているため、ここに来るのはローカルストレージの場合のみ
+		logging.Warn("ANSWER_SECRET が未設定です。再起動すると進行中のクイズの選択肢IDが無効になります。")
+	}
+	quizOpts := []service.Option{
+		service.WithAnswerSecret([]byte(cfg.AnswerSecret)),
+		service.WithModerator(moderation.NewDefaultClassifier()),
+		service.WithGenerateTimeout(cfg.AIGenerateTimeout),
+		// 署名付きURLを発行できない場合は、サーバーの画像プロキシで配信する
+		service.WithImageBaseURL(cfg.PublicBaseURL),
+	}
+	banStore, _ := storageClient.(storage.BanStore)
+	if banStore != nil {
+		quizOpts = append(quizOpts, service.WithBanStore(banStore))
+	}
+	quizService := service.NewQuizService(aiClient, storageClient, quizOpts...)
 	logging.Info("クイズサービスを初期化しました。")
 
+	// 認証の初期化
+	authSecret := []byte(cfg.AuthSecret)
+	if len(authSecret) == 0 {
+		// Cloud Storage では設定の検証で必須にしているため、ここに来るのはローカルストレージの場合のみ
+		logging.Warn("AUTH_SECRET が未設定です。起動ごとにランダムな鍵を使用するため、再起動すると発行済みのトークンは無効になります。")
+		authSecret = make([]byte, 32)
+		if _, err := rand.Read(authSecret); err != nil {
+			logging.Error("認証の秘密鍵の生成に失敗しました。")
+			dumpError(err)
+			os.Exit(1)
+		}
+	}
+	verifier, err := auth.NewJWTSigner(authSecret, cfg.AuthIssuer, cfg.AuthTokenTTL)
+	if err != nil {
+		logging.Error("認証の初期化に失敗しました。")
+		dumpError(err)
+		os.Exit(1)
+	}
+	logging.Info("認証を初期化しました。発行者: %s", cfg.AuthIssuer)
+
+	// HEIC は変換コマンドがある環境でのみ受け付ける
+	if err := imaging.RegisterHEICConverter(cfg.HEICConverter); err != nil {
+		logging.Warn("HEIC の画像は受け付けません: %v", err)
+	}
+
+	// 画像の検証の初期化
+	imageValidator, err := service.NewImageValidator(server.MaxUploadSize, cfg.AllowedImageTypes)
+	if err != nil {
+		logging.Error("画像の検証の初期化に失敗しました。")
+		dumpError(err)
+		os.Exit(1)
+	}
+	logging.Info("画像の検証を初期化しました。形式: %v", imageValidator.AllowedTypes())
+
 	// サーバーの初期化
-	srv := server.NewServer(quizService)
+	serverOpts := []server.Option{server.WithVerifier(verifier), server.WithImageValidator(imageValidator)}
+	if uploadStore, ok := storageClient.(storage.UploadStore); ok {
+		serverOpts = append(serverOpts, server.WithUploadService(service.NewUploadService(uploadStore, server.MaxUploadSize)))
+		logging.Info("アップロードセッションを有効にしました。")
+	}
+	if banStore != nil {
+		serverOpts = append(serverOpts, server.WithModerationService(service.NewModerationService(storageClient, banStore)))
+		logging.Info("管理者による審査とクイズの報告を有効にしました。")
+	}
+	var jobRunner *service.JobRunner
+	if jobStore, ok := storageClient.(storage.JobStore); ok && cfg.JobWorkers > 0 {
+		jobRunner = service.NewJobRunner(quizService, jobStore, service.WithJobWorkers(cfg.JobWorkers))
+		if err := jobRunner.Start(ctx); err != nil {
+			logging.Error("クイズ作成ジョブの開始に失敗しました。")
+			dumpError(err)
+			os.Exit(1)
+		}
+		serverOpts = append(serverOpts, server.WithJobQueue(jobRunner))
+		logging.Info("クイズの非同期作成を有効にしました。ワーカー数: %d", cfg.JobWorkers)
+	}
+	srv := server.NewServer(quizService, serverOpts...)
 	logging.Info("HTTPサーバーを初期化しました。")
 
 	// HTTPサーバーの設定
@@ -144,6 +239,12 @@ func main() {
 		dumpError(err)
 	}
 
+	// 実行中のジョブは中断し、次の起動時に再開する
+	cancel()
+	if jobRunner != nil {
+		jobRunner.Wait()
+	}
+
 	logging.Info("サーバーを停止しました。")
 }
 
@@ -2,8 +2,12 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"unicode/utf8"
 
 	"cloud.google.com/go/vertexai/genai"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
@@ -17,19 +21,29 @@ type GenerativeModel interface {
 
 // AIClient はAIサービスとの通信を抽象化するインターフェース
 type AIClient interface {
-	GenerateInterpretation(ctx context.Context, imageData []byte, authorInterpretation string) (string, error)
+	// mimeType は画像の MIME タイプ（image/png など）です。空の場合は画像データから判定します
+	GenerateInterpretation(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona Persona) (string, error)
 }
 
+// DefaultVertexModel はモデル名が指定されていない場合に使用するVertex AIのモデルです
+const DefaultVertexModel = "gemini-pro-vision"
+
 // Client はVertex AIとの通信を担当します
 type Client struct {
 	projectID string
 	location  string
 	model     GenerativeModel
+	// newModel は生成温度を指定してモデルを作成します（未設定の場合は model を使用します）
+	newModel func(temperature float32) GenerativeModel
 }
 
 // NewClient は新しいAIクライアントを作成します
-func NewClient(projectID, location string) (*Client, error) {
-	logging.Info("AIクライアントの初期化を開始: projectID=%s, location=%s", projectID, location)
+// modelName が空の場合は DefaultVertexModel を使用します
+func NewClient(projectID, location, modelName string) (*Client, error) {
+	if modelName == "" {
+		modelName = DefaultVertexModel
+	}
+	logging.Info("AIクライアントの初期化を開始: projectID=%s, location=%s, model=%s", projectID, location, modelName)
 	ctx := context.Background()
 
 	var opts []option.ClientOption
@@ -49,18 +63,44 @@ func NewClient(projectID, location string) (*Client, error) {
 	}
 	logging.Info("Vertex AIクライアントの作成に成功")
 
-	model := client.GenerativeModel("gemini-pro-vision")
-	model.SetTemperature(0.7)
+	model := client.GenerativeModel(modelName)
+	model.SetTemperature(DefaultPersona.Temperature)
 
 	return &Client{
 		projectID: projectID,
 		location:  location,
 		model:     model,
+		newModel: func(temperature float32) GenerativeModel {
+			// GenerativeModel の設定は共有されるため、温度ごとに別のモデルを作成します
+			m := client.GenerativeModel(modelName)
+			m.SetTemperature(temperature)
+			return m
+		},
 	}, nil
 }
 
+// modelFor は指定された生成温度のモデルを返します
+func (c *Client) modelFor(temperature float32) GenerativeModel {
+	if c.newModel == nil {
+		return c.model
+	}
+	return c.newModel(temperature)
+}
+
+// imageMimeType は画像の MIME タイプを返します
+// 呼び出し側で判定済みの場合はそれを使い、未指定の場合は画像データの先頭から判定します
+func imageMimeType(imageData []byte, mimeType string) string {
+	if mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(imageData)
+}
+
 // generatePrompt はプロンプトを生成します
-func generatePrompt(authorInterpretation string) string {
+// 文字数はバイト数ではなく文字（rune）の数で伝えます（日本語の文字は UTF-8 で3バイトのため）
+func generatePrompt(authorInterpretation string, persona Persona) string {
+	length := utf8.RuneCountInString(strings.TrimSpace(authorInterpretation))
+	minLength, maxLength := lengthRange(length)
 	return fmt.Sprintf(`
 この画像に対して、投稿者は以下のような解釈をしています：
 %s
@@ -69,18 +109,19 @@ func generatePrompt(authorInterpretation string) string {
 以下の点に注意してください：
 1. 投稿者の解釈と同じような親しみやすい文体で書く
 2. 投稿者の解釈の0.8倍から1.2倍以内の文字数に収める（長すぎないように注意）
+3. %s
 4. 投稿者の解釈が自然な会話調なら、同じように自然な会話調で書く
 5. 投稿者の解釈がもっともらしいものなら、それを踏襲する
 6. 1つの段落にまとめる（改行を入れない）
 7. 重複する表現は避ける
 
-投稿者の解釈の文字数は%d文字です。これを参考に、簡潔な解釈を生成してください。
-`, authorInterpretation, len(authorInterpretation))
+投稿者の解釈の文字数は%d文字です。%d文字から%d文字の範囲で、簡潔な解釈を生成してください。
+`, authorInterpretation, persona.Instruction, length, minLength, maxLength)
 }
 
 // GenerateInterpretation は画像の解釈を生成します
-func (c *Client) GenerateInterpretation(ctx context.Context, imageData []byte, authorInterpretation string) (string, error) {
-	logging.Info("解釈生成を開始: 画像サイズ=%d bytes", len(imageData))
+func (c *Client) GenerateInterpretation(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona Persona) (string, error) {
+	logging.Info("解釈生成を開始: 画像サイズ=%d bytes, 形式=%s, ペルソナ=%s", len(imageData), mimeType, persona.Name)
 	if len(imageData) == 0 {
 		logging.Error("画像データが空です")
 		return "", fmt.Errorf("画像データが必要です")
@@ -90,30 +131,65 @@ func (c *Client) GenerateInterpretation(ctx context.Context, imageData []byte, a
 		return "", fmt.Errorf("投稿者の解釈が必要です")
 	}
 
-	prompt := generatePrompt(authorInterpretation)
-	logging.Debug("プロンプトを生成: 長さ=%d文字", len(prompt))
+	prompt := generatePrompt(authorInterpretation, persona)
+	logging.Debug("プロンプトを生成: 長さ=%d文字", utf8.RuneCountInString(prompt))
 
-	response, err := c.model.GenerateContent(ctx,
-		genai.ImageData("image/jpeg", imageData),
+	response, err := c.modelFor(persona.Temperature).GenerateContent(ctx,
+		genai.Blob{MIMEType: imageMimeType(imageData, mimeType), Data: imageData},
 		genai.Text(prompt),
 	)
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		logging.Warn("AIの応答が安全フィルターでブロックされました: %v", err)
+		return "", fmt.Errorf("%w: %v", ErrEmptyOutput, err)
+	}
 	if err != nil {
 		logging.Error("AIからの応答の取得に失敗: %v", err)
 		return "", fmt.Errorf("AIからの応答の取得に失敗: %w", err)
 	}
 
-	if len(response.Candidates) == 0 {
-		logging.Error("AIからの応答が空です")
-		return "", fmt.Errorf("AIからの応答が空です")
+	text, err := candidateText(response)
+	if err != nil {
+		logging.Warn("AIの応答から解釈を取り出せません: %v", err)
+		return "", err
 	}
 
-	text, ok := response.Candidates[0].Content.Parts[0].(genai.Text)
-	if !ok {
-		logging.Error("応答のテキスト変換に失敗")
-		return "", fmt.Errorf("テキスト応答の解析に失敗")
+	interpretation := text
+	logging.Info("解釈の生成に成功: 長さ=%d文字", utf8.RuneCountInString(interpretation))
+	return interpretation, nil
+}
+
+// candidateText は応答の最初の候補のテキストのパートをつなげて返します
+// 安全フィルターなどで生成が中断された候補は Content が nil か Parts が空になるため、パートを取り出す前に確かめます
+// 解釈を取り出せない場合は ErrEmptyOutput を返します
+func candidateText(response *genai.GenerateContentResponse) (string, error) {
+	if response == nil || len(response.Candidates) == 0 {
+		if response != nil && response.PromptFeedback != nil {
+			return "", fmt.Errorf("%w: プロンプトがブロックされました: reason=%s", ErrEmptyOutput, response.PromptFeedback.BlockReason)
+		}
+		return "", fmt.Errorf("%w: 候補がありません", ErrEmptyOutput)
+	}
+	candidate := response.Candidates[0]
+	if candidate == nil {
+		return "", fmt.Errorf("%w: 候補がありません", ErrEmptyOutput)
+	}
+	switch candidate.FinishReason {
+	case genai.FinishReasonUnspecified, genai.FinishReasonStop, genai.FinishReasonMaxTokens:
+	default:
+		return "", fmt.Errorf("%w: 生成が中断されました: finish_reason=%s", ErrEmptyOutput, candidate.FinishReason)
+	}
+	if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
+		return "", fmt.Errorf("%w: 候補に内容がありません: finish_reason=%s", ErrEmptyOutput, candidate.FinishReason)
 	}
 
-	interpretation := string(text)
-	logging.Info("解釈の生成に成功: 長さ=%d文字", len(interpretation))
-	return interpretation, nil
+	var b strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			b.WriteString(string(text))
+		}
+	}
+	if strings.TrimSpace(b.String()) == "" {
+		return "", fmt.Errorf("%w: テキストのパートがありません", ErrEmptyOutput)
+	}
+	return b.String(), nil
 }
@@ -2,6 +2,8 @@ package ai
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"cloud.google.com/go/vertexai/genai"
@@ -99,7 +101,7 @@ func TestGenerateInterpretation(t *testing.T) {
 			}
 
 			// テストの実行
-			got, err := client.GenerateInterpretation(context.Background(), tt.imageData, tt.authorInterpretation)
+			got, err := client.GenerateInterpretation(context.Background(), tt.imageData, "image/png", tt.authorInterpretation, DefaultPersona)
 
 			// エラーの検証
 			if tt.wantError {
@@ -120,3 +122,184 @@ func TestGenerateInterpretation(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateInterpretation_BlockedCandidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *genai.GenerateContentResponse
+		err      error
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "安全フィルターで内容のない候補",
+			response: &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonSafety}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "パートが空の候補",
+			response: &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{{Content: &genai.Content{}, FinishReason: genai.FinishReasonStop}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "途中まで生成して中断された候補",
+			response: &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{{
+					Content:      &genai.Content{Parts: []genai.Part{genai.Text("途中まで")}},
+					FinishReason: genai.FinishReasonRecitation,
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "プロンプトのブロック",
+			response: &genai.GenerateContentResponse{
+				PromptFeedback: &genai.PromptFeedback{BlockReason: genai.BlockedReasonSafety},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "SDK が返すブロックのエラー",
+			err:     &genai.BlockedError{Candidate: &genai.Candidate{FinishReason: genai.FinishReasonSafety}},
+			wantErr: true,
+		},
+		{
+			name: "複数のテキストのパートはつなげる",
+			response: &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{{
+					Content: &genai.Content{Parts: []genai.Part{
+						genai.Text("夕暮れの港に"),
+						genai.Blob{MIMEType: "image/png"},
+						genai.Text("溶ける橙色"),
+					}},
+					FinishReason: genai.FinishReasonStop,
+				}},
+			},
+			want: "夕暮れの港に溶ける橙色",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{model: &MockGenerativeModel{
+				generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+					return tt.response, tt.err
+				},
+			}}
+
+			got, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+			if tt.wantErr {
+				if !errors.Is(err, ErrEmptyOutput) {
+					t.Fatalf("expected ErrEmptyOutput, got %v", err)
+				}
+				// 通信の障害ではないため、ResilientClient ではやり直さない
+				if IsRetryable(err) {
+					t.Errorf("expected non-retryable error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGenerateInterpretation_UsesPersona(t *testing.T) {
+	var gotTemperature float32
+	var gotPrompt string
+	client := &Client{
+		newModel: func(temperature float32) GenerativeModel {
+			gotTemperature = temperature
+			return &MockGenerativeModel{
+				generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+					gotPrompt = string(parts[1].(genai.Text))
+					return &genai.GenerateContentResponse{
+						Candidates: []*genai.Candidate{
+							{Content: &genai.Content{Parts: []genai.Part{genai.Text("AIによる解釈")}}},
+						},
+					}, nil
+				},
+			}
+		},
+	}
+
+	persona := Personas[2]
+	if _, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", persona); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotTemperature != persona.Temperature {
+		t.Errorf("want temperature %v, got %v", persona.Temperature, gotTemperature)
+	}
+	if !strings.Contains(gotPrompt, persona.Instruction) {
+		t.Errorf("prompt does not contain persona instruction: %q", gotPrompt)
+	}
+}
+
+func TestGenerateInterpretation_ImageMimeType(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageData []byte
+		mimeType  string
+		want      string
+	}{
+		{name: "指定された形式", imageData: []byte("image"), mimeType: "image/png", want: "image/png"},
+		{name: "未指定の場合はPNGを判定", imageData: []byte("\x89PNG\r\n\x1a\n0000"), want: "image/png"},
+		{name: "未指定の場合はJPEGを判定", imageData: []byte("\xff\xd8\xff\xe0000"), want: "image/jpeg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			client := &Client{
+				model: &MockGenerativeModel{
+					generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+						got = parts[0].(genai.Blob).MIMEType
+						return &genai.GenerateContentResponse{
+							Candidates: []*genai.Candidate{
+								{Content: &genai.Content{Parts: []genai.Part{genai.Text("AIによる解釈")}}},
+							},
+						}, nil
+					},
+				},
+			}
+
+			if _, err := client.GenerateInterpretation(context.Background(), tt.imageData, tt.mimeType, "投稿者の解釈", DefaultPersona); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("want MIME type %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGeneratePrompt_CharacterCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		author string
+		want   string
+	}{
+		// 日本語は UTF-8 で1文字3バイトのため、バイト数で数えると約3倍の長さを伝えてしまう
+		{name: "日本語", author: "夕暮れの港で船を見送る人", want: "文字数は12文字です。9文字から15文字の範囲"},
+		{name: "日本語と英数字の混在", author: "東京タワーとTokyo Skytreeの夜景", want: "文字数は22文字です。17文字から27文字の範囲"},
+		{name: "英数字のみ", author: "A quiet harbor", want: "文字数は14文字です。11文字から17文字の範囲"},
+		{name: "前後の空白は数えない", author: "\n夕暮れの港\n", want: "文字数は5文字です。4文字から6文字の範囲"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := generatePrompt(tt.author, DefaultPersona)
+			if !strings.Contains(prompt, tt.want) {
+				t.Errorf("prompt does not contain %q: %q", tt.want, prompt)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+const (
+	// DefaultOpenAIEndpoint はエンドポイントが指定されていない場合に使用するURLです
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+	// DefaultOpenAIModel はモデル名が指定されていない場合に使用するモデルです
+	DefaultOpenAIModel = "gpt-4o-mini"
+)
+
+// OpenAIClient はOpenAI互換のChat Completions APIとの通信を担当します
+type OpenAIClient struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIClient は新しいOpenAI互換クライアントを作成します
+func NewOpenAIClient(endpoint, apiKey, model string) *OpenAIClient {
+	if endpoint == "" {
+		endpoint = DefaultOpenAIEndpoint
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return &OpenAIClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// newOpenAIProvider はOpenAI互換のクライアントを作成します
+func newOpenAIProvider(ctx context.Context, cfg ProviderConfig) (AIClient, error) {
+	logging.Info("OpenAI互換クライアントの初期化: endpoint=%s, model=%s", cfg.Endpoint, cfg.Model)
+	return NewOpenAIClient(cfg.Endpoint, cfg.APIKey, cfg.Model), nil
+}
+
+// chatCompletionRequest はChat Completions APIのリクエストです
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float32       `json:"temperature"`
+}
+
+type chatMessage struct {
+	Role    string        `json:"role"`
+	Content []chatContent `json:"content"`
+}
+
+type chatContent struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *chatImageURL `json:"image_url,omitempty"`
+}
+
+type chatImageURL struct {
+	URL string `json:"url"`
+}
+
+// StatusError はAIのエンドポイントが200以外のステータスコードを返したことを表します
+// ステータスコードから、呼び出しをやり直すかを判定できます
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("AIからエラー応答: status=%d, body=%s", e.StatusCode, e.Body)
+}
+
+// chatCompletionResponse はChat Completions APIのレスポンスです
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// GenerateInterpretation は画像の解釈を生成します
+func (c *OpenAIClient) GenerateInterpretation(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona Persona) (string, error) {
+	logging.Info("解釈生成を開始: 画像サイズ=%d bytes, 形式=%s, ペルソナ=%s", len(imageData), mimeType, persona.Name)
+	if len(imageData) == 0 {
+		return "", fmt.Errorf("画像データが必要です")
+	}
+	if authorInterpretation == "" {
+		return "", fmt.Errorf("投稿者の解釈が必要です")
+	}
+
+	body, err := json.Marshal(chatCompletionRequest{
+		Model: c.model,
+		Messages: []chatMessage{{
+			Role: "user",
+			Content: []chatContent{
+				{Type: "text", Text: generatePrompt(authorInterpretation, persona)},
+				{Type: "image_url", ImageURL: &chatImageURL{
+					URL: "data:" + imageMimeType(imageData, mimeType) + ";base64," + base64.StdEncoding.EncodeToString(imageData),
+				}},
+			},
+		}},
+		Temperature: persona.Temperature,
+	})
+	if err != nil {
+		return "", fmt.Errorf("リクエストの作成に失敗: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("リクエストの作成に失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		logging.Error("AIからの応答の取得に失敗: %v", err)
+		return "", fmt.Errorf("AIからの応答の取得に失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("AIからの応答の読み込みに失敗: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		logging.Error("AIからエラー応答: status=%d", resp.StatusCode)
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(respBody, &completion); err != nil {
+		return "", fmt.Errorf("AIからの応答の解析に失敗: %w", err)
+	}
+	if len(completion.Choices) == 0 || completion.Choices[0].Message.Content == "" {
+		logging.Warn("AIからの応答が空です")
+		return "", fmt.Errorf("%w: 応答が空です", ErrEmptyOutput)
+	}
+
+	interpretation := strings.TrimSpace(completion.Choices[0].Message.Content)
+	logging.Info("解釈の生成に成功: 長さ=%d文字", utf8.RuneCountInString(interpretation))
+	return interpretation, nil
+}
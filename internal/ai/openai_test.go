@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIClient_GenerateInterpretation(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		body               string
+		wantError          bool
+		wantInterpretation string
+	}{
+		{
+			name:               "正常系：有効な応答",
+			status:             http.StatusOK,
+			body:               `{"choices":[{"message":{"content":" AIによる解釈 "}}]}`,
+			wantInterpretation: "AIによる解釈",
+		},
+		{
+			name:      "異常系：空の応答",
+			status:    http.StatusOK,
+			body:      `{"choices":[]}`,
+			wantError: true,
+		},
+		{
+			name:      "異常系：エラーステータス",
+			status:    http.StatusTooManyRequests,
+			body:      `{"error":{"message":"rate limited"}}`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRequest chatCompletionRequest
+			var gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/chat/completions" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				gotAuth = r.Header.Get("Authorization")
+				if err := json.NewDecoder(r.Body).Decode(&gotRequest); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewOpenAIClient(server.URL+"/v1/", "test-key", "test-model")
+			got, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+
+			if gotAuth != "Bearer test-key" {
+				t.Errorf("unexpected Authorization header: %q", gotAuth)
+			}
+			if gotRequest.Model != "test-model" {
+				t.Errorf("unexpected model: %q", gotRequest.Model)
+			}
+			if len(gotRequest.Messages) != 1 || len(gotRequest.Messages[0].Content) != 2 ||
+				!strings.HasPrefix(gotRequest.Messages[0].Content[1].ImageURL.URL, "data:image/png;base64,") {
+				t.Errorf("unexpected messages: %+v", gotRequest.Messages)
+			}
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantInterpretation {
+				t.Errorf("want %q, got %q", tt.wantInterpretation, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package ai
+
+// Persona はAIが解釈を生成する際の視点と生成温度を表します
+// 同じクイズに複数の解釈を生成するとき、ペルソナごとに異なる解釈になるようにします
+type Persona struct {
+	Name        string
+	Instruction string
+	Temperature float32
+}
+
+// Personas は解釈の生成に使用するペルソナの一覧です
+var Personas = []Persona{
+	{
+		Name:        "default",
+		Instruction: "作品全体から受ける印象を素直に言葉にする",
+		Temperature: 0.7,
+	},
+	{
+		Name:        "colorist",
+		Instruction: "色彩や光の使い方に注目した視点で解釈する",
+		Temperature: 0.8,
+	},
+	{
+		Name:        "storyteller",
+		Instruction: "作品の中に流れる物語や時間に注目した視点で解釈する",
+		Temperature: 0.9,
+	},
+	{
+		Name:        "composer",
+		Instruction: "構図や形、余白の取り方に注目した視点で解釈する",
+		Temperature: 0.75,
+	},
+	{
+		Name:        "memoirist",
+		Instruction: "作者自身の思い出や体験が込められているという視点で解釈する",
+		Temperature: 0.85,
+	},
+}
+
+// DefaultPersona は既定のペルソナです
+var DefaultPersona = Personas[0]
+
+// PersonaAt は i 番目のおとり解釈に使用するペルソナを返します
+func PersonaAt(i int) Persona {
+	return Personas[i%len(Personas)]
+}
@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+const (
+	// ProviderVertex はVertex AI (Gemini) を使用するプロバイダー名です
+	ProviderVertex = "vertex"
+	// ProviderOpenAI はOpenAI互換のHTTPエンドポイントを使用するプロバイダー名です
+	ProviderOpenAI = "openai"
+	// ProviderStub はネットワークを使用しない決定的なスタブのプロバイダー名です
+	ProviderStub = "stub"
+)
+
+// ProviderConfig はAIプロバイダーの初期化に必要な設定を保持します
+// 各プロバイダーは必要な項目のみを参照します
+type ProviderConfig struct {
+	ProjectID string
+	Location  string
+	Model     string
+	Endpoint  string
+	APIKey    string
+}
+
+// ProviderFactory は設定からAIクライアントを生成する関数です
+type ProviderFactory func(ctx context.Context, cfg ProviderConfig) (AIClient, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]ProviderFactory)
+)
+
+func init() {
+	Register(ProviderVertex, newVertexProvider)
+	Register(ProviderOpenAI, newOpenAIProvider)
+	Register(ProviderStub, newStubProvider)
+}
+
+// Register はプロバイダーを登録します
+// 同じ名前で二重に登録した場合はpanicします
+func Register(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if factory == nil {
+		panic("ai: Register factory is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("ai: Register called twice for provider " + name)
+	}
+	providers[name] = factory
+}
+
+// Providers は登録済みのプロバイダー名を返します
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider は指定されたプロバイダーのAIクライアントを作成します
+func NewProvider(ctx context.Context, name string, cfg ProviderConfig) (AIClient, error) {
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未対応のAIプロバイダーです: %q (対応: %v)", name, Providers())
+	}
+
+	logging.Info("AIプロバイダーを初期化します: provider=%s", name)
+	client, err := factory(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("AIプロバイダー %s の初期化に失敗: %w", name, err)
+	}
+	return client, nil
+}
+
+// newVertexProvider はVertex AIのクライアントを作成します
+func newVertexProvider(ctx context.Context, cfg ProviderConfig) (AIClient, error) {
+	return NewClient(cfg.ProjectID, cfg.Location, cfg.Model)
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestProviders(t *testing.T) {
+	want := []string{ProviderOpenAI, ProviderStub, ProviderVertex}
+	got := Providers()
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		wantError bool
+	}{
+		{name: "正常系：スタブ", provider: ProviderStub},
+		{name: "正常系：OpenAI互換", provider: ProviderOpenAI},
+		{name: "異常系：未登録のプロバイダー", provider: "unknown", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewProvider(context.Background(), tt.provider, ProviderConfig{})
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if client == nil {
+				t.Error("expected client, got nil")
+			}
+		})
+	}
+}
+
+func TestRegister_PanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	Register(ProviderStub, newStubProvider)
+}
+
+func TestStubClient_GenerateInterpretation(t *testing.T) {
+	client := NewStubClient()
+	ctx := context.Background()
+	author := "夕暮れの街に灯る明かりは、帰る場所がある安心感を表しています。"
+
+	first, err := client.GenerateInterpretation(ctx, []byte("image"), "image/png", author, DefaultPersona)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := client.GenerateInterpretation(ctx, []byte("image"), "image/png", author, DefaultPersona)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("expected deterministic output, got %q and %q", first, second)
+	}
+	if first == author {
+		t.Error("expected interpretation different from author's")
+	}
+	if got, want := utf8.RuneCountInString(first), utf8.RuneCountInString(author); got != want {
+		t.Errorf("expected %d characters, got %d", want, got)
+	}
+
+	if _, err := client.GenerateInterpretation(ctx, nil, "image/png", author, DefaultPersona); err == nil {
+		t.Error("expected error for empty image, got nil")
+	}
+	if _, err := client.GenerateInterpretation(ctx, []byte("image"), "image/png", "", DefaultPersona); err == nil {
+		t.Error("expected error for empty interpretation, got nil")
+	}
+}
+
+func TestStubClient_DiffersByPersona(t *testing.T) {
+	client := NewStubClient()
+	author := "夕暮れの街に灯る明かりは、帰る場所がある安心感を表しています。"
+
+	seen := make(map[string]bool)
+	for i := range Personas {
+		got, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, PersonaAt(i))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected different interpretations for different personas")
+	}
+}
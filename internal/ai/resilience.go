@@ -0,0 +1,351 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultMaxRetries は一時的なエラーで呼び出しをやり直す既定の回数です（最初の呼び出しを含みません）
+	DefaultMaxRetries = 3
+	// DefaultCallTimeout は1回の呼び出しの既定のタイムアウトです
+	DefaultCallTimeout = 30 * time.Second
+	// DefaultBaseBackoff は最初にやり直すまでの既定の待ち時間です（やり直すたびに2倍にします）
+	DefaultBaseBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff はやり直すまでの待ち時間の既定の上限です
+	DefaultMaxBackoff = 8 * time.Second
+	// DefaultFailureThreshold は呼び出しを遮断するまでに許す、連続した一時的なエラーの既定の回数です
+	DefaultFailureThreshold = 5
+	// DefaultOpenDuration は遮断してから試しに呼び出しを再開するまでの既定の時間です
+	DefaultOpenDuration = 30 * time.Second
+)
+
+var (
+	// ErrUnavailable はAIサービスが一時的に利用できないことを表します
+	// やり直しても一時的なエラーが続いた場合と、呼び出しを遮断している場合に返します
+	ErrUnavailable = errors.New("AIサービスが一時的に利用できません")
+	// ErrCircuitOpen はAIサービスの障害が続いているため、呼び出しを遮断していることを表します
+	ErrCircuitOpen = errors.New("AIサービスへの呼び出しを遮断しています")
+)
+
+// IsRetryable はAIサービスの呼び出しのエラーが、やり直せば成功する可能性のある一時的なものかを判定します
+// レート制限・サーバーエラー・タイムアウト・接続エラーは一時的とみなし、入力の誤りや認証エラーはやり直しません
+func IsRetryable(err error) bool {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return retryableHTTPStatus(statusErr.StatusCode)
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return retryableHTTPStatus(apiErr.Code)
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.ResourceExhausted, codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Internal:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// retryableHTTPStatus はやり直す対象のHTTPステータスコードかを判定します
+func retryableHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// ResilienceOption は ResilientClient の設定を変更します
+type ResilienceOption func(*ResilientClient)
+
+// WithMaxRetries は一時的なエラーで呼び出しをやり直す回数を設定します（0 の場合はやり直しません）
+func WithMaxRetries(n int) ResilienceOption {
+	return func(c *ResilientClient) {
+		if n >= 0 {
+			c.maxRetries = n
+		}
+	}
+}
+
+// WithCallTimeout は1回の呼び出しのタイムアウトを設定します（0 以下の場合はタイムアウトしません）
+func WithCallTimeout(d time.Duration) ResilienceOption {
+	return func(c *ResilientClient) {
+		c.callTimeout = d
+	}
+}
+
+// WithBackoff はやり直すまでの待ち時間を設定します
+// 待ち時間は base から始めてやり直すたびに2倍にし、maxDelay を上限とします（実際の待ち時間はその半分から全体の間でランダムに決めます）
+func WithBackoff(base, maxDelay time.Duration) ResilienceOption {
+	return func(c *ResilientClient) {
+		if base > 0 {
+			c.baseBackoff = base
+		}
+		if maxDelay >= c.baseBackoff {
+			c.maxBackoff = maxDelay
+		}
+	}
+}
+
+// WithCircuitBreaker は一時的なエラーが threshold 回続いた場合に、openDuration の間は呼び出しを遮断するよう設定します
+// threshold が 0 以下の場合は遮断しません
+func WithCircuitBreaker(threshold int, openDuration time.Duration) ResilienceOption {
+	return func(c *ResilientClient) {
+		if threshold <= 0 {
+			c.breaker = nil
+			return
+		}
+		c.breaker = newCircuitBreaker(threshold, openDuration)
+	}
+}
+
+// ResilientClient はAIサービスの一時的な障害に備えて、呼び出しのやり直しと遮断を行うAIクライアントです
+// どのプロバイダーのクライアントでも包めます
+type ResilientClient struct {
+	client      AIClient
+	maxRetries  int
+	callTimeout time.Duration
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	breaker     *circuitBreaker
+
+	// sleep と jitter はテストで差し替えます
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func(d time.Duration) time.Duration
+}
+
+// NewResilientClient は client の呼び出しをやり直しと遮断で保護するクライアントを作成します
+func NewResilientClient(client AIClient, opts ...ResilienceOption) *ResilientClient {
+	c := &ResilientClient{
+		client:      client,
+		maxRetries:  DefaultMaxRetries,
+		callTimeout: DefaultCallTimeout,
+		baseBackoff: DefaultBaseBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		breaker:     newCircuitBreaker(DefaultFailureThreshold, DefaultOpenDuration),
+		sleep:       sleepContext,
+		jitter:      equalJitter,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// GenerateInterpretation は画像の解釈を生成します
+// 一時的なエラーの場合は待ち時間を空けてやり直し、それでも失敗した場合や遮断中の場合は ErrUnavailable を返します
+func (c *ResilientClient) GenerateInterpretation(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona Persona) (string, error) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			if lastErr != nil {
+				return "", fmt.Errorf("%w: %w (直前のエラー: %v)", ErrUnavailable, err, lastErr)
+			}
+			return "", fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+
+		interpretation, err := c.call(ctx, imageData, mimeType, authorInterpretation, persona)
+		if err == nil {
+			c.breaker.success()
+			return interpretation, nil
+		}
+		// 呼び出し側のキャンセルや入力の誤りはAIサービスの障害ではないため、遮断の判定に数えない
+		if ctx.Err() != nil || !IsRetryable(err) {
+			c.breaker.release()
+			return "", err
+		}
+		c.breaker.failure()
+		lastErr = err
+
+		if attempt >= c.maxRetries {
+			logging.Error("AIサービスの呼び出しをやり直しても失敗しました: ペルソナ=%s, 試行=%d, err=%v", persona.Name, attempt+1, err)
+			return "", fmt.Errorf("%w: %d回試行しました: %w", ErrUnavailable, attempt+1, err)
+		}
+		delay := c.backoff(attempt)
+		// 呼び出し側の期限までに次の呼び出しを始められない場合は、待たずに諦める
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			logging.Error("AIサービスの呼び出しをやり直す前に期限に達します: ペルソナ=%s, 試行=%d, err=%v", persona.Name, attempt+1, err)
+			return "", fmt.Errorf("%w: 期限までにやり直せません: %w", ErrUnavailable, err)
+		}
+		logging.Warn("AIサービスの一時的なエラーのため呼び出しをやり直します: ペルソナ=%s, 試行=%d/%d, 待ち時間=%s, err=%v", persona.Name, attempt+1, c.maxRetries+1, delay, err)
+		if err := c.sleep(ctx, delay); err != nil {
+			return "", err
+		}
+	}
+}
+
+// call はタイムアウトを設定して1回だけ呼び出します
+func (c *ResilientClient) call(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona Persona) (string, error) {
+	if c.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.callTimeout)
+		defer cancel()
+	}
+	return c.client.GenerateInterpretation(ctx, imageData, mimeType, authorInterpretation, persona)
+}
+
+// backoff は attempt 回目（0 始まり）の失敗の後にやり直すまでの待ち時間を返します
+func (c *ResilientClient) backoff(attempt int) time.Duration {
+	d := c.baseBackoff
+	for i := 0; i < attempt && d < c.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	return c.jitter(d)
+}
+
+// equalJitter は複数の呼び出しが同時にやり直さないよう、待ち時間を d/2 から d の間でランダムに決めます
+func equalJitter(d time.Duration) time.Duration {
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// sleepContext は d だけ待ちます。その間に ctx が終了した場合はそのエラーを返します
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// breakerState は遮断の状態です
+type breakerState int
+
+const (
+	// breakerClosed は通常どおり呼び出す状態です
+	breakerClosed breakerState = iota
+	// breakerOpen は呼び出しを遮断している状態です
+	breakerOpen
+	// breakerHalfOpen は遮断の時間が過ぎ、1回だけ試しに呼び出している状態です
+	breakerHalfOpen
+)
+
+// circuitBreaker は一時的なエラーが続いた場合にAIサービスの呼び出しを遮断します
+// nil の場合は遮断しません
+type circuitBreaker struct {
+	threshold    int
+	openDuration time.Duration
+	now          func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// newCircuitBreaker は新しい circuitBreaker を作成します
+func newCircuitBreaker(threshold int, openDuration time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, openDuration: openDuration, now: time.Now}
+}
+
+// allow は呼び出してよいかを判定し、遮断中の場合は ErrCircuitOpen を返します
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.openDuration {
+			return ErrCircuitOpen
+		}
+		logging.Info("AIサービスの呼び出しを試しに再開します")
+		b.state = breakerHalfOpen
+		b.probing = true
+	case breakerHalfOpen:
+		// 試しの呼び出しの結果が出るまでは、他の呼び出しを遮断する
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// success は呼び出しが成功したことを記録し、遮断を解除します
+func (b *circuitBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != breakerClosed {
+		logging.Info("AIサービスの呼び出しの遮断を解除しました")
+	}
+	b.state = breakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// failure は一時的なエラーを記録し、続いた回数が閾値に達した場合は遮断します
+func (b *circuitBreaker) failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		if b.state != breakerOpen {
+			logging.Warn("AIサービスのエラーが続いているため呼び出しを遮断します: 連続したエラー=%d, 遮断時間=%s", b.failures, b.openDuration)
+		}
+		b.state = breakerOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// release は遮断の判定に数えない結果で呼び出しが終わったことを記録します
+func (b *circuitBreaker) release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
@@ -0,0 +1,268 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/vertexai/genai"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Vertex AIのレート制限", err: status.Error(codes.ResourceExhausted, "quota exceeded"), want: true},
+		{name: "Vertex AIの一時的な障害", err: status.Error(codes.Unavailable, "unavailable"), want: true},
+		{name: "Vertex AIの入力エラー", err: status.Error(codes.InvalidArgument, "invalid"), want: false},
+		{name: "Vertex AIの権限エラー", err: status.Error(codes.PermissionDenied, "denied"), want: false},
+		{name: "ラップされたエラー", err: fmt.Errorf("AIからの応答の取得に失敗: %w", status.Error(codes.Unavailable, "unavailable")), want: true},
+		{name: "HTTP 429", err: &StatusError{StatusCode: 429}, want: true},
+		{name: "HTTP 503", err: &StatusError{StatusCode: 503}, want: true},
+		{name: "HTTP 400", err: &StatusError{StatusCode: 400}, want: false},
+		{name: "REST API の 503", err: &googleapi.Error{Code: 503}, want: true},
+		{name: "REST API の 403", err: &googleapi.Error{Code: 403}, want: false},
+		{name: "タイムアウト", err: fmt.Errorf("AIからの応答の取得に失敗: %w", context.DeadlineExceeded), want: true},
+		{name: "キャンセル", err: context.Canceled, want: false},
+		{name: "接続エラー", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, want: true},
+		{name: "その他のエラー", err: errors.New("画像データが必要です"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// failingModel は最初の failures 回は err を返し、その後は解釈を返すモデルを作成します
+func failingModel(calls *int, failures int, err error) *MockGenerativeModel {
+	return &MockGenerativeModel{
+		generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+			*calls++
+			if *calls <= failures {
+				return nil, err
+			}
+			return &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{
+					{Content: &genai.Content{Parts: []genai.Part{genai.Text("AIによる解釈")}}},
+				},
+			}, nil
+		},
+	}
+}
+
+// newTestResilientClient は待ち時間を記録するだけで実際には待たないクライアントを作成します
+func newTestResilientClient(model GenerativeModel, delays *[]time.Duration, opts ...ResilienceOption) *ResilientClient {
+	c := NewResilientClient(&Client{model: model}, opts...)
+	c.jitter = func(d time.Duration) time.Duration { return d }
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return ctx.Err()
+	}
+	return c
+}
+
+func TestResilientClient_Retry(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	t.Run("一時的なエラーは待ち時間を増やしながらやり直す", func(t *testing.T) {
+		calls := 0
+		var delays []time.Duration
+		client := newTestResilientClient(failingModel(&calls, 3, unavailable), &delays,
+			WithMaxRetries(3), WithBackoff(10*time.Millisecond, 25*time.Millisecond))
+
+		got, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "AIによる解釈" {
+			t.Errorf("unexpected interpretation: %q", got)
+		}
+		if calls != 4 {
+			t.Errorf("want 4 calls, got %d", calls)
+		}
+		want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond}
+		if fmt.Sprint(delays) != fmt.Sprint(want) {
+			t.Errorf("want delays %v, got %v", want, delays)
+		}
+	})
+
+	t.Run("やり直しても失敗した場合は ErrUnavailable", func(t *testing.T) {
+		calls := 0
+		var delays []time.Duration
+		client := newTestResilientClient(failingModel(&calls, 10, unavailable), &delays, WithMaxRetries(2))
+
+		_, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+		if !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("expected ErrUnavailable, got %v", err)
+		}
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("expected the last error to be wrapped, got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("want 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("一時的でないエラーはやり直さない", func(t *testing.T) {
+		calls := 0
+		var delays []time.Duration
+		client := newTestResilientClient(failingModel(&calls, 10, status.Error(codes.InvalidArgument, "invalid")), &delays)
+
+		_, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+		if err == nil || errors.Is(err, ErrUnavailable) {
+			t.Fatalf("expected the original error, got %v", err)
+		}
+		if calls != 1 || len(delays) != 0 {
+			t.Errorf("want 1 call without delay, got %d calls, delays %v", calls, delays)
+		}
+	})
+
+	t.Run("呼び出しごとのタイムアウト", func(t *testing.T) {
+		calls := 0
+		var delays []time.Duration
+		model := &MockGenerativeModel{
+			generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+				calls++
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		}
+		client := newTestResilientClient(model, &delays, WithMaxRetries(1), WithCallTimeout(10*time.Millisecond))
+
+		_, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+		if !errors.Is(err, ErrUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected ErrUnavailable with DeadlineExceeded, got %v", err)
+		}
+		if calls != 2 {
+			t.Errorf("want 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("期限までにやり直せない場合は待たずに諦める", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		calls := 0
+		var delays []time.Duration
+		client := newTestResilientClient(failingModel(&calls, 10, unavailable), &delays,
+			WithMaxRetries(3), WithBackoff(2*time.Minute, 2*time.Minute))
+
+		_, err := client.GenerateInterpretation(ctx, []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+		if !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("expected ErrUnavailable, got %v", err)
+		}
+		if calls != 1 || len(delays) != 0 {
+			t.Errorf("want 1 call without delay, got %d calls, delays %v", calls, delays)
+		}
+	})
+
+	t.Run("呼び出し側のキャンセルはやり直さない", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		var delays []time.Duration
+		model := &MockGenerativeModel{
+			generateContentFunc: func(context.Context, ...genai.Part) (*genai.GenerateContentResponse, error) {
+				calls++
+				cancel()
+				return nil, unavailable
+			},
+		}
+		client := newTestResilientClient(model, &delays)
+
+		if _, err := client.GenerateInterpretation(ctx, []byte("image"), "image/png", "投稿者の解釈", DefaultPersona); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if calls != 1 {
+			t.Errorf("want 1 call, got %d", calls)
+		}
+	})
+}
+
+func TestResilientClient_CircuitBreaker(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	calls := 0
+	failures := 2
+	var delays []time.Duration
+	client := newTestResilientClient(failingModel(&calls, failures, unavailable), &delays,
+		WithMaxRetries(0), WithCircuitBreaker(2, time.Minute))
+	client.breaker.now = func() time.Time { return now }
+	generate := func() error {
+		_, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", "投稿者の解釈", DefaultPersona)
+		return err
+	}
+
+	// 一時的なエラーが閾値まで続くと遮断する
+	for i := 0; i < failures; i++ {
+		if err := generate(); !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: expected ErrUnavailable, got %v", i+1, err)
+		}
+	}
+	// 遮断中はモデルを呼ばずにすぐ失敗する
+	if err := generate(); !errors.Is(err, ErrUnavailable) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != failures {
+		t.Errorf("want %d calls while open, got %d", failures, calls)
+	}
+
+	// 遮断の時間が過ぎると試しに呼び出し、成功すれば遮断を解除する
+	now = now.Add(time.Minute)
+	if err := generate(); err != nil {
+		t.Fatalf("unexpected error after cooldown: %v", err)
+	}
+	if err := generate(); err != nil {
+		t.Fatalf("unexpected error after recovery: %v", err)
+	}
+	if calls != failures+2 {
+		t.Errorf("want %d calls, got %d", failures+2, calls)
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	// 試しの呼び出しの結果が出るまでは、他の呼び出しを遮断する
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen during probe, got %v", err)
+	}
+	// 試しの呼び出しが失敗すると、再び遮断する
+	b.failure()
+	now = now.Add(30 * time.Second)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after failed probe, got %v", err)
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	d := 100 * time.Millisecond
+	for i := 0; i < 100; i++ {
+		if got := equalJitter(d); got < d/2 || got > d {
+			t.Fatalf("equalJitter(%v) = %v, want between %v and %v", d, got, d/2, d)
+		}
+	}
+}
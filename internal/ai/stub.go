@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"unicode/utf8"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+// stubPhrases はスタブが解釈を組み立てるための定型文です
+var stubPhrases = []string{
+	"画面の奥へと続く光の筋が、忘れかけていた記憶の入口を示しているように感じます。",
+	"色の重なりには、言葉にできない感情を少しずつほどいていく時間が表れています。",
+	"静かな構図の中に、日常のささやかな変化を見逃さないまなざしが込められています。",
+	"余白の広さが、見る人それぞれの物語を受け止めるための場所になっています。",
+	"柔らかな輪郭は、境界を曖昧にすることで人と風景のつながりを描いているようです。",
+}
+
+// StubClient はネットワークを使用せず、入力から決定的に解釈を生成するクライアントです
+// CIやオフライン環境でアップロードの流れ全体を動かすために使用します
+type StubClient struct{}
+
+// NewStubClient は新しいスタブクライアントを作成します
+func NewStubClient() *StubClient {
+	return &StubClient{}
+}
+
+// newStubProvider はスタブクライアントを作成します
+func newStubProvider(ctx context.Context, cfg ProviderConfig) (AIClient, error) {
+	logging.Warn("スタブのAIプロバイダーを使用します。本番環境では使用しないでください")
+	return NewStubClient(), nil
+}
+
+// GenerateInterpretation は投稿者の解釈と同じ文字数の解釈を決定的に生成します
+// ペルソナが異なれば、異なる解釈になります
+func (c *StubClient) GenerateInterpretation(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona Persona) (string, error) {
+	if len(imageData) == 0 {
+		return "", fmt.Errorf("画像データが必要です")
+	}
+	if authorInterpretation == "" {
+		return "", fmt.Errorf("投稿者の解釈が必要です")
+	}
+
+	h := fnv.New32a()
+	h.Write(imageData)
+	h.Write([]byte(authorInterpretation))
+	h.Write([]byte(persona.Name))
+	start := int(h.Sum32() % uint32(len(stubPhrases)))
+
+	target := utf8.RuneCountInString(authorInterpretation)
+	var runes []rune
+	for i := 0; len(runes) < target; i++ {
+		runes = append(runes, []rune(stubPhrases[(start+i)%len(stubPhrases)])...)
+	}
+	runes = runes[:target]
+	if target > 1 {
+		runes[target-1] = '。'
+	}
+	return string(runes), nil
+}
@@ -0,0 +1,203 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+// DefaultMaxAttempts は検証に通らなかった解釈を生成し直す場合の既定の試行回数です
+const DefaultMaxAttempts = 3
+
+const (
+	// minLengthPercent と maxLengthPercent はプロンプトで指示している文字数の範囲（投稿者の解釈に対する百分率）です
+	minLengthPercent = 80
+	maxLengthPercent = 120
+	// duplicateThreshold はこの値以上の類似度の解釈を投稿者の解釈の焼き直しとみなします
+	duplicateThreshold = 0.8
+)
+
+var (
+	// ErrInvalidOutput はAIの出力がおとりの解釈として使えないことを表します
+	ErrInvalidOutput = errors.New("AIの出力が検証を通過しませんでした")
+	// ErrEmptyOutput はAIの応答に解釈が含まれていないことを表します（安全フィルターによるブロックなど）
+	// 通信の障害ではないため ResilientClient はやり直さず、ValidatingClient が生成し直します
+	ErrEmptyOutput = errors.New("AIの応答に解釈が含まれていません")
+)
+
+// Violation はAIの出力が満たしていないルールです
+type Violation string
+
+const (
+	// ViolationParagraph は複数の段落に分かれていることを表します
+	ViolationParagraph Violation = "multiple_paragraphs"
+	// ViolationLength は文字数が投稿者の解釈のおよそ0.8倍から1.2倍の範囲（lengthRange）の外であることを表します
+	ViolationLength Violation = "length"
+	// ViolationDuplicate は投稿者の解釈とほとんど同じであることを表します
+	ViolationDuplicate Violation = "near_duplicate"
+	// ViolationRefusal は生成を断る文言が含まれていることを表します
+	ViolationRefusal Violation = "refusal"
+	// ViolationEmpty は応答に解釈が含まれていないことを表します（ErrEmptyOutput）
+	ViolationEmpty Violation = "empty"
+)
+
+// refusalPhrases はモデルが生成を断るときの定型的な言い回しです（小文字で比較します）
+var refusalPhrases = []string{
+	"申し訳ありません",
+	"申し訳ございません",
+	"お答えできません",
+	"お手伝いできません",
+	"生成できません",
+	"生成することはできません",
+	"解釈することはできません",
+	"aiとして",
+	"言語モデルとして",
+	"i'm sorry",
+	"i am sorry",
+	"i cannot",
+	"i can't",
+	"as an ai",
+}
+
+// ValidationError は試行回数の上限まで生成し直しても検証に通らなかったことを表します
+// errors.Is(err, ErrInvalidOutput) で判定できます
+type ValidationError struct {
+	// Attempts は生成を試みた回数です
+	Attempts int
+	// Violations は最後に生成した解釈が満たしていなかったルールです
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: 試行回数=%d, 違反=%v", ErrInvalidOutput, e.Attempts, e.Violations)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidOutput
+}
+
+// ValidateInterpretation はAIが生成した解釈がプロンプトのルールを満たしているかを検証し、満たしていないルールを返します
+// 文字数は前後の空白を除いた文字（rune）の数で比較します
+func ValidateInterpretation(authorInterpretation, interpretation string) []Violation {
+	author := strings.TrimSpace(authorInterpretation)
+	text := strings.TrimSpace(interpretation)
+
+	var violations []Violation
+	if strings.ContainsAny(text, "\r\n\u2028\u2029") {
+		violations = append(violations, ViolationParagraph)
+	}
+
+	minLength, maxLength := lengthRange(utf8.RuneCountInString(author))
+	if n := utf8.RuneCountInString(text); n < minLength || n > maxLength {
+		violations = append(violations, ViolationLength)
+	}
+
+	if similarity(author, text) >= duplicateThreshold {
+		violations = append(violations, ViolationDuplicate)
+	}
+
+	lower := strings.ToLower(text)
+	for _, phrase := range refusalPhrases {
+		if strings.Contains(lower, phrase) {
+			violations = append(violations, ViolationRefusal)
+			break
+		}
+	}
+	return violations
+}
+
+// lengthRange は投稿者の解釈の文字数から、生成する解釈に許す文字数の範囲を返します
+// 短い解釈でも範囲が投稿者の文字数ちょうどにならないよう、下限は切り捨て、上限は切り上げ、少なくとも前後1文字の幅を持たせます
+func lengthRange(length int) (minLength, maxLength int) {
+	// 浮動小数点の誤差で境界がずれないよう、整数で計算します
+	minLength = min(length*minLengthPercent/100, length-1)
+	maxLength = max((length*maxLengthPercent+99)/100, length+1)
+	return max(minLength, 1), maxLength
+}
+
+// similarity は2つの文章の類似度を、空白と句読点を除いた文字の2-gramのダイス係数（0〜1）で返します
+func similarity(a, b string) float64 {
+	ga, gb := bigrams(a), bigrams(b)
+	total := 0
+	for _, n := range ga {
+		total += n
+	}
+	for _, n := range gb {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	common := 0
+	for gram, n := range ga {
+		common += min(n, gb[gram])
+	}
+	return float64(2*common) / float64(total)
+}
+
+// bigrams は空白と句読点を除いた文字の2-gramの出現回数を返します
+func bigrams(s string) map[string]int {
+	var runes []rune
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		runes = append(runes, r)
+	}
+
+	grams := make(map[string]int, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])]++
+	}
+	return grams
+}
+
+// ValidatingClient は生成された解釈を検証し、ルールを満たさない場合は生成し直すAIクライアントです
+// どのプロバイダーのクライアントでも包めます
+type ValidatingClient struct {
+	client      AIClient
+	maxAttempts int
+}
+
+// NewValidatingClient は client の出力を検証するクライアントを作成します
+// maxAttempts は生成を試みる回数の上限です（1 未満の場合は DefaultMaxAttempts）
+func NewValidatingClient(client AIClient, maxAttempts int) *ValidatingClient {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	return &ValidatingClient{client: client, maxAttempts: maxAttempts}
+}
+
+// GenerateInterpretation は検証に通る解釈を生成します
+// 試行回数の上限まで生成し直しても検証に通らない場合は *ValidationError を返します
+// 応答に解釈が含まれていない場合（ErrEmptyOutput）は生成し直し、AIとの通信に失敗した場合は生成し直さずにそのエラーを返します
+func (c *ValidatingClient) GenerateInterpretation(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona Persona) (string, error) {
+	var violations []Violation
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		interpretation, err := c.client.GenerateInterpretation(ctx, imageData, mimeType, authorInterpretation, persona)
+		switch {
+		case errors.Is(err, ErrEmptyOutput):
+			violations = []Violation{ViolationEmpty}
+		case err != nil:
+			// AIサービスの障害（呼び出しの遮断を含む）は生成し直しても解消しないため、すぐに返す
+			return "", err
+		default:
+			violations = ValidateInterpretation(authorInterpretation, interpretation)
+		}
+		if len(violations) == 0 {
+			return strings.TrimSpace(interpretation), nil
+		}
+		logging.Warn("生成された解釈が検証を通過しませんでした: ペルソナ=%s, 試行=%d/%d, 違反=%v", persona.Name, attempt, c.maxAttempts, violations)
+
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+	}
+	return "", &ValidationError{Attempts: c.maxAttempts, Violations: violations}
+}
@@ -0,0 +1,304 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"cloud.google.com/go/vertexai/genai"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidateInterpretation(t *testing.T) {
+	author := "夕暮れの港で、遠ざかる船を見送る人の背中に、言葉にできない寂しさを込めました。"
+
+	tests := []struct {
+		name           string
+		interpretation string
+		want           []Violation
+	}{
+		{
+			name:           "正常系：ルールを満たす解釈",
+			interpretation: "沈みかけた太陽の橙色が水面に溶けて、一日の終わりの穏やかな安らぎを描いています。",
+		},
+		{
+			name:           "正常系：前後の改行は無視する",
+			interpretation: "\n沈みかけた太陽の橙色が水面に溶けて、一日の終わりの穏やかな安らぎを描いています。\n",
+		},
+		{
+			name:           "異常系：複数の段落",
+			interpretation: "沈みかけた太陽の橙色が水面に溶けて、\n\n一日の終わりの穏やかな安らぎを描いています。",
+			want:           []Violation{ViolationParagraph},
+		},
+		{
+			name:           "異常系：短すぎる",
+			interpretation: "穏やかな夕暮れの風景です。",
+			want:           []Violation{ViolationLength},
+		},
+		{
+			name:           "異常系：長すぎる",
+			interpretation: strings.Repeat("沈みかけた太陽の橙色が水面に溶けています。", 3),
+			want:           []Violation{ViolationLength},
+		},
+		{
+			name:           "異常系：投稿者の解釈の焼き直し",
+			interpretation: "夕暮れの港で、遠ざかる船を見送る人の背中に、言葉にならない寂しさを込めました。",
+			want:           []Violation{ViolationDuplicate},
+		},
+		{
+			name:           "異常系：生成の拒否",
+			interpretation: "申し訳ありませんが、この画像について新しい解釈を生成することはできません。ご了承ください。",
+			want:           []Violation{ViolationRefusal},
+		},
+		{
+			name:           "異常系：英語での拒否",
+			interpretation: "I'm sorry, but I cannot describe this image.",
+			want:           []Violation{ViolationRefusal},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateInterpretation(author, tt.interpretation)
+			if len(got) != len(tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("want %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+// sequenceModel は呼び出されるたびに texts を順に返すモデルを作成します
+func sequenceModel(calls *int, texts ...string) *MockGenerativeModel {
+	return &MockGenerativeModel{
+		generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+			text := texts[min(*calls, len(texts)-1)]
+			*calls++
+			return &genai.GenerateContentResponse{
+				Candidates: []*genai.Candidate{
+					{Content: &genai.Content{Parts: []genai.Part{genai.Text(text)}}},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestValidatingClient(t *testing.T) {
+	author := "夕暮れの港で、遠ざかる船を見送る人の背中に、言葉にできない寂しさを込めました。"
+	valid := "沈みかけた太陽の橙色が水面に溶けて、一日の終わりの穏やかな安らぎを描いています。"
+	refusal := "申し訳ありませんが、この画像について新しい解釈を生成することはできません。ご了承ください。"
+
+	t.Run("検証に通らない場合は生成し直す", func(t *testing.T) {
+		calls := 0
+		client := NewValidatingClient(&Client{model: sequenceModel(&calls, refusal, author, valid+"\n")}, 3)
+
+		got, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, DefaultPersona)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != valid {
+			t.Errorf("want %q, got %q", valid, got)
+		}
+		if calls != 3 {
+			t.Errorf("want 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("試行回数の上限に達した場合は ValidationError", func(t *testing.T) {
+		calls := 0
+		client := NewValidatingClient(&Client{model: sequenceModel(&calls, refusal)}, 2)
+
+		_, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, DefaultPersona)
+		if !errors.Is(err, ErrInvalidOutput) {
+			t.Fatalf("expected ErrInvalidOutput, got %v", err)
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected *ValidationError, got %T", err)
+		}
+		if validationErr.Attempts != 2 || len(validationErr.Violations) != 1 || validationErr.Violations[0] != ViolationRefusal {
+			t.Errorf("unexpected error: %+v", validationErr)
+		}
+		if calls != 2 {
+			t.Errorf("want 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("内容のない候補は生成し直す", func(t *testing.T) {
+		calls := 0
+		client := NewValidatingClient(&Client{model: &MockGenerativeModel{
+			generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+				calls++
+				if calls == 1 {
+					// 安全フィルターでブロックされた候補は Content を持たない
+					return &genai.GenerateContentResponse{
+						Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonSafety}},
+					}, nil
+				}
+				return &genai.GenerateContentResponse{
+					Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text(valid)}}}},
+				}, nil
+			},
+		}}, 3)
+
+		got, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, DefaultPersona)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != valid || calls != 2 {
+			t.Errorf("want %q after 2 calls, got %q after %d calls", valid, got, calls)
+		}
+	})
+
+	t.Run("内容のない候補が続く場合は ValidationError", func(t *testing.T) {
+		client := NewValidatingClient(&Client{model: &MockGenerativeModel{
+			generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+				return &genai.GenerateContentResponse{
+					Candidates: []*genai.Candidate{{Content: &genai.Content{}, FinishReason: genai.FinishReasonSafety}},
+				}, nil
+			},
+		}}, 2)
+
+		_, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, DefaultPersona)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected *ValidationError, got %v", err)
+		}
+		if len(validationErr.Violations) != 1 || validationErr.Violations[0] != ViolationEmpty {
+			t.Errorf("unexpected violations: %v", validationErr.Violations)
+		}
+	})
+
+	t.Run("通信エラーは生成し直さない", func(t *testing.T) {
+		calls := 0
+		client := NewValidatingClient(&Client{model: &MockGenerativeModel{
+			generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+				calls++
+				return nil, errors.New("unavailable")
+			},
+		}}, 3)
+
+		if _, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, DefaultPersona); err == nil || errors.Is(err, ErrInvalidOutput) {
+			t.Fatalf("expected communication error, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("want 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("呼び出しを遮断したら生成し直さない", func(t *testing.T) {
+		calls := 0
+		model := &MockGenerativeModel{
+			generateContentFunc: func(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+				calls++
+				if calls == 1 {
+					return &genai.GenerateContentResponse{
+						Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []genai.Part{genai.Text(refusal)}}}},
+					}, nil
+				}
+				return nil, status.Error(codes.Unavailable, "unavailable")
+			},
+		}
+		var delays []time.Duration
+		resilient := newTestResilientClient(model, &delays, WithMaxRetries(3), WithCircuitBreaker(2, time.Minute))
+		client := NewValidatingClient(resilient, 5)
+
+		// 1回目は検証に通らず生成し直し、2回目のやり直しの後に遮断する
+		_, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, DefaultPersona)
+		if !errors.Is(err, ErrUnavailable) || !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected ErrCircuitOpen, got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("want 3 calls, got %d", calls)
+		}
+
+		// 遮断中はモデルを呼ばずにすぐ失敗する
+		if _, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, DefaultPersona); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected ErrCircuitOpen, got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("want no calls while open, got %d", calls)
+		}
+	})
+
+	t.Run("スタブの解釈は検証に通る", func(t *testing.T) {
+		client := NewValidatingClient(NewStubClient(), 1)
+		for _, persona := range Personas {
+			if _, err := client.GenerateInterpretation(context.Background(), []byte("image"), "image/png", author, persona); err != nil {
+				t.Errorf("persona %s: unexpected error: %v", persona.Name, err)
+			}
+		}
+	})
+}
+
+func TestValidateInterpretation_MixedCharacters(t *testing.T) {
+	// 20文字（日本語と英数字の混在）で、バイト数では 34 バイトになる
+	author := "東京タワーとTokyo Skytreeの"
+	tests := []struct {
+		name           string
+		interpretation string
+		wantLength     bool
+	}{
+		{name: "下限の16文字", interpretation: "夜のSkyline光の粒が揺れる"},
+		{name: "上限の24文字", interpretation: "街の灯りがCity Lightsとして川面に映る"},
+		{name: "15文字は短すぎる", interpretation: "夜のSkyline光の粒が揺れ", wantLength: true},
+		// バイト数で比較すると範囲内に見えるが、文字数では長すぎる
+		{name: "英字だけの30文字は長すぎる", interpretation: "Tokyo Tower glows at midnight.", wantLength: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLength := false
+			for _, v := range ValidateInterpretation(author, tt.interpretation) {
+				if v == ViolationLength {
+					gotLength = true
+				}
+			}
+			if gotLength != tt.wantLength {
+				t.Errorf("want length violation %v, got %v (%d文字)", tt.wantLength, gotLength, utf8.RuneCountInString(tt.interpretation))
+			}
+		})
+	}
+}
+
+func TestLengthRange(t *testing.T) {
+	tests := []struct {
+		length  int
+		wantMin int
+		wantMax int
+	}{
+		{length: 1, wantMin: 1, wantMax: 2},
+		{length: 2, wantMin: 1, wantMax: 3},
+		{length: 3, wantMin: 2, wantMax: 4},
+		{length: 4, wantMin: 3, wantMax: 5},
+		{length: 5, wantMin: 4, wantMax: 6},
+		{length: 6, wantMin: 4, wantMax: 8},
+		{length: 7, wantMin: 5, wantMax: 9},
+		{length: 8, wantMin: 6, wantMax: 10},
+		{length: 9, wantMin: 7, wantMax: 11},
+		{length: 10, wantMin: 8, wantMax: 12},
+		{length: 20, wantMin: 16, wantMax: 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d文字", tt.length), func(t *testing.T) {
+			gotMin, gotMax := lengthRange(tt.length)
+			if gotMin != tt.wantMin || gotMax != tt.wantMax {
+				t.Errorf("lengthRange(%d) = [%d, %d], want [%d, %d]", tt.length, gotMin, gotMax, tt.wantMin, tt.wantMax)
+			}
+			// 投稿者の解釈と同じ文字数以外の長さも受け付ける（1文字の場合は短い側に幅を持たせられない）
+			if (tt.length > 1 && gotMin >= tt.length) || gotMax <= tt.length {
+				t.Errorf("lengthRange(%d) = [%d, %d] has no tolerance", tt.length, gotMin, gotMax)
+			}
+		})
+	}
+}
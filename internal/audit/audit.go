@@ -0,0 +1,52 @@
+// Package audit は権限が必要な操作の記録（監査ログ）を提供します
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+// Entry は監査ログの1件分の記録を表します
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Actor は操作した利用者のIDです（未認証の場合は空）
+	Actor string `json:"actor"`
+	// Action は操作の種類です
+	Action string `json:"action"`
+	// Target は操作の対象です（クイズIDなど）
+	Target     string `json:"target,omitempty"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	// Allowed は操作が許可されたかを表します
+	Allowed bool `json:"allowed"`
+	// Status は応答したHTTPステータスコードです
+	Status int `json:"status"`
+}
+
+// Logger は監査ログの記録先を表すインターフェースです
+type Logger interface {
+	Record(ctx context.Context, entry *Entry)
+}
+
+// logLogger は監査ログをアプリケーションのログに1行のJSONとして出力します
+// Cloud Run ではログがそのまま Cloud Logging に保存されます
+type logLogger struct{}
+
+// NewLogger はアプリケーションのログに出力する監査ログを作成します
+func NewLogger() Logger {
+	return logLogger{}
+}
+
+// Record は監査ログを出力します
+func (logLogger) Record(ctx context.Context, entry *Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logging.Error("audit: 監査ログの変換に失敗: %v", err)
+		return
+	}
+	logging.Info("audit: %s", data)
+}
@@ -0,0 +1,72 @@
+// Package auth はHTTPリクエストの呼び出し元を識別するための認証機能を提供します
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNoToken はリクエストにトークンが含まれていないことを表します
+	ErrNoToken = errors.New("認証トークンがありません")
+	// ErrInvalidToken はトークンの形式・署名・有効期限のいずれかが不正であることを表します
+	ErrInvalidToken = errors.New("認証トークンが不正です")
+)
+
+// Identity は認証済みの呼び出し元を表します
+type Identity struct {
+	// UserID は利用者を一意に識別するIDです
+	UserID string `json:"sub"`
+	// DisplayName は画面に表示する利用者の名前です
+	DisplayName string `json:"name,omitempty"`
+	// Roles は利用者に付与されたロールです
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Verifier はトークンを検証し、呼び出し元を返すインターフェースです
+// 外部の認証基盤に合わせて実装を差し替えられます
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Identity, error)
+}
+
+// RoleAdmin は破壊的な操作や管理操作を許可されたロールです
+const RoleAdmin = "admin"
+
+// HasRole は呼び出し元が指定のロールを持つかを返します
+func (i *Identity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// WithIdentity は呼び出し元を保持したコンテキストを返します
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext はコンテキストから呼び出し元を取得します
+// 認証されていないリクエストの場合は false を返します
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// BearerToken は Authorization ヘッダーから Bearer トークンを取り出します
+func BearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", ErrNoToken
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrInvalidToken
+	}
+	return strings.TrimSpace(token), nil
+}
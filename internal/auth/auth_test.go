@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    string
+		wantErr error
+	}{
+		{name: "Bearer トークン", header: "Bearer abc.def.ghi", want: "abc.def.ghi"},
+		{name: "小文字の scheme", header: "bearer abc", want: "abc"},
+		{name: "ヘッダーなし", header: "", wantErr: ErrNoToken},
+		{name: "Basic 認証", header: "Basic dXNlcjpwYXNz", wantErr: ErrInvalidToken},
+		{name: "トークンが空", header: "Bearer ", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			got, err := BearerToken(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIdentityContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("expected no identity in empty context")
+	}
+
+	ctx := WithIdentity(context.Background(), &Identity{UserID: "user-1"})
+	identity, ok := FromContext(ctx)
+	if !ok || identity.UserID != "user-1" {
+		t.Errorf("unexpected identity: %+v, %v", identity, ok)
+	}
+}
+
+func TestIdentityHasRole(t *testing.T) {
+	identity := &Identity{UserID: "user-1", Roles: []string{"editor", RoleAdmin}}
+	if !identity.HasRole(RoleAdmin) {
+		t.Error("expected identity to have admin role")
+	}
+	if identity.HasRole("owner") {
+		t.Error("unexpected role")
+	}
+	if (&Identity{UserID: "user-2"}).HasRole(RoleAdmin) {
+		t.Error("identity without roles must not have admin role")
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTokenTTL はトークンの有効期間が指定されていない場合に使用する期間です
+const DefaultTokenTTL = 24 * time.Hour
+
+// jwtHeader は HS256 で署名したトークンのヘッダーです
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// claims はトークンに含める情報です
+type claims struct {
+	Identity
+	Issuer    string `json:"iss,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// JWTSigner は共有の秘密鍵（HS256）でトークンの発行と検証を行います
+// 外部の認証基盤を用意できない開発環境や小規模な運用で、その代わりとして使用します
+type JWTSigner struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewJWTSigner は新しいJWTSignerを作成します
+func NewJWTSigner(secret []byte, issuer string, ttl time.Duration) (*JWTSigner, error) {
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("秘密鍵は32バイト以上必要です")
+	}
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+	return &JWTSigner{
+		secret: secret,
+		issuer: issuer,
+		ttl:    ttl,
+		now:    time.Now,
+	}, nil
+}
+
+// Sign は呼び出し元の情報を含むトークンを発行します
+func (s *JWTSigner) Sign(identity *Identity) (string, error) {
+	if identity == nil || identity.UserID == "" {
+		return "", fmt.Errorf("ユーザーIDが必要です")
+	}
+
+	now := s.now()
+	payload, err := json.Marshal(claims{
+		Identity:  *identity,
+		Issuer:    s.issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.ttl).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("トークンの作成に失敗: %w", err)
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + s.signature(unsigned), nil
+}
+
+// Verify はトークンの署名・発行者・有効期限を検証し、呼び出し元を返します
+func (s *JWTSigner) Verify(ctx context.Context, token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		// ヘッダーを固定値と比較することで、alg の差し替え（"none" など）を受け付けない
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(s.signature(parts[0]+"."+parts[1])), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if c.UserID == "" || c.Issuer != s.issuer {
+		return nil, ErrInvalidToken
+	}
+	if !s.now().Before(time.Unix(c.ExpiresAt, 0)) {
+		return nil, fmt.Errorf("%w: 有効期限切れ", ErrInvalidToken)
+	}
+
+	identity := c.Identity
+	return &identity, nil
+}
+
+// signature は署名対象の文字列に対する HMAC-SHA256 の署名を返します
+func (s *JWTSigner) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestSigner(t *testing.T) *JWTSigner {
+	t.Helper()
+	signer, err := NewJWTSigner(testSecret, "test-issuer", time.Hour)
+	if err != nil {
+		t.Fatalf("NewJWTSigner failed: %v", err)
+	}
+	return signer
+}
+
+func TestJWTSigner_RoundTrip(t *testing.T) {
+	signer := newTestSigner(t)
+
+	token, err := signer.Sign(&Identity{UserID: "user-1", DisplayName: "山田", Roles: []string{"admin"}})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	identity, err := signer.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if identity.UserID != "user-1" || identity.DisplayName != "山田" || len(identity.Roles) != 1 || identity.Roles[0] != "admin" {
+		t.Errorf("unexpected identity: %+v", identity)
+	}
+}
+
+func TestJWTSigner_RejectsInvalidTokens(t *testing.T) {
+	signer := newTestSigner(t)
+	token, err := signer.Sign(&Identity{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	parts := strings.Split(token, ".")
+
+	otherSecret, _ := NewJWTSigner([]byte("fedcba9876543210fedcba9876543210"), "test-issuer", time.Hour)
+	otherToken, _ := otherSecret.Sign(&Identity{UserID: "user-1"})
+
+	otherIssuer, _ := NewJWTSigner(testSecret, "other-issuer", time.Hour)
+	otherIssuerToken, _ := otherIssuer.Sign(&Identity{UserID: "user-1"})
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","iss":"test-issuer","exp":9999999999}`))
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+
+	tests := map[string]string{
+		"空文字":       "",
+		"形式が不正":     "not-a-token",
+		"ペイロードの改ざん": parts[0] + "." + forged + "." + parts[2],
+		"署名なし":      parts[0] + "." + parts[1] + ".",
+		"alg=none":  noneHeader + "." + parts[1] + ".",
+		"別の秘密鍵で署名":  otherToken,
+		"別の発行者":     otherIssuerToken,
+		"余分なセグメント":  token + ".extra",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := signer.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJWTSigner_Expired(t *testing.T) {
+	signer := newTestSigner(t)
+	issuedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	signer.now = func() time.Time { return issuedAt }
+
+	token, err := signer.Sign(&Identity{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	signer.now = func() time.Time { return issuedAt.Add(59 * time.Minute) }
+	if _, err := signer.Verify(context.Background(), token); err != nil {
+		t.Errorf("expected token to be valid before expiry, got %v", err)
+	}
+
+	signer.now = func() time.Time { return issuedAt.Add(time.Hour) }
+	if _, err := signer.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken after expiry, got %v", err)
+	}
+}
+
+func TestNewJWTSigner_Validation(t *testing.T) {
+	if _, err := NewJWTSigner([]byte("short"), "", time.Hour); err == nil {
+		t.Error("expected error for short secret, got nil")
+	}
+
+	signer, err := NewJWTSigner(testSecret, "", 0)
+	if err != nil {
+		t.Fatalf("NewJWTSigner failed: %v", err)
+	}
+	if signer.ttl != DefaultTokenTTL {
+		t.Errorf("expected default TTL %v, got %v", DefaultTokenTTL, signer.ttl)
+	}
+	if _, err := signer.Sign(&Identity{}); err == nil {
+		t.Error("expected error for empty user ID, got nil")
+	}
+}
@@ -3,25 +3,101 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	// StorageBackendGCS はCloud Storageを使用するストレージバックエンド
+	StorageBackendGCS = "gcs"
+	// StorageBackendLocal はローカルディレクトリを使用するストレージバックエンド
+	StorageBackendLocal = "local"
+)
+
+const (
+	// AIProviderVertex はVertex AIを使用するAIプロバイダー
+	AIProviderVertex = "vertex"
+	// AIProviderOpenAI はOpenAI互換のエンドポイントを使用するAIプロバイダー
+	AIProviderOpenAI = "openai"
+	// AIProviderStub はネットワークを使用しないスタブのAIプロバイダー
+	AIProviderStub = "stub"
+)
+
+const (
+	// SignedURLMethodV4 は V4 署名の署名付きURLで画像を配信します
+	SignedURLMethodV4 = "v4"
+	// SignedURLMethodV2 は V2 署名の署名付きURLで画像を配信します
+	SignedURLMethodV2 = "v2"
+	// SignedURLMethodProxy は署名せず、サーバーの画像プロキシで画像を配信します
+	SignedURLMethodProxy = "proxy"
+)
+
+// maxSignedURLTTL は V4 署名で指定できる有効期間の上限です
+const maxSignedURLTTL = 7 * 24 * time.Hour
+
 // Config はアプリケーションの設定を保持する構造体
 type Config struct {
-	ProjectID  string
-	Location   string
-	BucketName string
-	Port       string
+	ProjectID       string
+	Location        string
+	BucketName      string
+	Port            string
+	StorageBackend  string
+	LocalStorageDir string
+	PublicBaseURL   string
+	AIProvider      string
+	AIModel         string
+	AIEndpoint      string
+	AIAPIKey        string
+	// AIMaxAttempts は検証に通らなかったAIの解釈を生成し直す場合の試行回数の上限です（0 の場合は ai.DefaultMaxAttempts）
+	AIMaxAttempts int
+	// AIMaxRetries はAIサービスの一時的なエラーで呼び出しをやり直す回数です
+	AIMaxRetries int
+	// AICallTimeout はAIサービスの1回の呼び出しのタイムアウトです
+	AICallTimeout time.Duration
+	// AIGenerateTimeout はクイズ1件分のおとりの解釈の生成全体にかける時間の上限です（やり直しと生成し直しを含みます）
+	AIGenerateTimeout time.Duration
+	// AnswerSecret は選択肢IDの生成に使用する秘密鍵です
+	// Cloud Storage では複数のインスタンスで同じ値を使う必要があるため必須です（ローカルストレージで未設定の場合は起動ごとにランダム）
+	AnswerSecret string
+	// AuthSecret は認証トークンの署名に使用する秘密鍵です（32バイト以上）
+	// Cloud Storage では複数のインスタンスで同じ値を使う必要があるため必須です（ローカルストレージで未設定の場合は起動ごとにランダム）
+	AuthSecret string
+	// AuthIssuer は認証トークンの発行者です
+	AuthIssuer string
+	// AuthTokenTTL は発行する認証トークンの有効期間です
+	AuthTokenTTL time.Duration
+	// AllowedImageTypes はアップロードを受け付ける画像の MIME タイプです（空の場合はサービスの既定の形式）
+	AllowedImageTypes []string
+	// HEICConverter は HEIC の画像を変換する libheif のコマンドです（空の場合は heif-convert）
+	HEICConverter string
+	// SignedURLTTL は画像の署名付きURLの有効期間です
+	SignedURLTTL time.Duration
+	// SignedURLMethod は画像URLの発行方法です（v4, v2 または proxy）
+	SignedURLMethod string
+	// JobWorkers はクイズを非同期に作成するワーカーの数です（0 の場合は非同期の作成を無効にします）
+	JobWorkers int
 }
 
 // Load は環境変数から設定を読み込む
 func Load() (*Config, error) {
+	aiProvider := os.Getenv("AI_PROVIDER")
+	if aiProvider == "" {
+		aiProvider = AIProviderVertex // デフォルトはVertex AI
+	}
+
 	projectID := os.Getenv("PROJECT_ID")
-	if projectID == "" {
+	if projectID == "" && aiProvider == AIProviderVertex {
 		return nil, fmt.Errorf("PROJECT_ID environment variable is not set")
 	}
 
+	storageBackend := os.Getenv("STORAGE_BACKEND")
+	if storageBackend == "" {
+		storageBackend = StorageBackendGCS // デフォルトはCloud Storage
+	}
+
 	bucketName := os.Getenv("BUCKET_NAME")
-	if bucketName == "" {
+	if bucketName == "" && storageBackend == StorageBackendGCS {
 		return nil, fmt.Errorf("BUCKET_NAME environment variable is not set")
 	}
 
@@ -30,12 +106,122 @@ func Load() (*Config, error) {
 		port = "8080" // デフォルトポート
 	}
 
-	return &Config{
-		ProjectID:  projectID,
-		Location:   "us-central1",
-		BucketName: bucketName,
-		Port:       port,
-	}, nil
+	localStorageDir := os.Getenv("LOCAL_STORAGE_DIR")
+	if localStorageDir == "" {
+		localStorageDir = "./data" // デフォルトの保存先
+	}
+
+	authIssuer := os.Getenv("AUTH_ISSUER")
+	if authIssuer == "" {
+		authIssuer = "zenn-ai-hackathon" // デフォルトの発行者
+	}
+
+	authTokenTTL := 24 * time.Hour // デフォルトの有効期間
+	if v := os.Getenv("AUTH_TOKEN_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTH_TOKEN_TTL: %w", err)
+		}
+		authTokenTTL = ttl
+	}
+
+	signedURLTTL := 15 * time.Minute // デフォルトの有効期間
+	if v := os.Getenv("SIGNED_URL_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SIGNED_URL_TTL: %w", err)
+		}
+		signedURLTTL = ttl
+	}
+
+	signedURLMethod := strings.ToLower(os.Getenv("SIGNED_URL_METHOD"))
+	if signedURLMethod == "" {
+		signedURLMethod = SignedURLMethodV4 // デフォルトはV4署名
+	}
+
+	jobWorkers := 2 // デフォルトのワーカー数
+	if v := os.Getenv("JOB_WORKERS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JOB_WORKERS: %w", err)
+		}
+		jobWorkers = n
+	}
+
+	aiMaxAttempts := 3 // デフォルトの試行回数
+	if v := os.Getenv("AI_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AI_MAX_ATTEMPTS: %w", err)
+		}
+		aiMaxAttempts = n
+	}
+
+	aiMaxRetries := 3 // デフォルトのやり直しの回数
+	if v := os.Getenv("AI_MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AI_MAX_RETRIES: %w", err)
+		}
+		aiMaxRetries = n
+	}
+
+	aiCallTimeout := 30 * time.Second // デフォルトのタイムアウト
+	if v := os.Getenv("AI_CALL_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AI_CALL_TIMEOUT: %w", err)
+		}
+		aiCallTimeout = d
+	}
+
+	aiGenerateTimeout := 2 * time.Minute // デフォルトの上限
+	if v := os.Getenv("AI_GENERATE_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AI_GENERATE_TIMEOUT: %w", err)
+		}
+		aiGenerateTimeout = d
+	}
+
+	var allowedImageTypes []string
+	for _, v := range strings.Split(os.Getenv("ALLOWED_IMAGE_TYPES"), ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			allowedImageTypes = append(allowedImageTypes, v)
+		}
+	}
+
+	cfg := &Config{
+		ProjectID:         projectID,
+		Location:          "us-central1",
+		BucketName:        bucketName,
+		Port:              port,
+		StorageBackend:    storageBackend,
+		LocalStorageDir:   localStorageDir,
+		PublicBaseURL:     os.Getenv("PUBLIC_BASE_URL"),
+		AIProvider:        aiProvider,
+		AIModel:           os.Getenv("AI_MODEL"),
+		AIEndpoint:        os.Getenv("AI_ENDPOINT"),
+		AIAPIKey:          os.Getenv("AI_API_KEY"),
+		AIMaxAttempts:     aiMaxAttempts,
+		AIMaxRetries:      aiMaxRetries,
+		AICallTimeout:     aiCallTimeout,
+		AIGenerateTimeout: aiGenerateTimeout,
+		AnswerSecret:      os.Getenv("ANSWER_SECRET"),
+		AuthSecret:        os.Getenv("AUTH_SECRET"),
+		AuthIssuer:        authIssuer,
+		AuthTokenTTL:      authTokenTTL,
+
+		AllowedImageTypes: allowedImageTypes,
+		HEICConverter:     os.Getenv("HEIC_CONVERTER"),
+		SignedURLTTL:      signedURLTTL,
+		SignedURLMethod:   signedURLMethod,
+		JobWorkers:        jobWorkers,
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
 // GetPort はポート番号を:8080の形式で返します
@@ -45,11 +231,44 @@ func (c *Config) GetPort() string {
 
 // Validate は設定値の検証を行います
 func (c *Config) Validate() error {
-	if c.ProjectID == "" {
-		return fmt.Errorf("ProjectID is required")
+	switch c.AIProvider {
+	case "", AIProviderVertex:
+		if c.ProjectID == "" {
+			return fmt.Errorf("ProjectID is required")
+		}
+	case AIProviderOpenAI, AIProviderStub:
+	default:
+		return fmt.Errorf("unsupported AIProvider: %s", c.AIProvider)
+	}
+	switch c.StorageBackend {
+	case "", StorageBackendGCS:
+		if c.BucketName == "" {
+			return fmt.Errorf("BucketName is required")
+		}
+		if c.AnswerSecret == "" {
+			return fmt.Errorf("AnswerSecret is required for the %s storage backend", StorageBackendGCS)
+		}
+		if c.AuthSecret == "" {
+			return fmt.Errorf("AuthSecret is required for the %s storage backend", StorageBackendGCS)
+		}
+	case StorageBackendLocal:
+		if c.LocalStorageDir == "" {
+			return fmt.Errorf("LocalStorageDir is required")
+		}
+	default:
+		return fmt.Errorf("unsupported StorageBackend: %s", c.StorageBackend)
+	}
+	if c.AIMaxAttempts < 0 {
+		return fmt.Errorf("AIMaxAttempts must not be negative")
 	}
-	if c.BucketName == "" {
-		return fmt.Errorf("BucketName is required")
+	if c.AIMaxRetries < 0 {
+		return fmt.Errorf("AIMaxRetries must not be negative")
+	}
+	if c.AICallTimeout < 0 {
+		return fmt.Errorf("AICallTimeout must not be negative")
+	}
+	if c.AIGenerateTimeout < 0 {
+		return fmt.Errorf("AIGenerateTimeout must not be negative")
 	}
 	if c.Location == "" {
 		return fmt.Errorf("Location is required")
@@ -57,5 +276,31 @@ func (c *Config) Validate() error {
 	if c.Port == "" {
 		return fmt.Errorf("Port is required")
 	}
+	if c.AuthSecret != "" && len(c.AuthSecret) < 32 {
+		return fmt.Errorf("AuthSecret must be at least 32 bytes")
+	}
+	if c.AuthTokenTTL < 0 {
+		return fmt.Errorf("AuthTokenTTL must not be negative")
+	}
+	switch c.SignedURLMethod {
+	case "", SignedURLMethodV4:
+		if c.SignedURLTTL > maxSignedURLTTL {
+			return fmt.Errorf("SignedURLTTL must not exceed %s for v4 signing", maxSignedURLTTL)
+		}
+	case SignedURLMethodV2, SignedURLMethodProxy:
+	default:
+		return fmt.Errorf("unsupported SignedURLMethod: %s", c.SignedURLMethod)
+	}
+	if c.SignedURLTTL < 0 {
+		return fmt.Errorf("SignedURLTTL must not be negative")
+	}
+	if c.JobWorkers < 0 {
+		return fmt.Errorf("JobWorkers must not be negative")
+	}
+	for _, t := range c.AllowedImageTypes {
+		if !strings.HasPrefix(t, "image/") {
+			return fmt.Errorf("invalid AllowedImageTypes entry: %s", t)
+		}
+	}
 	return nil
 }
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -30,6 +32,88 @@ func TestLoad(t *testing.T) {
 			},
 			wantError: false,
 		},
+		{
+			name: "正常系：ローカルストレージ使用時はBUCKET_NAME不要",
+			envVars: map[string]string{
+				"PROJECT_ID":        "test-project",
+				"STORAGE_BACKEND":   "local",
+				"LOCAL_STORAGE_DIR": "/tmp/quiz-data",
+			},
+			wantError: false,
+		},
+		{
+			name: "正常系：スタブのAIプロバイダー使用時はPROJECT_ID不要",
+			envVars: map[string]string{
+				"AI_PROVIDER":     "stub",
+				"STORAGE_BACKEND": "local",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：未対応のAIプロバイダー",
+			envVars: map[string]string{
+				"PROJECT_ID":  "test-project",
+				"BUCKET_NAME": "test-bucket",
+				"AI_PROVIDER": "unknown",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：未対応のストレージバックエンド",
+			envVars: map[string]string{
+				"PROJECT_ID":      "test-project",
+				"BUCKET_NAME":     "test-bucket",
+				"STORAGE_BACKEND": "s3",
+			},
+			wantError: true,
+		},
+		{
+			name: "正常系：認証トークンの設定",
+			envVars: map[string]string{
+				"PROJECT_ID":     "test-project",
+				"BUCKET_NAME":    "test-bucket",
+				"AUTH_SECRET":    "0123456789abcdef0123456789abcdef",
+				"AUTH_TOKEN_TTL": "2h",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：短すぎる認証の秘密鍵",
+			envVars: map[string]string{
+				"PROJECT_ID":  "test-project",
+				"BUCKET_NAME": "test-bucket",
+				"AUTH_SECRET": "short",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：不正な認証トークンの有効期間",
+			envVars: map[string]string{
+				"PROJECT_ID":     "test-project",
+				"BUCKET_NAME":    "test-bucket",
+				"AUTH_TOKEN_TTL": "one day",
+			},
+			wantError: true,
+		},
+		{
+			name: "正常系：受け付ける画像形式の設定",
+			envVars: map[string]string{
+				"PROJECT_ID":          "test-project",
+				"BUCKET_NAME":         "test-bucket",
+				"ALLOWED_IMAGE_TYPES": "image/jpeg, Image/PNG,,image/webp",
+				"HEIC_CONVERTER":      "/usr/local/bin/heif-dec",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：画像ではない形式",
+			envVars: map[string]string{
+				"PROJECT_ID":          "test-project",
+				"BUCKET_NAME":         "test-bucket",
+				"ALLOWED_IMAGE_TYPES": "image/jpeg,application/pdf",
+			},
+			wantError: true,
+		},
 		{
 			name: "異常系：PROJECT_IDなし",
 			envVars: map[string]string{
@@ -37,6 +121,126 @@ func TestLoad(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "正常系：署名付きURLの設定",
+			envVars: map[string]string{
+				"PROJECT_ID":        "test-project",
+				"BUCKET_NAME":       "test-bucket",
+				"SIGNED_URL_TTL":    "1h",
+				"SIGNED_URL_METHOD": "V2",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：未対応の署名方式",
+			envVars: map[string]string{
+				"PROJECT_ID":        "test-project",
+				"BUCKET_NAME":       "test-bucket",
+				"SIGNED_URL_METHOD": "v3",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：V4署名の上限を超える有効期間",
+			envVars: map[string]string{
+				"PROJECT_ID":     "test-project",
+				"BUCKET_NAME":    "test-bucket",
+				"SIGNED_URL_TTL": "200h",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：不正な署名付きURLの有効期間",
+			envVars: map[string]string{
+				"PROJECT_ID":     "test-project",
+				"BUCKET_NAME":    "test-bucket",
+				"SIGNED_URL_TTL": "soon",
+			},
+			wantError: true,
+		},
+		{
+			name: "正常系：非同期のワーカー数",
+			envVars: map[string]string{
+				"PROJECT_ID":  "test-project",
+				"BUCKET_NAME": "test-bucket",
+				"JOB_WORKERS": "0",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：不正なワーカー数",
+			envVars: map[string]string{
+				"PROJECT_ID":  "test-project",
+				"BUCKET_NAME": "test-bucket",
+				"JOB_WORKERS": "-1",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：不正なAIの試行回数",
+			envVars: map[string]string{
+				"PROJECT_ID":      "test-project",
+				"BUCKET_NAME":     "test-bucket",
+				"AI_MAX_ATTEMPTS": "-1",
+			},
+			wantError: true,
+		},
+		{
+			name: "正常系：AIの呼び出しのやり直しとタイムアウト",
+			envVars: map[string]string{
+				"PROJECT_ID":      "test-project",
+				"BUCKET_NAME":     "test-bucket",
+				"AI_MAX_RETRIES":  "0",
+				"AI_CALL_TIMEOUT": "10s",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：不正なAIの呼び出しのタイムアウト",
+			envVars: map[string]string{
+				"PROJECT_ID":      "test-project",
+				"BUCKET_NAME":     "test-bucket",
+				"AI_CALL_TIMEOUT": "soon",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：不正なAIの生成全体の上限",
+			envVars: map[string]string{
+				"PROJECT_ID":          "test-project",
+				"BUCKET_NAME":         "test-bucket",
+				"AI_GENERATE_TIMEOUT": "later",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：Cloud StorageでANSWER_SECRETなし",
+			envVars: map[string]string{
+				"PROJECT_ID":    "test-project",
+				"BUCKET_NAME":   "test-bucket",
+				"ANSWER_SECRET": "",
+			},
+			wantError: true,
+		},
+		{
+			name: "異常系：Cloud StorageでAUTH_SECRETなし",
+			envVars: map[string]string{
+				"PROJECT_ID":  "test-project",
+				"BUCKET_NAME": "test-bucket",
+				"AUTH_SECRET": "",
+			},
+			wantError: true,
+		},
+		{
+			name: "正常系：ローカルストレージはANSWER_SECRETとAUTH_SECRET不要",
+			envVars: map[string]string{
+				"AI_PROVIDER":     "stub",
+				"STORAGE_BACKEND": "local",
+				"ANSWER_SECRET":   "",
+				"AUTH_SECRET":     "",
+			},
+			wantError: false,
+		},
 		{
 			name: "異常系：BUCKET_NAMEなし",
 			envVars: map[string]string{
@@ -51,6 +255,10 @@ func TestLoad(t *testing.T) {
 			// 環境変数をクリア
 			os.Clearenv()
 
+			// 秘密鍵は各ケースで上書きしない限り設定しておく
+			os.Setenv("ANSWER_SECRET", "test-answer-secret")
+			os.Setenv("AUTH_SECRET", "test-auth-secret-0123456789abcdef")
+
 			// テスト用の環境変数を設定
 			for k, v := range tt.envVars {
 				os.Setenv(k, v)
@@ -86,6 +294,62 @@ func TestLoad(t *testing.T) {
 			if tt.envVars["PORT"] == "" && cfg.Port != "8080" {
 				t.Errorf("expected default Port %q, got %q", "8080", cfg.Port)
 			}
+			if tt.envVars["AI_PROVIDER"] == "" && cfg.AIProvider != AIProviderVertex {
+				t.Errorf("expected default AIProvider %q, got %q", AIProviderVertex, cfg.AIProvider)
+			}
+			if tt.envVars["STORAGE_BACKEND"] == "" && cfg.StorageBackend != StorageBackendGCS {
+				t.Errorf("expected default StorageBackend %q, got %q", StorageBackendGCS, cfg.StorageBackend)
+			}
+			if tt.envVars["AUTH_TOKEN_TTL"] == "" && cfg.AuthTokenTTL != 24*time.Hour {
+				t.Errorf("expected default AuthTokenTTL %v, got %v", 24*time.Hour, cfg.AuthTokenTTL)
+			}
+			if tt.envVars["AUTH_ISSUER"] == "" && cfg.AuthIssuer == "" {
+				t.Error("expected default AuthIssuer, got empty")
+			}
+			if v := tt.envVars["ALLOWED_IMAGE_TYPES"]; v == "" && cfg.AllowedImageTypes != nil {
+				t.Errorf("expected no AllowedImageTypes, got %v", cfg.AllowedImageTypes)
+			} else if v != "" && strings.Join(cfg.AllowedImageTypes, ",") != "image/jpeg,image/png,image/webp" {
+				t.Errorf("unexpected AllowedImageTypes: %v", cfg.AllowedImageTypes)
+			}
+			if cfg.HEICConverter != tt.envVars["HEIC_CONVERTER"] {
+				t.Errorf("expected HEICConverter %q, got %q", tt.envVars["HEIC_CONVERTER"], cfg.HEICConverter)
+			}
+			if tt.envVars["SIGNED_URL_TTL"] == "" && cfg.SignedURLTTL != 15*time.Minute {
+				t.Errorf("expected default SignedURLTTL %v, got %v", 15*time.Minute, cfg.SignedURLTTL)
+			}
+			if v := tt.envVars["SIGNED_URL_METHOD"]; v == "" && cfg.SignedURLMethod != SignedURLMethodV4 {
+				t.Errorf("expected default SignedURLMethod %q, got %q", SignedURLMethodV4, cfg.SignedURLMethod)
+			} else if v != "" && cfg.SignedURLMethod != strings.ToLower(v) {
+				t.Errorf("expected SignedURLMethod %q, got %q", strings.ToLower(v), cfg.SignedURLMethod)
+			}
+			if v := tt.envVars["JOB_WORKERS"]; v == "" && cfg.JobWorkers != 2 {
+				t.Errorf("expected default JobWorkers 2, got %d", cfg.JobWorkers)
+			} else if v == "0" && cfg.JobWorkers != 0 {
+				t.Errorf("expected JobWorkers 0, got %d", cfg.JobWorkers)
+			}
+			if tt.envVars["AI_MAX_ATTEMPTS"] == "" && cfg.AIMaxAttempts != 3 {
+				t.Errorf("expected default AIMaxAttempts 3, got %d", cfg.AIMaxAttempts)
+			}
+			if v := tt.envVars["AI_MAX_RETRIES"]; v == "" && cfg.AIMaxRetries != 3 {
+				t.Errorf("expected default AIMaxRetries 3, got %d", cfg.AIMaxRetries)
+			} else if v == "0" && cfg.AIMaxRetries != 0 {
+				t.Errorf("expected AIMaxRetries 0, got %d", cfg.AIMaxRetries)
+			}
+			if v := tt.envVars["AI_CALL_TIMEOUT"]; v == "" && cfg.AICallTimeout != 30*time.Second {
+				t.Errorf("expected default AICallTimeout %v, got %v", 30*time.Second, cfg.AICallTimeout)
+			} else if v == "10s" && cfg.AICallTimeout != 10*time.Second {
+				t.Errorf("expected AICallTimeout %v, got %v", 10*time.Second, cfg.AICallTimeout)
+			}
+			if v := tt.envVars["AI_GENERATE_TIMEOUT"]; v == "" && cfg.AIGenerateTimeout != 2*time.Minute {
+				t.Errorf("expected default AIGenerateTimeout %v, got %v", 2*time.Minute, cfg.AIGenerateTimeout)
+			}
+			// 公開URLを設定しない場合は、どの環境でも正しいルート相対URLを使う
+			if cfg.PublicBaseURL != tt.envVars["PUBLIC_BASE_URL"] {
+				t.Errorf("expected PublicBaseURL %q, got %q", tt.envVars["PUBLIC_BASE_URL"], cfg.PublicBaseURL)
+			}
+			if tt.envVars["LOCAL_STORAGE_DIR"] != "" && cfg.LocalStorageDir != tt.envVars["LOCAL_STORAGE_DIR"] {
+				t.Errorf("expected LocalStorageDir %q, got %q", tt.envVars["LOCAL_STORAGE_DIR"], cfg.LocalStorageDir)
+			}
 		})
 	}
 }
@@ -106,13 +370,37 @@ func TestValidate(t *testing.T) {
 	}{
 		{
 			name: "正常系：すべての項目が設定されている",
+			config: &Config{
+				ProjectID:    "test-project",
+				BucketName:   "test-bucket",
+				Location:     "test-location",
+				Port:         "8080",
+				AnswerSecret: "test-answer-secret",
+				AuthSecret:   "test-auth-secret-0123456789abcdef",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：Cloud StorageでAnswerSecretが未設定",
 			config: &Config{
 				ProjectID:  "test-project",
 				BucketName: "test-bucket",
 				Location:   "test-location",
 				Port:       "8080",
+				AuthSecret: "test-auth-secret-0123456789abcdef",
 			},
-			wantError: false,
+			wantError: true,
+		},
+		{
+			name: "異常系：Cloud StorageでAuthSecretが未設定",
+			config: &Config{
+				ProjectID:    "test-project",
+				BucketName:   "test-bucket",
+				Location:     "test-location",
+				Port:         "8080",
+				AnswerSecret: "test-answer-secret",
+			},
+			wantError: true,
 		},
 		{
 			name: "異常系：ProjectIDが未設定",
@@ -132,6 +420,40 @@ func TestValidate(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "正常系：ローカルストレージはBucketName不要",
+			config: &Config{
+				ProjectID:       "test-project",
+				Location:        "test-location",
+				Port:            "8080",
+				StorageBackend:  StorageBackendLocal,
+				LocalStorageDir: "./data",
+			},
+			wantError: false,
+		},
+		{
+			name: "正常系：スタブのAIプロバイダーはProjectID不要",
+			config: &Config{
+				BucketName:   "test-bucket",
+				Location:     "test-location",
+				Port:         "8080",
+				AIProvider:   AIProviderStub,
+				AnswerSecret: "test-answer-secret",
+				AuthSecret:   "test-auth-secret-0123456789abcdef",
+			},
+			wantError: false,
+		},
+		{
+			name: "異常系：未対応のストレージバックエンド",
+			config: &Config{
+				ProjectID:      "test-project",
+				BucketName:     "test-bucket",
+				Location:       "test-location",
+				Port:           "8080",
+				StorageBackend: "s3",
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
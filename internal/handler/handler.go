@@ -59,14 +59,17 @@ func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// クイズの作成
-	quiz, err := h.quizService.CreateQuiz(r.Context(), imageData, authorInterpretation)
+	quiz, err := h.quizService.CreateQuiz(r.Context(), &service.CreateQuizInput{
+		ImageData:            imageData,
+		AuthorInterpretation: authorInterpretation,
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// レスポンスの作成
-	imageURL, err := h.quizService.GetSignedImageURL(r.Context(), quiz.ImagePath)
+	imageURL, err := h.quizService.GetSignedImageURL(r.Context(), quiz.Image(), models.ImageFull)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -98,7 +101,7 @@ func (h *Handler) GetQuiz(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// レスポンスの作成
-	imageURL, err := h.quizService.GetSignedImageURL(r.Context(), quiz.ImagePath)
+	imageURL, err := h.quizService.GetSignedImageURL(r.Context(), quiz.Image(), models.ImageFull)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,159 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	"golang.org/x/image/webp"
+)
+
+// ErrUnsupportedFormat は画像の形式に対応するデコーダーが登録されていないことを表します
+var ErrUnsupportedFormat = errors.New("対応していない画像形式です")
+
+const (
+	// MimeTypeJPEG は JPEG の MIME タイプです
+	MimeTypeJPEG = "image/jpeg"
+	// MimeTypePNG は PNG の MIME タイプです
+	MimeTypePNG = "image/png"
+	// MimeTypeGIF は GIF の MIME タイプです
+	MimeTypeGIF = "image/gif"
+	// MimeTypeWebP は WebP の MIME タイプです
+	MimeTypeWebP = "image/webp"
+	// MimeTypeHEIC は HEIC/HEIF の MIME タイプです
+	MimeTypeHEIC = "image/heic"
+)
+
+// normalizedJPEGQuality は正規化した画像を JPEG にエンコードする際の品質です
+// 元の画像として保存するため、派生画像より高い品質にします
+const normalizedJPEGQuality = 90
+
+// Format は受け付ける画像形式を表します
+type Format struct {
+	// MimeType は形式の MIME タイプです
+	MimeType string
+	// Extensions はファイル名の拡張子です（先頭の . を含む小文字）
+	Extensions []string
+	// Decode は画像データをデコードします（アニメーションは最初のフレームのみ）
+	Decode func(r io.Reader) (image.Image, error)
+	// DecodeConfig は画像全体をデコードせずに大きさを読み取ります（nil の形式は CheckDimensions で受け付けません）
+	DecodeConfig func(r io.Reader) (image.Config, error)
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]Format)
+)
+
+func init() {
+	RegisterFormat(Format{MimeType: MimeTypeJPEG, Extensions: []string{".jpg", ".jpeg"}, Decode: jpeg.Decode, DecodeConfig: jpeg.DecodeConfig})
+	RegisterFormat(Format{MimeType: MimeTypePNG, Extensions: []string{".png"}, Decode: png.Decode, DecodeConfig: png.DecodeConfig})
+	RegisterFormat(Format{MimeType: MimeTypeGIF, Extensions: []string{".gif"}, Decode: gif.Decode, DecodeConfig: gif.DecodeConfig})
+	RegisterFormat(Format{MimeType: MimeTypeWebP, Extensions: []string{".webp"}, Decode: webp.Decode, DecodeConfig: webp.DecodeConfig})
+	// HEIC は cgo なしで使えるデコーダーがないため、変換コマンドが見つかった場合に RegisterHEICConverter で登録します
+}
+
+// RegisterFormat は画像形式とそのデコーダーを登録します
+// 標準ライブラリや golang.org/x/image にデコーダーのない形式は、デコーダーを用意したうえでこの関数で登録します
+// 同じ MIME タイプで二重に登録した場合はpanicします
+func RegisterFormat(format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if format.Decode == nil {
+		panic("imaging: RegisterFormat decoder is nil")
+	}
+	if _, dup := formats[format.MimeType]; dup {
+		panic("imaging: RegisterFormat called twice for " + format.MimeType)
+	}
+	formats[format.MimeType] = format
+}
+
+// LookupFormat は登録済みの画像形式を返します
+func LookupFormat(mimeType string) (Format, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	format, ok := formats[mimeType]
+	return format, ok
+}
+
+// Formats は登録済みの画像形式の MIME タイプを返します
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectType は画像データの先頭から MIME タイプを判定します
+// http.DetectContentType が判定できない HEIC/HEIF も判定します
+func DetectType(data []byte) string {
+	if isHEIF(data) {
+		return MimeTypeHEIC
+	}
+	return http.DetectContentType(data)
+}
+
+// heifBrands は HEIC/HEIF のファイルの ftyp ボックスに記録されるブランドです
+var heifBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"hevc": true, "hevx": true, "mif1": true, "msf1": true,
+}
+
+// isHEIF は画像データが HEIC/HEIF（ISO BMFF の ftyp ボックスで始まる）かを返します
+func isHEIF(data []byte) bool {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	return heifBrands[string(data[8:12])]
+}
+
+// Normalize は画像を Web で扱いやすい形式（JPEG または PNG）に変換し、変換後のデータと MIME タイプを返します
+// JPEG と PNG はそのまま返します。それ以外の形式は、透過を含む場合は PNG、含まない場合は JPEG に変換します
+func Normalize(data []byte, mimeType string) ([]byte, string, error) {
+	if mimeType == MimeTypeJPEG || mimeType == MimeTypePNG {
+		return data, mimeType, nil
+	}
+	format, ok := LookupFormat(mimeType)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, mimeType)
+	}
+	img, err := format.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("画像のデコードに失敗: %s: %w", mimeType, err)
+	}
+
+	var buf bytes.Buffer
+	if opaque(img) {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: normalizedJPEGQuality}); err != nil {
+			return nil, "", fmt.Errorf("JPEG へのエンコードに失敗: %w", err)
+		}
+		return buf.Bytes(), MimeTypeJPEG, nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", fmt.Errorf("PNG へのエンコードに失敗: %w", err)
+	}
+	return buf.Bytes(), MimeTypePNG, nil
+}
+
+// opaque は画像が透過を含まないかを返します
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
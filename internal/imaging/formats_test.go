@@ -0,0 +1,187 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"testing"
+)
+
+// encodeGIF は2色の GIF 画像を作成します（transparent が true の場合は片方の色を透明にします）
+func encodeGIF(t *testing.T, transparent bool) []byte {
+	t.Helper()
+	palette := color.Palette{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	if transparent {
+		palette[1] = color.RGBA{}
+	}
+	img := image.NewPaletted(image.Rect(0, 0, 8, 8), palette)
+	for x := 0; x < 4; x++ {
+		img.SetColorIndex(x, 0, 1)
+	}
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("gif.Encode failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// readFormatFixture は形式ごとのフィクスチャを読み込みます
+func readFormatFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../../fixture/formats/" + name)
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+	return data
+}
+
+func TestDetectType(t *testing.T) {
+	pngData, err := os.ReadFile("../../fixture/generated/fake0.png")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "PNG", data: pngData, want: MimeTypePNG},
+		{name: "GIF", data: encodeGIF(t, false), want: MimeTypeGIF},
+		{name: "WebP", data: readFormatFixture(t, "opaque.webp"), want: MimeTypeWebP},
+		{name: "HEIC のフィクスチャ", data: readFormatFixture(t, "sample.heic"), want: MimeTypeHEIC},
+		{name: "HEIC", data: []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic"), want: MimeTypeHEIC},
+		{name: "HEIF（mif1）", data: []byte("\x00\x00\x00\x18ftypmif1\x00\x00\x00\x00mif1heic"), want: MimeTypeHEIC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectType(tt.data); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	// 同じ ftyp ボックスを持つ動画は HEIC と判定しない
+	if got := DetectType([]byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isomiso2")); got == MimeTypeHEIC {
+		t.Errorf("expected MP4 not to be detected as HEIC")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	pngData, err := os.ReadFile("../../fixture/generated/fake0.png")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+
+	t.Run("PNG はそのまま", func(t *testing.T) {
+		data, mimeType, err := Normalize(pngData, MimeTypePNG)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if mimeType != MimeTypePNG || !bytes.Equal(data, pngData) {
+			t.Errorf("expected PNG to be returned as is, got %s (%d bytes)", mimeType, len(data))
+		}
+	})
+
+	t.Run("不透明な GIF は JPEG", func(t *testing.T) {
+		data, mimeType, err := Normalize(encodeGIF(t, false), MimeTypeGIF)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if mimeType != MimeTypeJPEG {
+			t.Fatalf("expected %s, got %s", MimeTypeJPEG, mimeType)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("JPEG としてデコードできません: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 8 {
+			t.Errorf("expected 8x8, got %dx%d", b.Dx(), b.Dy())
+		}
+	})
+
+	t.Run("透過を含む GIF は PNG", func(t *testing.T) {
+		data, mimeType, err := Normalize(encodeGIF(t, true), MimeTypeGIF)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if mimeType != MimeTypePNG {
+			t.Fatalf("expected %s, got %s", MimeTypePNG, mimeType)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("PNG としてデコードできません: %v", err)
+		}
+		if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+			t.Errorf("expected transparent pixel to be kept, got alpha=%d", a)
+		}
+	})
+
+	t.Run("不透明な WebP は JPEG", func(t *testing.T) {
+		data, mimeType, err := Normalize(readFormatFixture(t, "opaque.webp"), MimeTypeWebP)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if mimeType != MimeTypeJPEG {
+			t.Fatalf("expected %s, got %s", MimeTypeJPEG, mimeType)
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+			t.Errorf("JPEG としてデコードできません: %v", err)
+		}
+	})
+
+	t.Run("透過を含む WebP は PNG", func(t *testing.T) {
+		data, mimeType, err := Normalize(readFormatFixture(t, "alpha.webp"), MimeTypeWebP)
+		if err != nil {
+			t.Fatalf("Normalize failed: %v", err)
+		}
+		if mimeType != MimeTypePNG {
+			t.Fatalf("expected %s, got %s", MimeTypePNG, mimeType)
+		}
+		if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+			t.Errorf("PNG としてデコードできません: %v", err)
+		}
+	})
+
+	t.Run("デコーダーのない形式", func(t *testing.T) {
+		_, _, err := Normalize([]byte("\x00\x00\x00\x1cftypavif"), "image/avif")
+		if !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+		}
+	})
+
+	t.Run("壊れた画像", func(t *testing.T) {
+		if _, _, err := Normalize([]byte("GIF89a broken"), MimeTypeGIF); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestRegisterFormat(t *testing.T) {
+	const mimeType = "image/x-test-solid"
+	RegisterFormat(Format{
+		MimeType:   mimeType,
+		Extensions: []string{".solid"},
+		Decode: func(r io.Reader) (image.Image, error) {
+			return image.NewUniform(color.Black), nil
+		},
+	})
+
+	if _, ok := LookupFormat(mimeType); !ok {
+		t.Fatalf("registered format %s not found", mimeType)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic for duplicate registration")
+			}
+		}()
+		RegisterFormat(Format{MimeType: mimeType, Decode: gif.Decode})
+	}()
+}
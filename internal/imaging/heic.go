@@ -0,0 +1,81 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// DefaultHEICConverter は HEIC の画像を PNG に変換する libheif のコマンドです
+const DefaultHEICConverter = "heif-convert"
+
+// heicConvertTimeout は1枚の画像の変換にかける時間の上限です
+const heicConvertTimeout = 30 * time.Second
+
+// RegisterHEICConverter は libheif の変換コマンド（heif-convert）を使う HEIC のデコーダーを登録します
+// command が空の場合は DefaultHEICConverter を PATH から探します
+// コマンドが見つからない場合はエラーを返し、HEIC は登録しません（登録済みの場合は何もしません）
+func RegisterHEICConverter(command string) error {
+	if command == "" {
+		command = DefaultHEICConverter
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return fmt.Errorf("HEIC の変換コマンドが見つかりません: %w", err)
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if _, registered := formats[MimeTypeHEIC]; !registered {
+		formats[MimeTypeHEIC] = Format{MimeType: MimeTypeHEIC, Extensions: []string{".heic", ".heif"}, Decode: heicConverter(path), DecodeConfig: heifDecodeConfig}
+	}
+	return nil
+}
+
+// heicConverter は command で HEIC の画像を一時ファイルの PNG に変換してデコードするデコーダーを返します
+func heicConverter(command string) func(r io.Reader) (image.Image, error) {
+	return func(r io.Reader) (image.Image, error) {
+		dir, err := os.MkdirTemp("", "heic")
+		if err != nil {
+			return nil, fmt.Errorf("一時ディレクトリの作成に失敗: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		input := filepath.Join(dir, "input.heic")
+		output := filepath.Join(dir, "output.png")
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("画像の読み込みに失敗: %w", err)
+		}
+		if err := os.WriteFile(input, data, 0o600); err != nil {
+			return nil, fmt.Errorf("一時ファイルの書き込みに失敗: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), heicConvertTimeout)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, command, input, output).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("HEIC の変換に失敗: %w: %s", err, bytes.TrimSpace(out))
+		}
+
+		converted, err := os.ReadFile(output)
+		if err != nil {
+			return nil, fmt.Errorf("変換した画像の読み込みに失敗: %w", err)
+		}
+		// ispe ボックスの大きさと変換後の大きさが異なる場合に備えて、デコードの前に確かめ直す
+		config, err := png.DecodeConfig(bytes.NewReader(converted))
+		if err != nil {
+			return nil, fmt.Errorf("変換した画像の読み込みに失敗: %w", err)
+		}
+		if err := checkSize(config.Width, config.Height); err != nil {
+			return nil, err
+		}
+		return png.Decode(bytes.NewReader(converted))
+	}
+}
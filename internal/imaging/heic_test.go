@@ -0,0 +1,91 @@
+package imaging
+
+import (
+	"bytes"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// writeFakeConverter は heif-convert の代わりに、入力が HEIC のフィクスチャであることを確かめて PNG のフィクスチャを出力するスクリプトを作成します
+func writeFakeConverter(t *testing.T) string {
+	t.Helper()
+	heic, err := filepath.Abs("../../fixture/formats/sample.heic")
+	if err != nil {
+		t.Fatalf("フィクスチャのパスの取得に失敗: %v", err)
+	}
+	pngPath, err := filepath.Abs("../../fixture/generated/fake0.png")
+	if err != nil {
+		t.Fatalf("フィクスチャのパスの取得に失敗: %v", err)
+	}
+	script := filepath.Join(t.TempDir(), "fake-heif-convert")
+	content := "#!/bin/sh\ncmp -s \"$1\" '" + heic + "' || exit 1\ncp '" + pngPath + "' \"$2\"\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil {
+		t.Fatalf("スクリプトの作成に失敗: %v", err)
+	}
+	return script
+}
+
+func TestRegisterHEICConverter(t *testing.T) {
+	if err := RegisterHEICConverter("no-such-heif-convert"); err == nil {
+		t.Fatal("expected error for missing command, got nil")
+	}
+	if _, ok := LookupFormat(MimeTypeHEIC); ok {
+		t.Fatal("HEIC should not be registered without a converter")
+	}
+
+	if err := RegisterHEICConverter(writeFakeConverter(t)); err != nil {
+		t.Fatalf("RegisterHEICConverter failed: %v", err)
+	}
+	format, ok := LookupFormat(MimeTypeHEIC)
+	if !ok {
+		t.Fatal("HEIC is not registered")
+	}
+	if len(format.Extensions) != 2 || format.Extensions[0] != ".heic" || format.Extensions[1] != ".heif" {
+		t.Errorf("unexpected extensions: %v", format.Extensions)
+	}
+	// 登録済みの場合は何もしない
+	if err := RegisterHEICConverter(writeFakeConverter(t)); err != nil {
+		t.Errorf("second RegisterHEICConverter failed: %v", err)
+	}
+
+	// 変換した画像は JPEG または PNG に正規化される
+	data, mimeType, err := Normalize(readFormatFixture(t, "sample.heic"), MimeTypeHEIC)
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	switch mimeType {
+	case MimeTypeJPEG:
+		_, err = jpeg.Decode(bytes.NewReader(data))
+	case MimeTypePNG:
+		_, err = png.Decode(bytes.NewReader(data))
+	default:
+		t.Fatalf("unexpected MIME type: %s", mimeType)
+	}
+	if err != nil {
+		t.Errorf("正規化した画像をデコードできません: %v", err)
+	}
+
+	// 変換に失敗した場合はエラーを返す
+	if _, _, err := Normalize([]byte("\x00\x00\x00\x18ftypheic broken"), MimeTypeHEIC); err == nil {
+		t.Error("expected error for broken HEIC, got nil")
+	}
+}
+
+func TestHEICConverter_Libheif(t *testing.T) {
+	command, err := exec.LookPath(DefaultHEICConverter)
+	if err != nil {
+		t.Skipf("%s がインストールされていません", DefaultHEICConverter)
+	}
+
+	img, err := heicConverter(command)(bytes.NewReader(readFormatFixture(t, "sample.heic")))
+	if err != nil {
+		t.Fatalf("HEIC の変換に失敗: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("unexpected bounds: %v", b)
+	}
+}
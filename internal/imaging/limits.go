@@ -0,0 +1,75 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+)
+
+const (
+	// MaxImageSide は受け付ける画像の幅と高さの上限（ピクセル）です
+	MaxImageSide = 8192
+	// MaxImagePixels は受け付ける画像の画素数の上限です
+	// デコードすると1画素あたり最大4バイトを確保するため、1枚の画像で使うメモリを抑えます
+	MaxImagePixels = 40_000_000
+)
+
+// ErrImageTooLarge は画像の幅・高さ・画素数が上限を超えていることを表します
+var ErrImageTooLarge = errors.New("画像の大きさが上限を超えています")
+
+// CheckDimensions は画像全体をデコードせずにヘッダーから大きさを読み取り、上限を超えていないかを確かめます
+// 圧縮率の高い画像はファイルサイズの上限に収まっても、デコードで大量のメモリを確保するため、デコードの前に呼び出します
+func CheckDimensions(data []byte, mimeType string) error {
+	format, ok := LookupFormat(mimeType)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, mimeType)
+	}
+	if format.DecodeConfig == nil {
+		return fmt.Errorf("画像の大きさを確認できない形式です: %s", mimeType)
+	}
+	config, err := format.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("画像のヘッダーの読み込みに失敗: %s: %w", mimeType, err)
+	}
+	return checkSize(config.Width, config.Height)
+}
+
+// checkSize は幅と高さが上限を超えていないかを確かめます
+func checkSize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("画像の大きさが不正です: %dx%d", width, height)
+	}
+	if width > MaxImageSide || height > MaxImageSide || int64(width)*int64(height) > MaxImagePixels {
+		return fmt.Errorf("%w: %dx%d (幅と高さは%d以下、画素数は%d以下)", ErrImageTooLarge, width, height, MaxImageSide, MaxImagePixels)
+	}
+	return nil
+}
+
+// heifDecodeConfig は HEIC/HEIF の ispe ボックス（画像の大きさのプロパティ）から画像の大きさを読み取ります
+// 1つのファイルに複数の画像（主画像、サムネイル、タイル）が含まれるため、幅と高さそれぞれの最大値を返します
+func heifDecodeConfig(r io.Reader) (image.Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	var config image.Config
+	// ispe ボックスは 種類(4) の後に バージョンとフラグ(4)、幅(4)、高さ(4) が続きます
+	for rest := data; ; {
+		i := bytes.Index(rest, []byte("ispe"))
+		if i < 0 || i+16 > len(rest) {
+			break
+		}
+		width := int(binary.BigEndian.Uint32(rest[i+8:]))
+		height := int(binary.BigEndian.Uint32(rest[i+12:]))
+		config.Width = max(config.Width, width)
+		config.Height = max(config.Height, height)
+		rest = rest[i+4:]
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return image.Config{}, errors.New("HEIC の画像の大きさが見つかりません")
+	}
+	return config, nil
+}
@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// pngHeader は指定した大きさを宣言するだけの PNG のヘッダー（シグネチャと IHDR チャンク）を作成します
+// 画素のデータを含まないため数十バイトですが、デコードすると width*height*4 バイトを確保します
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8] = 8 // ビット深度
+	ihdr[9] = 6 // RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+// heifHeader は指定した大きさの ispe ボックスを含む HEIC のデータを作成します
+func heifHeader(width, height uint32) []byte {
+	data := []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic")
+	ispe := make([]byte, 20)
+	binary.BigEndian.PutUint32(ispe[0:], 20)
+	copy(ispe[4:], "ispe")
+	binary.BigEndian.PutUint32(ispe[12:], width)
+	binary.BigEndian.PutUint32(ispe[16:], height)
+	return append(data, ispe...)
+}
+
+func TestCheckDimensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		mimeType   string
+		wantErr    bool
+		wantTooBig bool
+	}{
+		{name: "小さい PNG", data: pngHeader(640, 480), mimeType: MimeTypePNG},
+		{name: "上限ちょうどの辺", data: pngHeader(MaxImageSide, 100), mimeType: MimeTypePNG},
+		{name: "30000x30000 の PNG", data: pngHeader(30000, 30000), mimeType: MimeTypePNG, wantErr: true, wantTooBig: true},
+		{name: "辺は上限内でも画素数が多すぎる PNG", data: pngHeader(8000, 8000), mimeType: MimeTypePNG, wantErr: true, wantTooBig: true},
+		{name: "幅だけが長すぎる PNG", data: pngHeader(MaxImageSide+1, 1), mimeType: MimeTypePNG, wantErr: true, wantTooBig: true},
+		{name: "65535x65535 の GIF", data: []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00"), mimeType: MimeTypeGIF, wantErr: true, wantTooBig: true},
+		{name: "WebP のフィクスチャ", data: readFormatFixture(t, "opaque.webp"), mimeType: MimeTypeWebP},
+		{name: "壊れたヘッダー", data: []byte("\x89PNG\r\n\x1a\n"), mimeType: MimeTypePNG, wantErr: true},
+		{name: "未登録の形式", data: []byte("data"), mimeType: "image/avif", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckDimensions(tt.data, tt.mimeType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckDimensions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantTooBig && !errors.Is(err, ErrImageTooLarge) {
+				t.Errorf("expected ErrImageTooLarge, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHEIFDecodeConfig(t *testing.T) {
+	config, err := heifDecodeConfig(bytes.NewReader(readFormatFixture(t, "sample.heic")))
+	if err != nil {
+		t.Fatalf("heifDecodeConfig failed: %v", err)
+	}
+	if config.Width != 512 || config.Height != 512 {
+		t.Errorf("want 512x512, got %dx%d", config.Width, config.Height)
+	}
+
+	// 主画像とサムネイルのように複数の ispe ボックスがある場合は大きい方を使う
+	data := append(heifHeader(320, 240), heifHeader(40000, 30000)[24:]...)
+	config, err = heifDecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("heifDecodeConfig failed: %v", err)
+	}
+	if config.Width != 40000 || config.Height != 30000 {
+		t.Errorf("want 40000x30000, got %dx%d", config.Width, config.Height)
+	}
+	if err := checkSize(config.Width, config.Height); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+
+	if _, err := heifDecodeConfig(bytes.NewReader([]byte("\x00\x00\x00\x18ftypheic"))); err == nil {
+		t.Error("expected error for HEIC without ispe, got nil")
+	}
+}
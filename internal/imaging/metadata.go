@@ -0,0 +1,279 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// errMalformed は画像のバイナリ構造が壊れていることを表します
+var errMalformed = errors.New("画像の構造が不正です")
+
+// StripMetadata は画像から撮影位置（GPS）などを含むメタデータを取り除きます
+// EXIF の向き（Orientation）が指定されている場合は、画素を回転・反転して正しい向きにしたうえで再エンコードします
+// 向きの指定がない場合は画素データを再エンコードせず、メタデータのセグメント（チャンク）だけを取り除きます
+// Normalize の後に呼び出す前提のため、JPEG と PNG のみに対応します
+func StripMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch mimeType {
+	case MimeTypeJPEG:
+		return stripJPEG(data)
+	case MimeTypePNG:
+		return stripPNG(data)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, mimeType)
+	}
+}
+
+// JPEG のマーカー
+const (
+	markerSOI  = 0xD8
+	markerEOI  = 0xD9
+	markerSOS  = 0xDA
+	markerAPP0 = 0xE0
+	markerAPP1 = 0xE1
+	// markerAPP14 は Adobe のセグメントで、CMYK などの色空間の判定に必要なため残します
+	markerAPP14 = 0xEE
+	markerAPP15 = 0xEF
+	markerCOM   = 0xFE
+)
+
+// exifHeader は APP1 セグメントのうち EXIF を格納するものの先頭です
+var exifHeader = []byte("Exif\x00\x00")
+
+// stripJPEG は JPEG から APP1〜APP13, APP15（EXIF/XMP/ICC/IPTC など）とコメントを取り除きます
+// EOI の後ろに付け足されたデータ（MPF の副画像など）も取り除きます
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, fmt.Errorf("%w: JPEG の SOI がありません", errMalformed)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, markerSOI)
+	orientation := 1
+	pos := 2
+	for {
+		if pos+2 > len(data) || data[pos] != 0xFF {
+			return nil, fmt.Errorf("%w: JPEG のマーカーが見つかりません: offset=%d", errMalformed, pos)
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// マーカーの前の埋め草
+			pos++
+			continue
+		}
+		if marker == markerEOI {
+			out = append(out, 0xFF, markerEOI)
+			break
+		}
+		if pos+4 > len(data) {
+			return nil, fmt.Errorf("%w: JPEG のセグメントが途中で終わっています", errMalformed)
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) || end < pos+4 {
+			return nil, fmt.Errorf("%w: JPEG のセグメントの長さが不正です: offset=%d", errMalformed, pos)
+		}
+
+		if marker == markerAPP1 && bytes.HasPrefix(data[pos+4:end], exifHeader) {
+			if o, ok := tiffOrientation(data[pos+4+len(exifHeader) : end]); ok {
+				orientation = o
+			}
+		}
+		if isJPEGMetadata(marker) {
+			pos = end
+			continue
+		}
+		out = append(out, data[pos:end]...)
+		pos = end
+
+		if marker == markerSOS {
+			// 圧縮データは次のマーカー（RSTn とバイトスタッフィングを除く）まで続く
+			scanEnd := pos
+			for scanEnd+1 < len(data) {
+				if data[scanEnd] == 0xFF {
+					next := data[scanEnd+1]
+					if next != 0x00 && (next < 0xD0 || next > 0xD7) {
+						break
+					}
+				}
+				scanEnd++
+			}
+			if scanEnd+1 >= len(data) {
+				return nil, fmt.Errorf("%w: JPEG の EOI がありません", errMalformed)
+			}
+			out = append(out, data[pos:scanEnd]...)
+			pos = scanEnd
+		}
+	}
+
+	if orientation == 1 {
+		return out, nil
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("画像のデコードに失敗: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, orient(img, orientation), &jpeg.Options{Quality: normalizedJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("JPEG へのエンコードに失敗: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// isJPEGMetadata は取り除く対象のセグメントかを返します
+// APP0（JFIF）と APP14（Adobe）は画素の解釈に必要なため残します
+func isJPEGMetadata(marker byte) bool {
+	if marker == markerCOM {
+		return true
+	}
+	return marker > markerAPP0 && marker <= markerAPP15 && marker != markerAPP14
+}
+
+// pngSignature は PNG ファイルの先頭8バイトです
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngKeepChunks は PNG から取り除かずに残す補助チャンクです
+// テキスト（tEXt/zTXt/iTXt）、EXIF（eXIf）、ICC プロファイル（iCCP）、更新日時（tIME）や
+// 未知のチャンクは撮影や編集の情報を含みうるため、表示に必要なものだけを許可します
+var pngKeepChunks = map[string]bool{
+	"tRNS": true, "gAMA": true, "cHRM": true, "sRGB": true, "sBIT": true,
+	"bKGD": true, "pHYs": true, "hIST": true,
+	// APNG のフレーム
+	"acTL": true, "fcTL": true, "fdAT": true,
+}
+
+// stripPNG は PNG から画素の表示に不要な補助チャンクを取り除きます
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("%w: PNG のシグネチャがありません", errMalformed)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	orientation := 1
+	pos := len(pngSignature)
+	for {
+		// 長さ(4) + 種類(4) + データ + CRC(4)
+		if pos+8 > len(data) {
+			return nil, fmt.Errorf("%w: PNG の IEND がありません", errMalformed)
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+		end := pos + 12 + length
+		if length < 0 || end > len(data) || end < pos {
+			return nil, fmt.Errorf("%w: PNG のチャンクの長さが不正です: offset=%d", errMalformed, pos)
+		}
+
+		if chunkType == "eXIf" {
+			if o, ok := tiffOrientation(data[pos+8 : pos+8+length]); ok {
+				orientation = o
+			}
+		}
+		// 先頭が大文字のチャンクは必須チャンク（IHDR/PLTE/IDAT/IEND）
+		if chunkType[0] >= 'A' && chunkType[0] <= 'Z' || pngKeepChunks[chunkType] {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	if orientation == 1 {
+		return out, nil
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("画像のデコードに失敗: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, orient(img, orientation)); err != nil {
+		return nil, fmt.Errorf("PNG へのエンコードに失敗: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// tagOrientation は EXIF の向きのタグです
+const tagOrientation = 0x0112
+
+// tiffOrientation は TIFF 形式の EXIF データの IFD0 から向き（1〜8）を読み取ります
+func tiffOrientation(tiff []byte) (int, bool) {
+	if len(tiff) < 8 {
+		return 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		// 向きは SHORT(3) 型の値が1つで、値の欄に直接格納される
+		if order.Uint16(tiff[entry:]) != tagOrientation || order.Uint16(tiff[entry+2:]) != 3 {
+			continue
+		}
+		o := int(order.Uint16(tiff[entry+8:]))
+		if o < 1 || o > 8 {
+			return 0, false
+		}
+		return o, true
+	}
+	return 0, false
+}
+
+// orient は EXIF の向き（2〜8）に従って画像を回転・反転し、正しい向きにした画像を返します
+func orient(src image.Image, orientation int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	in := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(in, in.Bounds(), src, b.Min, draw.Src)
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		// 5〜8 は90度回転を伴うため縦横が入れ替わる
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 左右反転
+				dx, dy = w-1-x, y
+			case 3: // 180度回転
+				dx, dy = w-1-x, h-1-y
+			case 4: // 上下反転
+				dx, dy = x, h-1-y
+			case 5: // 左上と右下を結ぶ対角線で反転
+				dx, dy = y, x
+			case 6: // 時計回りに90度回転
+				dx, dy = h-1-y, x
+			case 7: // 右上と左下を結ぶ対角線で反転
+				dx, dy = h-1-y, w-1-x
+			case 8: // 反時計回りに90度回転
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], in.Pix[y*in.Stride+x*4:y*in.Stride+x*4+4])
+		}
+	}
+	return dst
+}
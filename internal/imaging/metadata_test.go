@@ -0,0 +1,216 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// フィクスチャ（fixture/exif）は左半分が赤、右半分が青の 64x32 の画像に、
+// GPS を含む EXIF・XMP・ICC プロファイル・コメントを付けたものです
+// *_rotated.jpg は向きが 6（時計回りに90度）、*_rotated.png は向きが 8（反時計回りに90度）です
+
+// metadataMarkers は取り除かれているべきメタデータの断片です
+var metadataMarkers = []string{
+	"Exif",
+	"eXIf",
+	"http://ns.adobe.com/xap/1.0/",
+	"XML:com.adobe.xmp",
+	"GPSLatitude",
+	"ICC_PROFILE",
+	"iCCP",
+	"Example Camera",
+	"35.6581N",
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../../fixture/exif/" + name)
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+	return data
+}
+
+func assertNoMetadata(t *testing.T, data []byte) {
+	t.Helper()
+	for _, marker := range metadataMarkers {
+		if bytes.Contains(data, []byte(marker)) {
+			t.Errorf("expected %q to be stripped", marker)
+		}
+	}
+}
+
+// isRed は画素が赤に近いかを返します
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r>>8 > 150 && g>>8 < 100 && b>>8 < 100
+}
+
+// isBlue は画素が青に近いかを返します
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r>>8 < 100 && g>>8 < 100 && b>>8 > 150
+}
+
+func TestStripMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		mimeType string
+		// 向きを適用した後の大きさと、赤と青の画素の位置
+		width, height int
+		red, blue     image.Point
+	}{
+		{
+			name: "JPEG", fixture: "gps.jpg", mimeType: MimeTypeJPEG,
+			width: 64, height: 32, red: image.Pt(8, 16), blue: image.Pt(56, 16),
+		},
+		{
+			// 時計回りに90度回転すると、左半分（赤）が上になる
+			name: "JPEG（向き 6）", fixture: "gps_rotated.jpg", mimeType: MimeTypeJPEG,
+			width: 32, height: 64, red: image.Pt(16, 8), blue: image.Pt(16, 56),
+		},
+		{
+			name: "PNG", fixture: "gps.png", mimeType: MimeTypePNG,
+			width: 64, height: 32, red: image.Pt(8, 16), blue: image.Pt(56, 16),
+		},
+		{
+			// 反時計回りに90度回転すると、左半分（赤）が下になる
+			name: "PNG（向き 8）", fixture: "gps_rotated.png", mimeType: MimeTypePNG,
+			width: 32, height: 64, red: image.Pt(16, 56), blue: image.Pt(16, 8),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readFixture(t, tt.fixture)
+			if !bytes.Contains(data, []byte("GPSLatitude")) {
+				t.Fatal("フィクスチャに GPS の情報が含まれていません")
+			}
+
+			stripped, err := StripMetadata(data, tt.mimeType)
+			if err != nil {
+				t.Fatalf("StripMetadata failed: %v", err)
+			}
+			assertNoMetadata(t, stripped)
+			if got := DetectType(stripped); got != tt.mimeType {
+				t.Errorf("expected %s, got %s", tt.mimeType, got)
+			}
+
+			img, _, err := image.Decode(bytes.NewReader(stripped))
+			if err != nil {
+				t.Fatalf("デコードできません: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Fatalf("expected %dx%d, got %dx%d", tt.width, tt.height, b.Dx(), b.Dy())
+			}
+			if c := img.At(tt.red.X, tt.red.Y); !isRed(c) {
+				t.Errorf("expected red at %v, got %v", tt.red, c)
+			}
+			if c := img.At(tt.blue.X, tt.blue.Y); !isBlue(c) {
+				t.Errorf("expected blue at %v, got %v", tt.blue, c)
+			}
+		})
+	}
+}
+
+func TestStripMetadata_KeepsPixelsWithoutOrientation(t *testing.T) {
+	// 向きの指定がない場合は再エンコードせず、画素はまったく変わらない
+	data := readFixture(t, "gps.jpg")
+	stripped, err := StripMetadata(data, MimeTypeJPEG)
+	if err != nil {
+		t.Fatalf("StripMetadata failed: %v", err)
+	}
+	before, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("jpeg.Decode failed: %v", err)
+	}
+	after, err := jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("jpeg.Decode failed: %v", err)
+	}
+	b := before.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if before.At(x, y) != after.At(x, y) {
+				t.Fatalf("pixel (%d, %d) changed: %v -> %v", x, y, before.At(x, y), after.At(x, y))
+			}
+		}
+	}
+
+	// メタデータのない画像はそのままの内容になる
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, before); err != nil {
+		t.Fatalf("png.Encode failed: %v", err)
+	}
+	stripped, err = StripMetadata(buf.Bytes(), MimeTypePNG)
+	if err != nil {
+		t.Fatalf("StripMetadata failed: %v", err)
+	}
+	if !bytes.Equal(stripped, buf.Bytes()) {
+		t.Error("expected PNG without metadata to be unchanged")
+	}
+}
+
+func TestStripMetadata_Invalid(t *testing.T) {
+	jpegData := readFixture(t, "gps.jpg")
+	pngData := readFixture(t, "gps.png")
+
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+	}{
+		{name: "JPEG ではない", data: []byte("not an image"), mimeType: MimeTypeJPEG},
+		{name: "途中で終わる JPEG", data: jpegData[:len(jpegData)/2], mimeType: MimeTypeJPEG},
+		{name: "PNG ではない", data: []byte("not an image"), mimeType: MimeTypePNG},
+		{name: "途中で終わる PNG", data: pngData[:len(pngData)/2], mimeType: MimeTypePNG},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := StripMetadata(tt.data, tt.mimeType); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := StripMetadata(encodeGIF(t, false), MimeTypeGIF); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2x1 の画像（左が赤、右が青）を各向きで変換したときの、赤の画素の位置
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.NRGBA{255, 0, 0, 255})
+	src.Set(1, 0, color.NRGBA{0, 0, 255, 255})
+
+	tests := []struct {
+		orientation int
+		width       int
+		red         image.Point
+	}{
+		{orientation: 2, width: 2, red: image.Pt(1, 0)},
+		{orientation: 3, width: 2, red: image.Pt(1, 0)},
+		{orientation: 4, width: 2, red: image.Pt(0, 0)},
+		{orientation: 5, width: 1, red: image.Pt(0, 0)},
+		{orientation: 6, width: 1, red: image.Pt(0, 0)},
+		{orientation: 7, width: 1, red: image.Pt(0, 1)},
+		{orientation: 8, width: 1, red: image.Pt(0, 1)},
+	}
+	for _, tt := range tests {
+		img := orient(src, tt.orientation)
+		if img.Bounds().Dx() != tt.width {
+			t.Errorf("orientation %d: expected width %d, got %d", tt.orientation, tt.width, img.Bounds().Dx())
+			continue
+		}
+		if c := img.At(tt.red.X, tt.red.Y); !isRed(c) {
+			t.Errorf("orientation %d: expected red at %v, got %v", tt.orientation, tt.red, c)
+		}
+	}
+}
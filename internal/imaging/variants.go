@@ -0,0 +1,107 @@
+// Package imaging はアップロードされた画像の加工を行います
+// JPEG/PNG/GIF は標準ライブラリ、WebP は golang.org/x/image/webp のデコーダーで読み込みます（いずれも cgo は不要です）
+// HEIC は RegisterHEICConverter で登録した場合だけ、外部の heif-convert コマンド（libheif）で変換して読み込みます
+// その他の形式は RegisterFormat でデコーダーを登録すると受け付けられるようになります
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	// PNG のデコーダーを登録
+	_ "image/png"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+// jpegQuality は派生画像を JPEG にエンコードする際の品質です
+const jpegQuality = 85
+
+// variantSizes は派生画像ごとの長辺の最大ピクセル数です
+// 元の画像（models.ImageFull）はそのまま保存するため含みません
+var variantSizes = map[models.ImageVariant]int{
+	models.ImageThumbnail: 320,
+	models.ImageMedium:    1024,
+}
+
+// GenerateVariants は画像を縮小したサイズ別の派生画像を JPEG で返します
+// 元の画像が派生画像のサイズより小さい場合は拡大せず、元の大きさのまま再エンコードします
+func GenerateVariants(data []byte) (map[models.ImageVariant][]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("画像のデコードに失敗: %w", err)
+	}
+	// JPEG は透過を扱えないため、先に白い背景と合成しておく
+	flat := flatten(src)
+
+	variants := make(map[models.ImageVariant][]byte, len(variantSizes))
+	for variant, size := range variantSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, Resize(flat, size), &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("派生画像のエンコードに失敗: variant=%s: %w", variant, err)
+		}
+		variants[variant] = buf.Bytes()
+	}
+	return variants, nil
+}
+
+// flatten は画像を白い背景と合成した RGBA 画像に変換します
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// Resize は長辺が maxSize 以下になるよう画像を縮小します
+// 縮小先の1ピクセルに対応する元の画像の領域を平均する（面積平均法）ため、縮小してもちらつきが出にくくなります
+func Resize(src *image.RGBA, maxSize int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= maxSize && sh <= maxSize {
+		return src
+	}
+	dw, dh := maxSize, maxSize
+	if sw >= sh {
+		dh = max(1, sh*maxSize/sw)
+	} else {
+		dw = max(1, sw*maxSize/sh)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := span(dy, dh, sh)
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := span(dx, dw, sw)
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[(y-src.Rect.Min.Y)*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[(x-src.Rect.Min.X)*4:]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+			q := dst.Pix[dy*dst.Stride+dx*4:]
+			q[0], q[1], q[2], q[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// span は縮小先の i 番目のピクセルに対応する元の画像の範囲 [from, to) を返します
+func span(i, dstSize, srcSize int) (from, to int) {
+	from = i * srcSize / dstSize
+	to = (i + 1) * srcSize / dstSize
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
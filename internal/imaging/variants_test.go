@@ -0,0 +1,102 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+func TestGenerateVariants(t *testing.T) {
+	data, err := os.ReadFile("../../fixture/generated/fake0.png")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+
+	variants, err := GenerateVariants(data)
+	if err != nil {
+		t.Fatalf("GenerateVariants failed: %v", err)
+	}
+
+	// フィクスチャは 1536x1536 の正方形
+	wantSizes := map[models.ImageVariant]int{
+		models.ImageThumbnail: 320,
+		models.ImageMedium:    1024,
+	}
+	if len(variants) != len(wantSizes) {
+		t.Fatalf("expected %d variants, got %d", len(wantSizes), len(variants))
+	}
+	for variant, size := range wantSizes {
+		img, err := jpeg.Decode(bytes.NewReader(variants[variant]))
+		if err != nil {
+			t.Fatalf("%s: JPEG としてデコードできません: %v", variant, err)
+		}
+		if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+			t.Errorf("%s: expected %dx%d, got %dx%d", variant, size, size, b.Dx(), b.Dy())
+		}
+		if len(variants[variant]) >= len(data) {
+			t.Errorf("%s: expected variant to be smaller than the original (%d >= %d bytes)", variant, len(variants[variant]), len(data))
+		}
+	}
+}
+
+func TestGenerateVariants_SmallTransparentImage(t *testing.T) {
+	// 派生画像より小さい、透過を含む横長の画像
+	src := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("png.Encode failed: %v", err)
+	}
+
+	variants, err := GenerateVariants(buf.Bytes())
+	if err != nil {
+		t.Fatalf("GenerateVariants failed: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(variants[models.ImageThumbnail]))
+	if err != nil {
+		t.Fatalf("JPEG としてデコードできません: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Errorf("expected small image not to be enlarged, got %dx%d", b.Dx(), b.Dy())
+	}
+	// 透明な部分は白になる
+	if r, g, b, _ := img.At(100, 50).RGBA(); r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("expected transparent pixels to become white, got %v", img.At(100, 50))
+	}
+}
+
+func TestGenerateVariants_InvalidImage(t *testing.T) {
+	if _, err := GenerateVariants([]byte("not an image")); err == nil {
+		t.Error("expected error for invalid image, got nil")
+	}
+}
+
+func TestResize(t *testing.T) {
+	// 左半分が黒、右半分が白の縦長の画像
+	src := image.NewRGBA(image.Rect(0, 0, 40, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 40; x++ {
+			if x >= 20 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	dst := Resize(src, 20)
+	if b := dst.Bounds(); b.Dx() != 10 || b.Dy() != 20 {
+		t.Fatalf("expected 10x20, got %dx%d", b.Dx(), b.Dy())
+	}
+	if got := dst.RGBAAt(2, 10); got.R != 0 {
+		t.Errorf("expected left side to stay black, got %v", got)
+	}
+	if got := dst.RGBAAt(7, 10); got.R != 255 {
+		t.Errorf("expected right side to stay white, got %v", got)
+	}
+}
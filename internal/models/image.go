@@ -0,0 +1,74 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// ImageVariant はアップロード時に生成する画像のサイズ別の種類です
+type ImageVariant string
+
+const (
+	// ImageThumbnail は一覧のカードに表示する小さな画像です
+	ImageThumbnail ImageVariant = "thumbnail"
+	// ImageMedium はクイズの画面に表示する中くらいの画像です
+	ImageMedium ImageVariant = "medium"
+	// ImageFull はアップロードされた元の画像です
+	ImageFull ImageVariant = "full"
+)
+
+// ImageVariants はすべての画像の種類を小さい順に並べたものです
+var ImageVariants = []ImageVariant{ImageThumbnail, ImageMedium, ImageFull}
+
+// ParseImageVariant は文字列を画像の種類に変換します
+func ParseImageVariant(s string) (ImageVariant, bool) {
+	for _, v := range ImageVariants {
+		if string(v) == s {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// QuizImage はクイズの画像と、生成済みの派生画像の組を表します
+type QuizImage struct {
+	// QuizID は画像を使っているクイズのIDです（画像プロキシのURLに含めます）
+	QuizID string
+	// Path は元の画像のオブジェクトのパスです
+	Path string
+	// Variants は生成済みの派生画像の種類です
+	Variants []ImageVariant
+}
+
+// PathFor は指定された種類の画像のオブジェクトのパスを返します
+// 派生画像が生成されていない場合（派生画像の導入前のクイズなど）は元の画像のパスを返します
+func (i QuizImage) PathFor(variant ImageVariant) string {
+	for _, v := range i.Variants {
+		if v == variant {
+			return VariantPath(i.Path, variant)
+		}
+	}
+	return i.Path
+}
+
+// VariantPath は元の画像のパスから派生画像のパスを求めます
+// 派生画像は JPEG で、元の画像と同じ場所に images/<id>_<種類>.jpg として保存します
+func VariantPath(imagePath string, variant ImageVariant) string {
+	if variant == ImageFull {
+		return imagePath
+	}
+	return strings.TrimSuffix(imagePath, path.Ext(imagePath)) + "_" + string(variant) + ".jpg"
+}
+
+// ImageInfo は保存済みの画像オブジェクトの属性です
+type ImageInfo struct {
+	// Size は画像のバイト数です
+	Size int64
+	// ContentType は保存時に指定した Content-Type です（不明な場合は空）
+	ContentType string
+	// ETag は画像の内容を識別する値です（引用符を含みません）
+	ETag string
+	// UpdatedAt は画像を保存した日時です（不明な場合はゼロ値）
+	UpdatedAt time.Time
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// JobStatus はクイズ作成ジョブの状態です
+type JobStatus string
+
+const (
+	// JobPending は実行を待っている状態です
+	JobPending JobStatus = "pending"
+	// JobRunning は実行中の状態です
+	JobRunning JobStatus = "running"
+	// JobSucceeded はクイズの作成に成功した状態です
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed はクイズの作成に失敗した状態です
+	JobFailed JobStatus = "failed"
+)
+
+// Done はジョブが終了した（これ以上状態が変わらない）かを返します
+func (s JobStatus) Done() bool {
+	return s == JobSucceeded || s == JobFailed
+}
+
+// Job は非同期にクイズを作成するジョブです
+// 再起動後も実行を再開できるよう、作成の入力ごとストレージに保存します（画像は別のオブジェクト）
+type Job struct {
+	ID       string    `json:"id"`
+	Status   JobStatus `json:"status"`
+	AuthorID string    `json:"author_id"`
+	// QuizID は作成したクイズのIDです（成功した場合のみ）
+	QuizID string `json:"quiz_id,omitempty"`
+	// Error は失敗の理由です（失敗した場合のみ）
+	Error string `json:"error,omitempty"`
+	// Attempts は実行を開始した回数です
+	Attempts int `json:"attempts"`
+	// Owner は実行中のジョブを実行しているインスタンスのIDです（実行中の場合のみ）
+	Owner string `json:"owner,omitempty"`
+	// LeaseExpiresAt は実行しているインスタンスがジョブを占有する期限です（実行中の場合のみ）
+	// 実行しているインスタンスは期限が来る前に延長するため、期限を過ぎたジョブはインスタンスが停止したとみなせます
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+
+	Input     JobInput  `json:"input"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobInput はジョブで作成するクイズの入力です（画像を除く）
+type JobInput struct {
+	ImageMimeType        string   `json:"image_mime_type"`
+	AuthorInterpretation string   `json:"author_interpretation"`
+	Title                string   `json:"title,omitempty"`
+	DecoyCount           int      `json:"decoy_count,omitempty"`
+	AuthorName           string   `json:"author_name,omitempty"`
+	Tags                 []string `json:"tags,omitempty"`
+}
+
+// LeaseExpired は実行中のジョブの占有の期限が now までに切れているかを返します
+func (j *Job) LeaseExpired(now time.Time) bool {
+	return !now.Before(j.LeaseExpiresAt)
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// AnonymousReporterPrefix は未ログインのプレイヤーからの報告で、報告者のIDの先頭に付ける接頭辞です
+const AnonymousReporterPrefix = "player:"
+
+// QuizReport はプレイヤーからのクイズの報告です
+type QuizReport struct {
+	// ReporterID は報告した利用者のIDです（未ログインの場合は匿名のプレイヤーID）
+	ReporterID string    `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Anonymous は未ログインのプレイヤーからの報告かを返します
+// 匿名のプレイヤーIDはクライアントが自由に指定できるため、報告者を区別する根拠になりません
+func (r *QuizReport) Anonymous() bool {
+	return strings.HasPrefix(r.ReporterID, AnonymousReporterPrefix)
+}
+
+// PendingReports は最後に管理者が審査した後に届いた報告を返します
+func (q *Quiz) PendingReports() []QuizReport {
+	if q.ReviewedAt == nil {
+		return q.Reports
+	}
+	var pending []QuizReport
+	for _, report := range q.Reports {
+		if report.CreatedAt.After(*q.ReviewedAt) {
+			pending = append(pending, report)
+		}
+	}
+	return pending
+}
+
+// NeedsReview はクイズが管理者の確認を待っているかを返します
+// 審査で公開が保留されたクイズと、未確認の報告があるクイズが対象です
+func (q *Quiz) NeedsReview() bool {
+	return q.Status == QuizFlagged || len(q.PendingReports()) > 0
+}
+
+// AuthorBan は管理者による投稿者の利用停止です
+type AuthorBan struct {
+	AuthorID string `json:"author_id"`
+	Reason   string `json:"reason,omitempty"`
+	// BannedBy は利用停止にした管理者のIDです
+	BannedBy  string    `json:"banned_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -6,11 +6,20 @@ import (
 
 // QuizResponse はクイズのレスポンス形式を定義します
 type QuizResponse struct {
-	ID                   string `json:"id"`
-	ImageURL             string `json:"image_url"`
-	CreatedAt            string `json:"created_at"`
-	AuthorInterpretation string `json:"author_interpretation"`
-	AIInterpretation     string `json:"ai_interpretation"`
+	ID                   string   `json:"id"`
+	ImageURL             string   `json:"image_url"`
+	CreatedAt            string   `json:"created_at"`
+	AuthorInterpretation string   `json:"author_interpretation"`
+	AIInterpretation     string   `json:"ai_interpretation"`
+	AIInterpretations    []string `json:"ai_interpretations"`
+	Difficulty           string   `json:"difficulty"`
+}
+
+// QuizOption は回答者に提示する選択肢を表します
+// どれが投稿者の解釈かは含めず、回答はIDで受け付けます
+type QuizOption struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
 }
 
 // NewQuizResponse はQuizResponseを生成します
@@ -21,6 +30,8 @@ func NewQuizResponse(quiz *Quiz, imageURL string) *QuizResponse {
 		CreatedAt:            quiz.CreatedAt.Format(time.RFC3339),
 		AuthorInterpretation: quiz.AuthorInterpretation,
 		AIInterpretation:     quiz.AIInterpretation,
+		AIInterpretations:    quiz.Decoys(),
+		Difficulty:           quiz.Difficulty(),
 	}
 }
 
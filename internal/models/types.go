@@ -2,13 +2,98 @@ package models
 
 import "time"
 
+const (
+	// DifficultyEasy はおとりの解釈が1つのクイズの難易度です
+	DifficultyEasy = "easy"
+	// DifficultyNormal はおとりの解釈が2〜3つのクイズの難易度です
+	DifficultyNormal = "normal"
+	// DifficultyHard はおとりの解釈が4つ以上のクイズの難易度です
+	DifficultyHard = "hard"
+)
+
 // Quiz はクイズのデータモデルを表します
 type Quiz struct {
-	ID                   string    `json:"id"`
-	ImagePath            string    `json:"image_path"`
-	AuthorInterpretation string    `json:"author_interpretation"`
-	AIInterpretation     string    `json:"ai_interpretation"`
-	CreatedAt            time.Time `json:"created_at"`
+	ID        string `json:"id"`
+	ImagePath string `json:"image_path"`
+	// ImageMimeType は保存した元の画像の MIME タイプです（記録する前に作成したクイズでは空）
+	ImageMimeType string `json:"image_mime_type,omitempty"`
+	// ImageVariants は生成済みの派生画像の種類です
+	ImageVariants []ImageVariant `json:"image_variants,omitempty"`
+	// Title は一覧に表示するタイトルです（投稿者の解釈は答えになるため一覧には表示しません）
+	Title string `json:"title,omitempty"`
+	// AuthorID はクイズを作成した利用者のIDです（認証導入前のクイズでは空）
+	AuthorID string `json:"author_id,omitempty"`
+	// AuthorName は作成時点の投稿者の表示名です
+	AuthorName           string `json:"author_name,omitempty"`
+	AuthorInterpretation string `json:"author_interpretation"`
+	// AIInterpretation は先頭のAIの解釈です（おとりが1つだけだった旧形式との互換用）
+	AIInterpretation string `json:"ai_interpretation"`
+	// AIInterpretations はAIが生成したおとりの解釈の一覧です
+	AIInterpretations []string `json:"ai_interpretations,omitempty"`
+	// Tags は一覧の絞り込みに使用するタグです
+	Tags []string `json:"tags,omitempty"`
+	// Status は審査の状態です（審査の導入前のクイズでは空で、公開として扱います）
+	Status QuizStatus `json:"status,omitempty"`
+	// ModerationReasons は審査で公開を保留または拒否した理由です
+	ModerationReasons []string `json:"moderation_reasons,omitempty"`
+	// Reports はプレイヤーからの報告です
+	Reports []QuizReport `json:"reports,omitempty"`
+	// ReviewedBy は最後にクイズを審査した管理者のIDです
+	ReviewedBy string `json:"reviewed_by,omitempty"`
+	// ReviewedAt は最後に管理者が審査した日時です（この日時より前の報告は確認済みとして扱います）
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// QuizStatus はクイズの審査の状態です
+type QuizStatus string
+
+const (
+	// QuizApproved は公開されているクイズです
+	QuizApproved QuizStatus = "approved"
+	// QuizFlagged は審査で問題の可能性が見つかり、管理者の確認を待っているクイズです
+	QuizFlagged QuizStatus = "flagged"
+	// QuizRejected は管理者が公開を拒否したクイズです
+	QuizRejected QuizStatus = "rejected"
+)
+
+// Public はクイズを一覧や出題に含めてよいかを返します
+func (s QuizStatus) Public() bool {
+	return s == "" || s == QuizApproved
+}
+
+// Image はクイズの画像と派生画像の組を返します
+func (q *Quiz) Image() QuizImage {
+	return QuizImage{QuizID: q.ID, Path: q.ImagePath, Variants: q.ImageVariants}
+}
+
+// Decoys はAIが生成したおとりの解釈をすべて返します
+// ai_interpretations を持たない旧形式のクイズでは ai_interpretation を返します
+func (q *Quiz) Decoys() []string {
+	if len(q.AIInterpretations) > 0 {
+		return q.AIInterpretations
+	}
+	if q.AIInterpretation != "" {
+		return []string{q.AIInterpretation}
+	}
+	return nil
+}
+
+// Difficulty はおとりの解釈の数からクイズの難易度を返します
+func (q *Quiz) Difficulty() string {
+	return difficultyOf(len(q.Decoys()))
+}
+
+// difficultyOf はおとりの解釈の数に対応する難易度を返します
+func difficultyOf(decoyCount int) string {
+	switch n := decoyCount; {
+	case n >= 4:
+		return DifficultyHard
+	case n >= 2:
+		return DifficultyNormal
+	default:
+		return DifficultyEasy
+	}
 }
 
 // QuizList はクイズのリストを表します
@@ -17,7 +102,226 @@ type QuizList struct {
 }
 
 // QuizListResponse はクイズ一覧のレスポンスを表します
+// 一覧の画面で個別のクイズを取得しなくても表示できるよう、カードに必要な情報をまとめて返します
 type QuizListResponse struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	ThumbnailURL string   `json:"thumbnail_url"`
+	AuthorID     string   `json:"author_id,omitempty"`
+	AuthorName   string   `json:"author_name"`
+	Tags         []string `json:"tags,omitempty"`
+	PlayCount    int      `json:"play_count"`
+	Difficulty   string   `json:"difficulty"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+// NewQuizListResponse はクイズの概要からQuizListResponseを生成します
+func NewQuizListResponse(summary *QuizSummary, thumbnailURL string) *QuizListResponse {
+	return &QuizListResponse{
+		ID:           summary.ID,
+		Title:        summary.Title,
+		ThumbnailURL: thumbnailURL,
+		AuthorID:     summary.AuthorID,
+		AuthorName:   summary.AuthorName,
+		Tags:         summary.Tags,
+		PlayCount:    summary.PlayCount,
+		Difficulty:   summary.Difficulty(),
+		CreatedAt:    summary.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// QuizIndex はクイズ一覧を取得するためのインデックスを表します
+type QuizIndex struct {
+	Entries []*QuizIndexEntry `json:"entries"`
+}
+
+// QuizIndexEntry はインデックスに登録されたクイズ1件分の情報を表します
+// 一覧の絞り込みに使う項目は、クイズ本体を読み込まずに判定できるようインデックスにも保持します
+// 一覧に表示する項目も保持し、一覧の取得時にクイズ本体を1件ずつ読み込まずに済むようにします
+type QuizIndexEntry struct {
+	ID        string `json:"id"`
+	Title     string `json:"title,omitempty"`
+	ImagePath string `json:"image_path,omitempty"`
+	// ImageVariants は生成済みの派生画像の種類です
+	ImageVariants []ImageVariant `json:"image_variants,omitempty"`
+	AuthorID      string         `json:"author_id,omitempty"`
+	AuthorName    string         `json:"author_name,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	DecoyCount    int            `json:"decoy_count,omitempty"`
+	Status        QuizStatus     `json:"status,omitempty"`
+	// NeedsReview は管理者の確認を待っているかを表します（審査で保留された、または未確認の報告がある）
+	NeedsReview bool      `json:"needs_review,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewQuizIndexEntry はクイズからインデックスのエントリを作成します
+func NewQuizIndexEntry(quiz *Quiz) *QuizIndexEntry {
+	return &QuizIndexEntry{
+		ID:            quiz.ID,
+		Title:         quiz.Title,
+		ImagePath:     quiz.ImagePath,
+		ImageVariants: quiz.ImageVariants,
+		AuthorID:      quiz.AuthorID,
+		AuthorName:    quiz.AuthorName,
+		Tags:          quiz.Tags,
+		DecoyCount:    len(quiz.Decoys()),
+		Status:        quiz.Status,
+		NeedsReview:   quiz.NeedsReview(),
+		CreatedAt:     quiz.CreatedAt,
+	}
+}
+
+// Image はクイズの画像と派生画像の組を返します
+func (e *QuizIndexEntry) Image() QuizImage {
+	return QuizImage{QuizID: e.ID, Path: e.ImagePath, Variants: e.ImageVariants}
+}
+
+// QuizSummary は一覧に表示するクイズの概要を表します
+type QuizSummary struct {
+	QuizIndexEntry
+	// PlayCount はクイズが回答された回数です
+	PlayCount int
+}
+
+// Difficulty はおとりの解釈の数からクイズの難易度を返します
+func (s *QuizSummary) Difficulty() string {
+	return difficultyOf(s.DecoyCount)
+}
+
+// SortOrder はクイズ一覧の並び順です
+type SortOrder string
+
+const (
+	// SortNewestFirst は作成日時の新しい順です
+	SortNewestFirst SortOrder = "desc"
+	// SortOldestFirst は作成日時の古い順です
+	SortOldestFirst SortOrder = "asc"
+)
+
+// QuizListQuery はクイズ一覧の取得条件を表します
+// 空の項目では絞り込みません
+type QuizListQuery struct {
+	AuthorID string
+	Tag      string
+	// CreatedFrom 以降、CreatedTo より前に作成されたクイズに絞り込みます
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Order       SortOrder
+	// Status は審査の状態で絞り込みます（空の場合は公開されているクイズのみ）
+	Status QuizStatus
+	// IncludeHidden が true で Status が空の場合は、審査の状態で絞り込みません
+	IncludeHidden bool
+	// NeedsReview が true の場合は、管理者の確認を待っているクイズに絞り込みます
+	NeedsReview bool
+	// Limit は1ページに含める最大件数です
+	Limit int
+	// Cursor は前のページの NextCursor です（空の場合は先頭から）
+	Cursor string
+}
+
+// Matches はインデックスのエントリが絞り込み条件に一致するかを返します
+func (q *QuizListQuery) Matches(entry *QuizIndexEntry) bool {
+	switch {
+	case q.Status != "":
+		if entry.Status != q.Status {
+			return false
+		}
+	case !q.IncludeHidden:
+		if !entry.Status.Public() {
+			return false
+		}
+	}
+	if q.NeedsReview && !entry.NeedsReview {
+		return false
+	}
+	if q.AuthorID != "" && entry.AuthorID != q.AuthorID {
+		return false
+	}
+	if !q.CreatedFrom.IsZero() && entry.CreatedAt.Before(q.CreatedFrom) {
+		return false
+	}
+	if !q.CreatedTo.IsZero() && !entry.CreatedAt.Before(q.CreatedTo) {
+		return false
+	}
+	if q.Tag == "" {
+		return true
+	}
+	for _, tag := range entry.Tags {
+		if tag == q.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// QuizPage はクイズ一覧の1ページ分を表します
+type QuizPage struct {
+	Quizzes []*QuizSummary
+	// NextCursor は次のページを取得するためのカーソルです（最後のページでは空）
+	NextCursor string
+}
+
+// Answer はプレイヤーの回答1件分の記録を表します
+type Answer struct {
+	ID        string `json:"id"`
+	QuizID    string `json:"quiz_id"`
+	PlayerID  string `json:"player_id"`
+	SessionID string `json:"session_id"`
+	OptionID  string `json:"option_id"`
+	// DecoyIndex は選んだおとりの解釈の位置です（投稿者の解釈を選んだ場合は -1）
+	DecoyIndex int       `json:"decoy_index"`
+	IsCorrect  bool      `json:"is_correct"`
+	AnsweredAt time.Time `json:"answered_at"`
+}
+
+// QuizStats はクイズごとの回答の集計を表します
+// 回答のたびに書き換えるため、件数だけを持ちます（プレイヤーごとの最初の回答はストレージが別のオブジェクトに記録します）
+type QuizStats struct {
+	QuizID          string `json:"quiz_id"`
+	Attempts        int    `json:"attempts"`
+	CorrectAttempts int    `json:"correct_attempts"`
+	// DecoyPicks はおとりの解釈ごとの選ばれた回数です（ai_interpretations と同じ順序）
+	DecoyPicks []int `json:"decoy_picks"`
+	// PlayerCount は回答したプレイヤーの数です
+	PlayerCount int `json:"player_count"`
+	// FooledPlayerCount は最初の回答でおとりの解釈を選んだプレイヤーの数です
+	FooledPlayerCount int       `json:"fooled_player_count"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Record は回答を集計に加えます
+// firstAnswer はそのプレイヤーのこのクイズへの最初の回答かどうかです
+// 同じプレイヤーの2回目以降の回答は、だまされたかどうかの判定に使いません
+func (s *QuizStats) Record(answer *Answer, firstAnswer bool) {
+	s.QuizID = answer.QuizID
+	s.Attempts++
+	if answer.IsCorrect {
+		s.CorrectAttempts++
+	} else if answer.DecoyIndex >= 0 {
+		for len(s.DecoyPicks) <= answer.DecoyIndex {
+			s.DecoyPicks = append(s.DecoyPicks, 0)
+		}
+		s.DecoyPicks[answer.DecoyIndex]++
+	}
+
+	if firstAnswer {
+		s.PlayerCount++
+		if !answer.IsCorrect {
+			s.FooledPlayerCount++
+		}
+	}
+	s.UpdatedAt = answer.AnsweredAt
+}
+
+// FooledAttempts はおとりの解釈が選ばれた回答の数を返します
+func (s *QuizStats) FooledAttempts() int {
+	return s.Attempts - s.CorrectAttempts
+}
+
+// FooledRate はAIにだまされたプレイヤーの割合を返します（回答がない場合は 0）
+func (s *QuizStats) FooledRate() float64 {
+	if s.PlayerCount == 0 {
+		return 0
+	}
+	return float64(s.FooledPlayerCount) / float64(s.PlayerCount)
 }
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQuizDecoys(t *testing.T) {
+	tests := []struct {
+		name           string
+		json           string
+		wantDecoys     []string
+		wantDifficulty string
+	}{
+		{
+			name:           "旧形式：AIの解釈が1つ",
+			json:           `{"id":"q","ai_interpretation":"AIの解釈"}`,
+			wantDecoys:     []string{"AIの解釈"},
+			wantDifficulty: DifficultyEasy,
+		},
+		{
+			name:           "新形式：AIの解釈が複数",
+			json:           `{"id":"q","ai_interpretation":"AI1","ai_interpretations":["AI1","AI2","AI3"]}`,
+			wantDecoys:     []string{"AI1", "AI2", "AI3"},
+			wantDifficulty: DifficultyNormal,
+		},
+		{
+			name:           "新形式：AIの解釈が5つ",
+			json:           `{"id":"q","ai_interpretations":["AI1","AI2","AI3","AI4","AI5"]}`,
+			wantDecoys:     []string{"AI1", "AI2", "AI3", "AI4", "AI5"},
+			wantDifficulty: DifficultyHard,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var quiz Quiz
+			if err := json.Unmarshal([]byte(tt.json), &quiz); err != nil {
+				t.Fatalf("failed to unmarshal quiz: %v", err)
+			}
+
+			got := quiz.Decoys()
+			if len(got) != len(tt.wantDecoys) {
+				t.Fatalf("want %v, got %v", tt.wantDecoys, got)
+			}
+			for i := range got {
+				if got[i] != tt.wantDecoys[i] {
+					t.Errorf("want %v, got %v", tt.wantDecoys, got)
+				}
+			}
+			if d := quiz.Difficulty(); d != tt.wantDifficulty {
+				t.Errorf("want difficulty %q, got %q", tt.wantDifficulty, d)
+			}
+		})
+	}
+}
+
+func TestQuizStatsRecord(t *testing.T) {
+	var stats QuizStats
+	answers := []struct {
+		answer *Answer
+		first  bool
+	}{
+		{answer: &Answer{QuizID: "q", PlayerID: "p1", DecoyIndex: 1}, first: true},
+		{answer: &Answer{QuizID: "q", PlayerID: "p1", DecoyIndex: -1, IsCorrect: true}}, // 2回目は判定に使わない
+		{answer: &Answer{QuizID: "q", PlayerID: "p2", DecoyIndex: -1, IsCorrect: true}, first: true},
+		{answer: &Answer{QuizID: "q", PlayerID: "p3", DecoyIndex: 0}, first: true},
+		{answer: &Answer{QuizID: "q", PlayerID: "p4", DecoyIndex: 1}, first: true},
+	}
+	for _, a := range answers {
+		stats.Record(a.answer, a.first)
+	}
+
+	if stats.Attempts != 5 || stats.CorrectAttempts != 2 || stats.FooledAttempts() != 3 {
+		t.Errorf("unexpected attempts: %+v", stats)
+	}
+	if len(stats.DecoyPicks) != 2 || stats.DecoyPicks[0] != 1 || stats.DecoyPicks[1] != 2 {
+		t.Errorf("unexpected decoy picks: %v", stats.DecoyPicks)
+	}
+	if stats.PlayerCount != 4 || stats.FooledPlayerCount != 3 {
+		t.Errorf("unexpected players: %+v", stats)
+	}
+	if rate := stats.FooledRate(); rate != 0.75 {
+		t.Errorf("want fooled rate 0.75, got %v", rate)
+	}
+
+	var empty QuizStats
+	if rate := empty.FooledRate(); rate != 0 {
+		t.Errorf("want fooled rate 0 for no answers, got %v", rate)
+	}
+}
+
+func TestQuizImagePathFor(t *testing.T) {
+	withVariants := QuizImage{Path: "images/quiz_1.png", Variants: []ImageVariant{ImageThumbnail, ImageMedium}}
+	legacy := QuizImage{Path: "images/quiz_1.jpg"}
+
+	tests := []struct {
+		name    string
+		image   QuizImage
+		variant ImageVariant
+		want    string
+	}{
+		{name: "サムネイル", image: withVariants, variant: ImageThumbnail, want: "images/quiz_1_thumbnail.jpg"},
+		{name: "中サイズ", image: withVariants, variant: ImageMedium, want: "images/quiz_1_medium.jpg"},
+		{name: "元の画像", image: withVariants, variant: ImageFull, want: "images/quiz_1.png"},
+		{name: "派生画像のないクイズ", image: legacy, variant: ImageThumbnail, want: "images/quiz_1.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.image.PathFor(tt.variant); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// UploadSession は画像を2段階でアップロードするためのセッションです
+// クライアントはセッションを作成して画像をアップロードし、最後にクイズの作成を確定します
+type UploadSession struct {
+	ID string `json:"id"`
+	// AuthorID はセッションを作成したユーザーのIDです（本人以外はアップロードや確定ができません）
+	AuthorID string `json:"author_id"`
+	// Filename は元のファイル名です（拡張子の検証に使用します）
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	// Size はクライアントが申告した画像の大きさ（バイト数）です
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired はセッションの有効期限が切れているかを返します
+func (s *UploadSession) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,157 @@
+// Package moderation は投稿された画像と文章が公開してよい内容かを審査します
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Decision は審査の判定です
+type Decision string
+
+const (
+	// Approve は公開してよい内容です
+	Approve Decision = "approve"
+	// Flag は問題の可能性があり、管理者の確認が終わるまで公開しない内容です
+	Flag Decision = "flag"
+	// Reject は利用規約に違反しており、受け付けない内容です
+	Reject Decision = "reject"
+)
+
+// severity は判定の重さを返します（複数の判定は最も重いものにまとめます）
+func (d Decision) severity() int {
+	switch d {
+	case Reject:
+		return 2
+	case Flag:
+		return 1
+	}
+	return 0
+}
+
+// Content は審査する投稿の内容です
+type Content struct {
+	ImageData     []byte
+	ImageMimeType string
+	// Texts は審査する文章です（投稿者の解釈、タイトル、タグなど）
+	Texts []string
+}
+
+// Result は審査の結果です
+type Result struct {
+	Decision Decision
+	// Reasons は Flag または Reject と判定した理由です
+	Reasons []string
+}
+
+// Classifier は投稿の内容を審査するインターフェースです
+// 外部の審査サービスを使う場合もこのインターフェースを実装します
+type Classifier interface {
+	Classify(ctx context.Context, content *Content) (*Result, error)
+}
+
+// Rule は文章に対する審査のルールです
+type Rule struct {
+	// Pattern は正規化した文章に一致させる正規表現です
+	Pattern *regexp.Regexp
+	// Decision は一致した場合の判定です
+	Decision Decision
+	// Reason は一致した場合に記録する理由です
+	Reason string
+}
+
+// RuleClassifier はルールに一致する文章を判定する Classifier です
+// 画像の内容は判定しないため、画像の審査が必要な場合は外部の審査サービスと組み合わせてください
+type RuleClassifier struct {
+	rules []Rule
+}
+
+// NewRuleClassifier はルールで判定する Classifier を作成します
+func NewRuleClassifier(rules []Rule) *RuleClassifier {
+	return &RuleClassifier{rules: rules}
+}
+
+// NewDefaultClassifier は docs/RULES.md の禁止事項に基づく既定のルールで判定する Classifier を作成します
+func NewDefaultClassifier() *RuleClassifier {
+	return NewRuleClassifier(DefaultRules())
+}
+
+// DefaultRules は docs/RULES.md の禁止事項に基づく既定のルールを返します
+// 語句による判定は誤検知があるため、明らかな違反のみ Reject とし、それ以外は Flag にして管理者が確認します
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Pattern:  regexp.MustCompile(`死ね|殺すぞ|殺してやる|ころすぞ|ころしてやる|killyourself`),
+			Decision: Reject,
+			Reason:   "他者を傷つける表現",
+		},
+		{
+			Pattern:  regexp.MustCompile(`児童ポルノ|ロリエロ|childporn`),
+			Decision: Reject,
+			Reason:   "違法な内容",
+		},
+		{
+			Pattern:  regexp.MustCompile(`アダルト|18禁|r18|ヌード|nsfw|porn`),
+			Decision: Flag,
+			Reason:   "性的な内容の可能性",
+		},
+		{
+			Pattern:  regexp.MustCompile(`グロテスク|流血|惨殺|自殺|gore`),
+			Decision: Flag,
+			Reason:   "暴力的な内容の可能性",
+		},
+		{
+			Pattern:  regexp.MustCompile(`0\d{1,4}-\d{1,4}-\d{3,4}|0[789]0\d{8}|[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`),
+			Decision: Flag,
+			Reason:   "個人情報（電話番号・メールアドレス）の可能性",
+		},
+		{
+			Pattern:  regexp.MustCompile(`https?://|www\.`),
+			Decision: Flag,
+			Reason:   "外部サイトへの誘導の可能性",
+		},
+	}
+}
+
+// Classify は文章をルールと照合し、一致したルールのうち最も重い判定を返します
+func (c *RuleClassifier) Classify(ctx context.Context, content *Content) (*Result, error) {
+	result := &Result{Decision: Approve}
+	if content == nil {
+		return result, nil
+	}
+
+	var texts []string
+	for _, text := range content.Texts {
+		texts = append(texts, normalize(text))
+	}
+	text := strings.Join(texts, "\n")
+
+	for _, rule := range c.rules {
+		if !rule.Pattern.MatchString(text) {
+			continue
+		}
+		result.Reasons = append(result.Reasons, rule.Reason)
+		if rule.Decision.severity() > result.Decision.severity() {
+			result.Decision = rule.Decision
+		}
+	}
+	return result, nil
+}
+
+// normalize は表記の揺れでルールをすり抜けられないよう、文章を正規化します
+// 全角英数字を半角に、英字を小文字にし、空白を取り除きます
+func normalize(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
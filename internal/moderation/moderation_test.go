@@ -0,0 +1,96 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleClassifier_DefaultRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		texts       []string
+		want        Decision
+		wantReasons int
+	}{
+		{
+			name:  "問題のない文章",
+			texts: []string{"夕焼けの海辺を散歩する犬", "海辺の犬"},
+			want:  Approve,
+		},
+		{
+			name:  "「ピエロ」や「グローブ」は一致しない",
+			texts: []string{"グローブを持ったピエロ"},
+			want:  Approve,
+		},
+		{
+			name:        "他者を傷つける表現",
+			texts:       []string{"お前なんか死ね"},
+			want:        Reject,
+			wantReasons: 1,
+		},
+		{
+			name:        "空白や全角英字ですり抜けられない",
+			texts:       []string{"ｋｉｌｌ ｙｏｕｒ ｓｅｌｆ"},
+			want:        Reject,
+			wantReasons: 1,
+		},
+		{
+			name:        "性的な内容の可能性",
+			texts:       []string{"NSFW なイラスト"},
+			want:        Flag,
+			wantReasons: 1,
+		},
+		{
+			name:        "タイトルに含まれる電話番号",
+			texts:       []string{"海の写真", "連絡は 090-1234-5678 まで"},
+			want:        Flag,
+			wantReasons: 1,
+		},
+		{
+			name:        "メールアドレスとURL",
+			texts:       []string{"詳しくは https://example.com か foo@example.com へ"},
+			want:        Flag,
+			wantReasons: 2,
+		},
+		{
+			name:        "Flag と Reject の両方に一致する場合は Reject",
+			texts:       []string{"死ね", "https://example.com"},
+			want:        Reject,
+			wantReasons: 2,
+		},
+	}
+
+	classifier := NewDefaultClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := classifier.Classify(context.Background(), &Content{Texts: tt.texts})
+			if err != nil {
+				t.Fatalf("Classify failed: %v", err)
+			}
+			assert.Equal(t, tt.want, result.Decision)
+			assert.Len(t, result.Reasons, tt.wantReasons)
+		})
+	}
+}
+
+func TestRuleClassifier_CustomRules(t *testing.T) {
+	classifier := NewRuleClassifier([]Rule{
+		{Pattern: regexp.MustCompile(`禁止語`), Decision: Reject, Reason: "テスト用の禁止語"},
+	})
+
+	result, err := classifier.Classify(context.Background(), &Content{Texts: []string{"これは禁止語です"}})
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+	assert.Equal(t, Reject, result.Decision)
+	assert.Equal(t, []string{"テスト用の禁止語"}, result.Reasons)
+
+	result, err = classifier.Classify(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+	assert.Equal(t, Approve, result.Decision)
+}
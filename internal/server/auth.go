@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+// Option はServerの設定を変更する関数です
+type Option func(*Server)
+
+// WithVerifier は認証トークンの検証に使用するVerifierを設定します
+// 設定しない場合、すべてのリクエストは匿名として扱われます
+func WithVerifier(verifier auth.Verifier) Option {
+	return func(s *Server) {
+		s.verifier = verifier
+	}
+}
+
+// authenticate は Authorization ヘッダーのトークンを検証し、呼び出し元をコンテキストに設定するミドルウェアです
+// トークンがないリクエストは匿名として通し、不正なトークンは 401 で拒否します
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := auth.BearerToken(r)
+		if errors.Is(err, auth.ErrNoToken) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil || s.verifier == nil {
+			unauthorized(w, "認証トークンが不正です")
+			return
+		}
+
+		identity, err := s.verifier.Verify(r.Context(), token)
+		if err != nil {
+			logging.Warn("authenticate: トークンの検証に失敗: %v", err)
+			unauthorized(w, "認証トークンが不正です")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+	})
+}
+
+// requireAuth は認証済みの呼び出し元のみハンドラーを実行します
+func requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.FromContext(r.Context()); !ok {
+			logging.Warn("requireAuth: 認証されていないリクエスト: %s %s", r.Method, r.URL.Path)
+			unauthorized(w, "認証が必要です")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// unauthorized は 401 レスポンスを返します
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="zenn-ai-hackathon"`)
+	http.Error(w, message, http.StatusUnauthorized)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+// newTestSigner はテスト用のトークン署名器を作成します
+func newTestSigner(t *testing.T) *auth.JWTSigner {
+	t.Helper()
+	signer, err := auth.NewJWTSigner([]byte("0123456789abcdef0123456789abcdef"), "test", time.Hour)
+	if err != nil {
+		t.Fatalf("NewJWTSigner failed: %v", err)
+	}
+	return signer
+}
+
+// authorize はリクエストに呼び出し元のトークンを設定します
+func authorize(t *testing.T, req *http.Request, signer *auth.JWTSigner, identity *auth.Identity) *http.Request {
+	t.Helper()
+	token, err := signer.Sign(identity)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+// withIdentity はハンドラーを直接呼び出すテスト用に、呼び出し元をコンテキストに設定します
+func withIdentity(req *http.Request, identity *auth.Identity) *http.Request {
+	return req.WithContext(auth.WithIdentity(req.Context(), identity))
+}
+
+func TestAuthenticate(t *testing.T) {
+	signer := newTestSigner(t)
+	otherSigner, _ := auth.NewJWTSigner([]byte("fedcba9876543210fedcba9876543210"), "test", time.Hour)
+
+	mockService := &MockQuizService{}
+	mockService.On("GetQuizList", mock.Anything, mock.Anything).Return(&models.QuizPage{}, nil)
+	mockService.On("DeleteAllQuizzes", mock.Anything).Return(nil)
+	srv := NewServer(mockService, WithVerifier(signer))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		prepare        func(req *http.Request)
+		expectedStatus int
+	}{
+		{
+			name:           "公開APIはトークンなしで呼び出せる",
+			method:         http.MethodGet,
+			path:           "/quizzes",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "保護されたAPIはトークンなしで 401",
+			method:         http.MethodDelete,
+			path:           "/delete-all-quizzes",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "保護されたAPIは有効なトークンで呼び出せる",
+			method: http.MethodDelete,
+			path:   "/delete-all-quizzes",
+			prepare: func(req *http.Request) {
+				authorize(t, req, signer, &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "別の鍵で署名されたトークンは 401",
+			method: http.MethodDelete,
+			path:   "/delete-all-quizzes",
+			prepare: func(req *http.Request) {
+				authorize(t, req, otherSigner, &auth.Identity{UserID: "user-1"})
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "公開APIでも不正なトークンは 401",
+			method: http.MethodGet,
+			path:   "/quizzes",
+			prepare: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer invalid")
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "プリフライトリクエストは認証しない",
+			method:         http.MethodOptions,
+			path:           "/upload",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "アップロードはトークンなしで 401",
+			method:         http.MethodPost,
+			path:           "/upload",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.prepare != nil {
+				tt.prepare(req)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthenticate_WithoutVerifier(t *testing.T) {
+	// Verifier が未設定の場合、トークン付きのリクエストは検証できないため拒否する
+	srv := NewServer(&MockQuizService{})
+
+	req := httptest.NewRequest(http.MethodDelete, "/delete-all-quizzes", nil)
+	authorize(t, req, newTestSigner(t), &auth.Identity{UserID: "user-1"})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
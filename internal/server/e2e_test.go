@@ -0,0 +1,309 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/ai"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/imaging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// newEndToEndServer はスタブのAIとローカルストレージで構成したサーバーを作成します
+// ネットワークやGCPの認証情報なしでアップロードの流れ全体を動かせます
+func newEndToEndServer(t *testing.T) (*Server, *auth.JWTSigner) {
+	t.Helper()
+
+	aiClient, err := ai.NewProvider(context.Background(), ai.ProviderStub, ai.ProviderConfig{})
+	if err != nil {
+		t.Fatalf("AIクライアントの作成に失敗: %v", err)
+	}
+	storageClient, err := storage.NewLocalClient(t.TempDir())
+	if err != nil {
+		t.Fatalf("ストレージクライアントの作成に失敗: %v", err)
+	}
+
+	signer := newTestSigner(t)
+	srv := NewServer(service.NewQuizService(aiClient, storageClient), WithVerifier(signer))
+	return srv, signer
+}
+
+func TestUploadFlowEndToEnd(t *testing.T) {
+	srv, signer := newEndToEndServer(t)
+
+	imageData, err := os.ReadFile("../../fixture/generated/fake0.png")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+
+	// 1. アップロード
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "fake0.png")
+	if err != nil {
+		t.Fatalf("フォームファイルの作成に失敗: %v", err)
+	}
+	part.Write(imageData)
+	writer.WriteField("interpretation", "夕暮れの街に灯る明かりは、帰る場所がある安心感を表しています。")
+	writer.WriteField("tags", "夕暮れ, 街")
+	writer.WriteField("title", "夕暮れの街")
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	authorize(t, req, signer, &auth.Identity{UserID: "author-1", DisplayName: "投稿者"})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("アップロードに失敗: status=%d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	var uploaded struct {
+		ID               string            `json:"id"`
+		ImageURL         string            `json:"image_url"`
+		ImageURLs        map[string]string `json:"image_urls"`
+		AuthorID         string            `json:"author_id"`
+		AIInterpretation string            `json:"ai_interpretation"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&uploaded); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
+	}
+	if uploaded.ID == "" || uploaded.AIInterpretation == "" || uploaded.AuthorID != "author-1" {
+		t.Fatalf("不完全なレスポンス: %+v", uploaded)
+	}
+	if !strings.HasSuffix(uploaded.ImageURL, ".png") {
+		t.Errorf("PNG の画像が PNG の拡張子で保存されていません: %s", uploaded.ImageURL)
+	}
+
+	// 2. 一覧に含まれること（作成者とタグで絞り込み）
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes?author=author-1&tag="+url.QueryEscape("街"), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("一覧の取得に失敗: status=%d", rec.Code)
+	}
+	var list struct {
+		Quizzes []struct {
+			ID           string `json:"id"`
+			Title        string `json:"title"`
+			AuthorName   string `json:"author_name"`
+			ThumbnailURL string `json:"thumbnail_url"`
+		} `json:"quizzes"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("一覧のデコードに失敗: %v", err)
+	}
+	if len(list.Quizzes) != 1 || list.Quizzes[0].ID != uploaded.ID {
+		t.Fatalf("一覧に作成したクイズが含まれていません: %+v", list)
+	}
+	if item := list.Quizzes[0]; item.Title != "夕暮れの街" || item.AuthorName != "投稿者" || item.ThumbnailURL != uploaded.ImageURLs["thumbnail"] {
+		t.Errorf("一覧の項目が不完全です: %+v", item)
+	}
+
+	// 3. クイズの取得
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/"+uploaded.ID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("クイズの取得に失敗: status=%d", rec.Code)
+	}
+	var played struct {
+		SessionID string `json:"session_id"`
+		Options   []struct {
+			ID   string `json:"id"`
+			Text string `json:"text"`
+		} `json:"options"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&played); err != nil {
+		t.Fatalf("クイズのデコードに失敗: %v", err)
+	}
+	if played.SessionID == "" || len(played.Options) != 2 {
+		t.Fatalf("不完全なクイズ: %+v", played)
+	}
+	var aiOptionID string
+	for _, option := range played.Options {
+		if option.Text == uploaded.AIInterpretation {
+			aiOptionID = option.ID
+		}
+	}
+	if aiOptionID == "" {
+		t.Fatalf("AIの解釈が選択肢に含まれていません: %+v", played.Options)
+	}
+
+	// 同じセッションで再読み込みしても並び順が変わらないこと
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/"+uploaded.ID+"?session_id="+played.SessionID, nil))
+	var reloaded struct {
+		Options []struct {
+			ID string `json:"id"`
+		} `json:"options"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&reloaded); err != nil {
+		t.Fatalf("クイズのデコードに失敗: %v", err)
+	}
+	for i := range played.Options {
+		if reloaded.Options[i].ID != played.Options[i].ID {
+			t.Fatalf("再読み込みで選択肢が並び替わりました")
+		}
+	}
+
+	// 4. 画像がメタデータ（フィクスチャには XMP と IPTC が含まれる）を取り除いて配信されること
+	wantImage, err := imaging.StripMetadata(imageData, imaging.MimeTypePNG)
+	if err != nil {
+		t.Fatalf("StripMetadata failed: %v", err)
+	}
+	imageReq := httptest.NewRequest(http.MethodGet, uploaded.ImageURL, nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, imageReq)
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), wantImage) {
+		t.Fatalf("画像の配信に失敗: status=%d, size=%d", rec.Code, rec.Body.Len())
+	}
+	if bytes.Contains(rec.Body.Bytes(), []byte("XML:com.adobe.xmp")) {
+		t.Error("expected XMP metadata to be stripped")
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("expected Content-Type image/png, got %q", got)
+	}
+
+	// 縮小した派生画像も配信されること
+	for _, variant := range []string{"thumbnail", "medium"} {
+		rec = httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, uploaded.ImageURLs[variant], nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s の配信に失敗: status=%d", variant, rec.Code)
+		}
+		if _, err := jpeg.Decode(rec.Body); err != nil {
+			t.Errorf("%s が JPEG としてデコードできません: %v", variant, err)
+		}
+	}
+
+	// 範囲を指定して配信されること
+	proxyReq := httptest.NewRequest(http.MethodGet, uploaded.ImageURL, nil)
+	proxyReq.Header.Set("Range", "bytes=0-7")
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, proxyReq)
+	if rec.Code != http.StatusPartialContent || !bytes.Equal(rec.Body.Bytes(), wantImage[:8]) {
+		t.Fatalf("画像プロキシの配信に失敗: status=%d, body=%q", rec.Code, rec.Body.Bytes())
+	}
+	etag := rec.Header().Get("ETag")
+	proxyReq = httptest.NewRequest(http.MethodGet, uploaded.ImageURL, nil)
+	proxyReq.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, proxyReq)
+	if etag == "" || rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for ETag %q, got %d", etag, rec.Code)
+	}
+
+	// 5. 回答の検証
+	rec = httptest.NewRecorder()
+	answer := `{"quiz_id":"` + uploaded.ID + `","session_id":"` + played.SessionID + `","option_id":"` + aiOptionID + `"}`
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/verify-answer", bytes.NewBufferString(answer)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("回答の検証に失敗: status=%d", rec.Code)
+	}
+	var verified struct {
+		IsCorrect bool `json:"is_correct"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&verified); err != nil {
+		t.Fatalf("回答結果のデコードに失敗: %v", err)
+	}
+	if verified.IsCorrect {
+		t.Error("AIの解釈を選んだ回答が正解になっています")
+	}
+
+	// 6. 回答が集計に反映されること
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/"+uploaded.ID+"/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("集計の取得に失敗: status=%d", rec.Code)
+	}
+	var stats struct {
+		Attempts   int     `json:"attempts"`
+		Players    int     `json:"players"`
+		FooledRate float64 `json:"fooled_rate"`
+		DecoyPicks []int   `json:"decoy_picks"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("集計のデコードに失敗: %v", err)
+	}
+	if stats.Attempts != 1 || stats.Players != 1 || stats.FooledRate != 1 || len(stats.DecoyPicks) != 1 || stats.DecoyPicks[0] != 1 {
+		t.Errorf("集計が回答と一致しません: %+v", stats)
+	}
+}
+
+func TestUploadImageFormatsEndToEnd(t *testing.T) {
+	tests := []struct {
+		name         string
+		fixture      string
+		heic         bool
+		wantExt      string
+		wantMimeType string
+	}{
+		{name: "不透明な WebP は JPEG で保存", fixture: "opaque.webp", wantExt: ".jpg", wantMimeType: imaging.MimeTypeJPEG},
+		{name: "透過を含む WebP は PNG で保存", fixture: "alpha.webp", wantExt: ".png", wantMimeType: imaging.MimeTypePNG},
+		{name: "HEIC は JPEG で保存", fixture: "sample.heic", heic: true, wantExt: ".jpg", wantMimeType: imaging.MimeTypeJPEG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// HEIC は libheif の変換コマンドがある環境でのみ受け付ける（サーバーの作成前に登録する）
+			if tt.heic {
+				if err := imaging.RegisterHEICConverter(""); err != nil {
+					t.Skipf("HEIC を変換できない環境です: %v", err)
+				}
+			}
+			srv, signer := newEndToEndServer(t)
+
+			imageData, err := os.ReadFile("../../fixture/formats/" + tt.fixture)
+			if err != nil {
+				t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+			}
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("file", tt.fixture)
+			if err != nil {
+				t.Fatalf("フォームファイルの作成に失敗: %v", err)
+			}
+			part.Write(imageData)
+			writer.WriteField("interpretation", "夕暮れの街に灯る明かりは、帰る場所がある安心感を表しています。")
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			authorize(t, req, signer, &auth.Identity{UserID: "author-1"})
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("アップロードに失敗: status=%d, body=%s", rec.Code, rec.Body.String())
+			}
+			var uploaded struct {
+				ImageURL string `json:"image_url"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&uploaded); err != nil {
+				t.Fatalf("レスポンスのデコードに失敗: %v", err)
+			}
+			if !strings.HasSuffix(uploaded.ImageURL, tt.wantExt) {
+				t.Errorf("expected image to be stored as %s, got %s", tt.wantExt, uploaded.ImageURL)
+			}
+
+			// 保存された画像が変換後の形式で配信されること
+			rec = httptest.NewRecorder()
+			srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, uploaded.ImageURL, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("画像の配信に失敗: status=%d", rec.Code)
+			}
+			if got := imaging.DetectType(rec.Body.Bytes()); got != tt.wantMimeType {
+				t.Errorf("expected stored image to be %s, got %s", tt.wantMimeType, got)
+			}
+		})
+	}
+}
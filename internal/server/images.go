@@ -0,0 +1,203 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+const (
+	// imageCacheControl は公開中のクイズの画像に付ける Cache-Control です
+	// 画像の内容は書き換えないため、ブラウザには再検証せずにキャッシュさせます
+	// 報告や審査で非公開になった後も CDN などの共有キャッシュから配信され続けないよう、private にして閲覧の確認を毎回サーバーで行います
+	imageCacheControl = "private, max-age=31536000, immutable"
+	// privateImageCacheControl は審査で公開されていないクイズの画像に付ける Cache-Control です
+	// 作成者とモデレーションの権限を持つ利用者にしか見せないため、共有キャッシュには保存させず、毎回再検証させます
+	privateImageCacheControl = "private, no-cache"
+)
+
+// errRangeNotSatisfiable は Range ヘッダーの範囲が画像の大きさを超えていることを表します
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// handleGetImage は画像プロキシのハンドラーです
+// 署名付きURLを発行できない環境で、クイズ <ID> の画像 images/<名前> を /images/<ID>/<名前> として配信します
+// パスのクイズを閲覧できる呼び出し元にのみ配信し、そのクイズの画像でない場合や閲覧できないクイズの画像は 404 を返します
+// 画像はメモリに読み込まずにストリーミングし、Range（単一の範囲）と ETag による条件付きリクエストに対応します
+func (s *Server) handleGetImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	quizID, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, service.ImageProxyPrefix), "/")
+	if quizID == "" || name == "" || strings.ContainsAny(quizID+name, "/\\") || strings.Contains(quizID+name, "..") {
+		http.NotFound(w, r)
+		return
+	}
+	imagePath := "images/" + name
+
+	quiz, err := s.quizService.GetImageQuiz(r.Context(), quizID, imagePath)
+	if errors.Is(err, storage.ErrImageNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logging.Error("handleGetImage: 画像のクイズの取得に失敗: name=%s, err=%v", name, err)
+		http.Error(w, "画像の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+	// 閲覧できないクイズの画像は、存在を知られないよう 404 を返す
+	if !canView(r, quiz) {
+		http.NotFound(w, r)
+		return
+	}
+	cacheControl := imageCacheControl
+	if !quiz.Status.Public() {
+		cacheControl = privateImageCacheControl
+	}
+
+	info, err := s.quizService.StatImage(r.Context(), imagePath)
+	if errors.Is(err, storage.ErrImageNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logging.Error("handleGetImage: 画像の取得に失敗: name=%s, err=%v", name, err)
+		http.Error(w, "画像の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	etag := `"` + info.ETag + `"`
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", cacheControl)
+	h.Set("Accept-Ranges", "bytes")
+	h.Set("X-Content-Type-Options", "nosniff")
+	if !info.UpdatedAt.IsZero() {
+		h.Set("Last-Modified", info.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// If-Range が現在の ETag と一致しない場合は、範囲ではなく全体を返す
+	var offset, length int64
+	var partial bool
+	if ifRange := r.Header.Get("If-Range"); ifRange == "" || ifRange == etag {
+		offset, length, partial, err = parseRange(r.Header.Get("Range"), info.Size)
+		if errors.Is(err, errRangeNotSatisfiable) {
+			h.Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			http.Error(w, "Requested range not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+	}
+	if !partial {
+		offset, length = 0, info.Size
+	}
+
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	status := http.StatusOK
+	if partial {
+		h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, info.Size))
+		status = http.StatusPartialContent
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+
+	reader, err := s.quizService.OpenImage(r.Context(), imagePath, offset, length)
+	if err != nil {
+		logging.Error("handleGetImage: 画像の読み込みに失敗: name=%s, err=%v", name, err)
+		for _, key := range []string{"ETag", "Cache-Control", "Last-Modified", "Content-Length", "Content-Range"} {
+			h.Del(key)
+		}
+		http.Error(w, "画像の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+	defer reader.Close()
+
+	w.WriteHeader(status)
+	if _, err := io.Copy(w, reader); err != nil {
+		// ヘッダーは送信済みのため、ログに残すだけにする
+		logging.Warn("handleGetImage: 画像の送信に失敗: name=%s, err=%v", name, err)
+	}
+}
+
+// etagMatches は If-None-Match ヘッダーが etag に一致するかを返します（弱い比較）
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange は Range ヘッダーを解釈し、読み出す範囲の先頭と長さを返します
+// 対応するのは bytes=先頭-末尾、bytes=先頭-、bytes=-末尾からの長さ の単一の範囲のみで、
+// それ以外の指定（複数の範囲や不正な形式）は partial を false として無視します
+// 範囲が画像の大きさを超えている場合は errRangeNotSatisfiable を返します
+func parseRange(header string, size int64) (offset, length int64, partial bool, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		// 末尾からの長さの指定
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, errRangeNotSatisfiable
+		}
+		n = min(n, size)
+		return size - n, n, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		end = min(end, size-1)
+	}
+	if start >= size {
+		return 0, 0, false, errRangeNotSatisfiable
+	}
+	return start, end - start + 1, true, nil
+}
@@ -0,0 +1,346 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+func TestHandleGetImage(t *testing.T) {
+	imageData := []byte("\x89PNG\r\n\x1a\nimage data")
+	size := int64(len(imageData))
+	info := &models.ImageInfo{Size: size, ContentType: "image/png", ETag: "1-15"}
+	quiz := &models.Quiz{ID: "quiz_1", ImagePath: "images/quiz_1.png", AuthorID: "author-1"}
+	flagged := &models.Quiz{ID: "quiz_1", ImagePath: "images/quiz_1.png", AuthorID: "author-1", Status: models.QuizFlagged}
+	signer := newTestSigner(t)
+
+	// open は offset バイト目から length バイトを返す OpenImage のモックを設定します
+	open := func(m *MockQuizService, offset, length int64) {
+		m.On("OpenImage", mock.Anything, "images/quiz_1.png", offset, length).
+			Return(io.NopCloser(bytes.NewReader(imageData[offset:offset+length])), nil)
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		header       map[string]string
+		identity     *auth.Identity
+		setupMock    func(*MockQuizService)
+		wantStatus   int
+		wantBody     []byte
+		wantEmpty    bool
+		wantHeader   map[string]string
+		wantNoHeader []string
+	}{
+		{
+			name:   "正常系：画像全体",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+				open(m, 0, size)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   imageData,
+			wantHeader: map[string]string{
+				"Content-Type":   "image/png",
+				"Content-Length": strconv.FormatInt(size, 10),
+				"ETag":           `"1-15"`,
+				"Cache-Control":  "private, max-age=31536000, immutable",
+				"Accept-Ranges":  "bytes",
+			},
+			wantNoHeader: []string{"Pragma", "Expires"},
+		},
+		{
+			name:   "正常系：範囲の指定",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			header: map[string]string{"Range": "bytes=2-4"},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+				open(m, 2, 3)
+			},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   imageData[2:5],
+			wantHeader: map[string]string{
+				"Content-Range":  fmt.Sprintf("bytes 2-4/%d", size),
+				"Content-Length": "3",
+			},
+		},
+		{
+			name:   "正常系：末尾からの範囲",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			header: map[string]string{"Range": "bytes=-4"},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+				open(m, size-4, 4)
+			},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   imageData[size-4:],
+			wantHeader: map[string]string{"Content-Range": fmt.Sprintf("bytes %d-%d/%d", size-4, size-1, size)},
+		},
+		{
+			name:   "正常系：If-Range が一致しない場合は全体",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			header: map[string]string{"Range": "bytes=2-4", "If-Range": `"0-0"`},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+				open(m, 0, size)
+			},
+			wantStatus:   http.StatusOK,
+			wantBody:     imageData,
+			wantNoHeader: []string{"Content-Range"},
+		},
+		{
+			name:   "正常系：ETag が一致すれば 304",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			header: map[string]string{"If-None-Match": `"0-0", W/"1-15"`},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+			},
+			wantStatus: http.StatusNotModified,
+			wantEmpty:  true,
+			wantHeader: map[string]string{"ETag": `"1-15"`},
+		},
+		{
+			name:   "正常系：HEAD は本文なし",
+			method: http.MethodHead,
+			path:   "/images/quiz_1/quiz_1.png",
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantEmpty:  true,
+			wantHeader: map[string]string{"Content-Length": strconv.FormatInt(size, 10)},
+		},
+		{
+			name:   "異常系：画像の大きさを超える範囲",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			header: map[string]string{"Range": "bytes=100-"},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+			},
+			wantStatus: http.StatusRequestedRangeNotSatisfiable,
+			wantHeader: map[string]string{"Content-Range": fmt.Sprintf("bytes */%d", size)},
+		},
+		{
+			name:   "異常系：存在しない画像",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/missing.jpg",
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/missing.jpg").Return(nil, fmt.Errorf("wrap: %w", storage.ErrImageNotFound))
+			},
+			wantStatus:   http.StatusNotFound,
+			wantNoHeader: []string{"ETag", "Cache-Control"},
+		},
+		{
+			name:   "異常系：ストレージのエラー",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(quiz, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+				m.On("OpenImage", mock.Anything, "images/quiz_1.png", int64(0), size).Return(nil, errors.New("storage error"))
+			},
+			wantStatus:   http.StatusInternalServerError,
+			wantNoHeader: []string{"ETag", "Cache-Control"},
+		},
+		{
+			name:     "正常系：審査待ちのクイズの画像は作成者にキャッシュさせずに配信する",
+			method:   http.MethodGet,
+			path:     "/images/quiz_1/quiz_1.png",
+			identity: &auth.Identity{UserID: "author-1"},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(flagged, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+				open(m, 0, size)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   imageData,
+			wantHeader: map[string]string{"Cache-Control": "private, no-cache"},
+		},
+		{
+			name:     "正常系：審査待ちのクイズの画像は管理者にも配信する",
+			method:   http.MethodGet,
+			path:     "/images/quiz_1/quiz_1.png",
+			identity: &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(flagged, nil)
+				m.On("StatImage", mock.Anything, "images/quiz_1.png").Return(info, nil)
+				open(m, 0, size)
+			},
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"Cache-Control": "private, no-cache"},
+		},
+		{
+			name:   "異常系：審査待ちのクイズの画像は他の利用者には見せない",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(flagged, nil)
+			},
+			wantStatus:   http.StatusNotFound,
+			wantNoHeader: []string{"ETag", "Cache-Control"},
+		},
+		{
+			name:     "異常系：他の利用者が作成した審査待ちのクイズの画像",
+			method:   http.MethodGet,
+			path:     "/images/quiz_1/quiz_1.png",
+			identity: &auth.Identity{UserID: "user-2"},
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(flagged, nil)
+			},
+			wantStatus:   http.StatusNotFound,
+			wantNoHeader: []string{"ETag", "Cache-Control"},
+		},
+		{
+			name:   "異常系：クイズの取得のエラー",
+			method: http.MethodGet,
+			path:   "/images/quiz_1/quiz_1.png",
+			setupMock: func(m *MockQuizService) {
+				m.On("GetImageQuiz", mock.Anything, "quiz_1", "images/quiz_1.png").Return(nil, errors.New("storage error"))
+			},
+			wantStatus:   http.StatusInternalServerError,
+			wantNoHeader: []string{"ETag", "Cache-Control"},
+		},
+		{
+			name:       "異常系：クイズのIDがないパス",
+			method:     http.MethodGet,
+			path:       "/images/quiz_1.png",
+			setupMock:  func(m *MockQuizService) {},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "異常系：画像の名前より深いパス",
+			method:     http.MethodGet,
+			path:       "/images/quiz_1/nested/quiz_1.png",
+			setupMock:  func(m *MockQuizService) {},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "異常系：不正なメソッド",
+			method:     http.MethodPost,
+			path:       "/images/quiz_1/quiz_1.png",
+			setupMock:  func(m *MockQuizService) {},
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "正常系：プリフライトリクエスト",
+			method:     http.MethodOptions,
+			path:       "/images/quiz_1/quiz_1.png",
+			setupMock:  func(m *MockQuizService) {},
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Methods": "GET, HEAD, OPTIONS",
+				"Access-Control-Allow-Headers": "Range, If-None-Match, If-Range",
+			},
+			wantNoHeader: []string{"Cache-Control"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockQuizService)
+			tt.setupMock(mockService)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if tt.identity != nil {
+				authorize(t, req, signer, tt.identity)
+			}
+			rec := httptest.NewRecorder()
+			NewServer(mockService, WithVerifier(signer)).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != nil {
+				assert.Equal(t, tt.wantBody, rec.Body.Bytes())
+			}
+			if tt.wantEmpty {
+				assert.Empty(t, rec.Body.Bytes())
+			}
+			for k, v := range tt.wantHeader {
+				assert.Equal(t, v, rec.Header().Get(k), k)
+			}
+			for _, k := range tt.wantNoHeader {
+				assert.Empty(t, rec.Header().Get(k), k)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouteCachePolicy(t *testing.T) {
+	// APIのレスポンスはキャッシュさせない
+	rec := httptest.NewRecorder()
+	NewServer(new(MockQuizService)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, "no-store, no-cache, must-revalidate, proxy-revalidate", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+}
+
+func TestParseRange(t *testing.T) {
+	const size = 100
+	tests := []struct {
+		header      string
+		wantOffset  int64
+		wantLength  int64
+		wantPartial bool
+		wantErr     bool
+	}{
+		{header: "", wantPartial: false},
+		{header: "bytes=0-9", wantOffset: 0, wantLength: 10, wantPartial: true},
+		{header: "bytes=90-", wantOffset: 90, wantLength: 10, wantPartial: true},
+		{header: "bytes=90-200", wantOffset: 90, wantLength: 10, wantPartial: true},
+		{header: "bytes=-10", wantOffset: 90, wantLength: 10, wantPartial: true},
+		{header: "bytes=-200", wantOffset: 0, wantLength: 100, wantPartial: true},
+		{header: "bytes=100-", wantErr: true},
+		{header: "bytes=-0", wantErr: true},
+		// 対応していない指定や不正な形式は無視する
+		{header: "bytes=0-1,5-6", wantPartial: false},
+		{header: "bytes=5-1", wantPartial: false},
+		{header: "bytes=a-b", wantPartial: false},
+		{header: "items=0-1", wantPartial: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			offset, length, partial, err := parseRange(tt.header, size)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errRangeNotSatisfiable)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPartial, partial)
+			if tt.wantPartial {
+				assert.Equal(t, tt.wantOffset, offset)
+				assert.Equal(t, tt.wantLength, length)
+			}
+		})
+	}
+}
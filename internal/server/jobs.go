@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// jobsPath はクイズ作成ジョブのAPIのURLパスです
+const jobsPath = "/jobs/"
+
+// WithJobQueue はクイズを非同期に作成するジョブのキューを設定します
+// 未設定の場合、/upload は非同期の指定を無視して同期的にクイズを作成し、/jobs/ のルートは登録しません
+func WithJobQueue(jobQueue service.JobQueue) Option {
+	return func(s *Server) {
+		s.jobQueue = jobQueue
+	}
+}
+
+// jobResponse はクイズ作成ジョブの状態です
+type jobResponse struct {
+	ID        string           `json:"id"`
+	Status    models.JobStatus `json:"status"`
+	QuizID    string           `json:"quiz_id,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	StatusURL string           `json:"status_url"`
+	CreatedAt string           `json:"created_at"`
+	UpdatedAt string           `json:"updated_at"`
+}
+
+// newJobResponse はクイズ作成ジョブの状態を作成します
+func newJobResponse(job *models.Job) *jobResponse {
+	return &jobResponse{
+		ID:        job.ID,
+		Status:    job.Status,
+		QuizID:    job.QuizID,
+		Error:     job.Error,
+		StatusURL: jobsPath + job.ID,
+		CreatedAt: job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: job.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// wantsAsync はリクエストが非同期でのクイズ作成を求めているかを返します
+// クエリの async=true、または Prefer: respond-async ヘッダーで指定します
+func wantsAsync(r *http.Request) bool {
+	if v := r.URL.Query().Get("async"); v == "true" || v == "1" {
+		return true
+	}
+	for _, prefer := range r.Header.Values("Prefer") {
+		for _, token := range strings.Split(prefer, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// enqueueQuiz はクイズ作成ジョブを登録し、202 Accepted とジョブの状態を返します
+func (s *Server) enqueueQuiz(w http.ResponseWriter, r *http.Request, input *service.CreateQuizInput) {
+	job, err := s.jobQueue.Enqueue(r.Context(), input)
+	if err != nil {
+		logging.Error("enqueueQuiz: ジョブの登録に失敗: %v", err)
+		switch {
+		case errors.Is(err, service.ErrInvalidInterpretation) || errors.Is(err, service.ErrInvalidTag) || errors.Is(err, service.ErrInvalidTitle):
+			http.Error(w, "クイズの作成に失敗しました: "+err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrJobQueueFull):
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, "混み合っています。しばらくしてから再度お試しください", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "ジョブの登録に失敗しました", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", jobsPath+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(newJobResponse(job)); err != nil {
+		logging.Error("enqueueQuiz: レスポンスの送信に失敗: %v", err)
+		return
+	}
+	logging.Info("enqueueQuiz: ジョブを登録しました: id=%s", job.ID)
+}
+
+// handleGetJob はクイズ作成ジョブの状態を返すハンドラーです
+// ジョブを登録した利用者のみ取得できます
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	identity, ok := auth.FromContext(r.Context())
+	if !ok {
+		unauthorized(w, "認証が必要です")
+		return
+	}
+
+	jobID := strings.TrimPrefix(r.URL.Path, jobsPath)
+	job, err := s.jobQueue.GetJob(r.Context(), jobID)
+	if err != nil {
+		logging.Warn("handleGetJob: ジョブの取得に失敗: id=%s, err=%v", jobID, err)
+		if errors.Is(err, storage.ErrJobNotFound) {
+			http.Error(w, "ジョブが見つかりません", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "ジョブの取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+	if job.AuthorID != identity.UserID {
+		http.Error(w, "ジョブを登録した利用者のみ取得できます", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newJobResponse(job)); err != nil {
+		logging.Error("handleGetJob: レスポンスの送信に失敗: %v", err)
+	}
+}
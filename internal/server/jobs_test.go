@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// MockJobQueue はJobQueueのモック
+type MockJobQueue struct {
+	mock.Mock
+}
+
+func (m *MockJobQueue) Enqueue(ctx context.Context, input *service.CreateQuizInput) (*models.Job, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *MockJobQueue) GetJob(ctx context.Context, jobID string) (*models.Job, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+// newUploadRequest は画像と解釈を含む /upload のリクエストを作成します
+func newUploadRequest(t *testing.T, target string) *http.Request {
+	t.Helper()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "test.png")
+	if err != nil {
+		t.Fatalf("フォームファイルの作成に失敗: %v", err)
+	}
+	part.Write(testPNG(t))
+	writer.WriteField("interpretation", "投稿者の解釈")
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return withIdentity(req, &auth.Identity{UserID: "user-1"})
+}
+
+func TestHandleUpload_Async(t *testing.T) {
+	job := &models.Job{ID: "job_1", Status: models.JobPending, AuthorID: "user-1", CreatedAt: time.Now()}
+
+	tests := []struct {
+		name           string
+		target         string
+		prefer         string
+		enqueueErr     error
+		expectedStatus int
+	}{
+		{name: "クエリで指定", target: "/upload?async=true", expectedStatus: http.StatusAccepted},
+		{name: "Prefer ヘッダーで指定", target: "/upload", prefer: "respond-async, wait=5", expectedStatus: http.StatusAccepted},
+		{name: "キューが満杯", target: "/upload?async=1", enqueueErr: service.ErrJobQueueFull, expectedStatus: http.StatusServiceUnavailable},
+		{name: "不正なタグ", target: "/upload?async=1", enqueueErr: fmt.Errorf("%w: too many", service.ErrInvalidTag), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQueue := &MockJobQueue{}
+			if tt.enqueueErr != nil {
+				mockQueue.On("Enqueue", mock.Anything, mock.Anything).Return(nil, tt.enqueueErr)
+			} else {
+				mockQueue.On("Enqueue", mock.Anything, mock.MatchedBy(func(input *service.CreateQuizInput) bool {
+					return input.AuthorID == "user-1" && input.ImageMimeType == "image/png" && len(input.ImageData) > 0
+				})).Return(job, nil)
+			}
+			mockService := &MockQuizService{}
+
+			req := newUploadRequest(t, tt.target)
+			if tt.prefer != "" {
+				req.Header.Set("Prefer", tt.prefer)
+			}
+			rec := httptest.NewRecorder()
+			NewServer(mockService, WithJobQueue(mockQueue)).handleUpload(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			mockService.AssertNotCalled(t, "CreateQuiz", mock.Anything, mock.Anything)
+			mockQueue.AssertExpectations(t)
+			if tt.expectedStatus != http.StatusAccepted {
+				return
+			}
+			assert.Equal(t, "/jobs/job_1", rec.Header().Get("Location"))
+			var response jobResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("レスポンスのデコードに失敗: %v", err)
+			}
+			assert.Equal(t, "job_1", response.ID)
+			assert.Equal(t, models.JobPending, response.Status)
+		})
+	}
+
+	t.Run("キューが未設定の場合は同期的に作成", func(t *testing.T) {
+		mockService := &MockQuizService{}
+		mockService.On("CreateQuiz", mock.Anything, mock.Anything).Return(&models.Quiz{ID: "test-quiz", ImagePath: "test-image.png"}, nil)
+		mockService.On("GetSignedImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://storage.example.com/test-image.png", nil)
+
+		rec := httptest.NewRecorder()
+		NewServer(mockService).handleUpload(rec, newUploadRequest(t, "/upload?async=true"))
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHandleGetJob(t *testing.T) {
+	signer := newTestSigner(t)
+	succeeded := &models.Job{ID: "job_1", Status: models.JobSucceeded, AuthorID: "user-1", QuizID: "quiz_1"}
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		path           string
+		setupMock      func(*MockJobQueue)
+		expectedStatus int
+		expectedQuizID string
+	}{
+		{
+			name:     "正常系：作成者",
+			identity: &auth.Identity{UserID: "user-1"},
+			path:     "/jobs/job_1",
+			setupMock: func(m *MockJobQueue) {
+				m.On("GetJob", mock.Anything, "job_1").Return(succeeded, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedQuizID: "quiz_1",
+		},
+		{
+			name:     "異常系：作成者以外",
+			identity: &auth.Identity{UserID: "other"},
+			path:     "/jobs/job_1",
+			setupMock: func(m *MockJobQueue) {
+				m.On("GetJob", mock.Anything, "job_1").Return(succeeded, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:     "異常系：存在しないジョブ",
+			identity: &auth.Identity{UserID: "user-1"},
+			path:     "/jobs/job_missing",
+			setupMock: func(m *MockJobQueue) {
+				m.On("GetJob", mock.Anything, "job_missing").Return(nil, fmt.Errorf("wrap: %w", storage.ErrJobNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:     "異常系：ストレージのエラー",
+			identity: &auth.Identity{UserID: "user-1"},
+			path:     "/jobs/job_1",
+			setupMock: func(m *MockJobQueue) {
+				m.On("GetJob", mock.Anything, "job_1").Return(nil, errors.New("storage error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "異常系：未認証",
+			path:           "/jobs/job_1",
+			setupMock:      func(m *MockJobQueue) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQueue := &MockJobQueue{}
+			tt.setupMock(mockQueue)
+			srv := NewServer(&MockQuizService{}, WithVerifier(signer), WithJobQueue(mockQueue))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.identity != nil {
+				authorize(t, req, signer, tt.identity)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			mockQueue.AssertExpectations(t)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response jobResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("レスポンスのデコードに失敗: %v", err)
+			}
+			assert.Equal(t, models.JobSucceeded, response.Status)
+			assert.Equal(t, tt.expectedQuizID, response.QuizID)
+		})
+	}
+}
+
+func TestWantsAsync(t *testing.T) {
+	tests := []struct {
+		target string
+		prefer string
+		want   bool
+	}{
+		{target: "/upload", want: false},
+		{target: "/upload?async=true", want: true},
+		{target: "/upload?async=false", want: false},
+		{target: "/upload", prefer: "Respond-Async", want: true},
+		{target: "/upload", prefer: "return=minimal", want: false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.target, nil)
+		if tt.prefer != "" {
+			req.Header.Set("Prefer", tt.prefer)
+		}
+		assert.Equal(t, tt.want, wantsAsync(req), "%s %s", tt.target, tt.prefer)
+	}
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// moderationPath は管理者がクイズを審査するAPIのURLパスです
+const moderationPath = "/admin/moderation"
+
+// reviewActions は審査の操作と、認可・監査ログで扱う操作の対応です
+var reviewActions = map[service.ReviewAction]action{
+	service.ReviewApprove:   actionApproveQuiz,
+	service.ReviewReject:    actionRejectQuiz,
+	service.ReviewBanAuthor: actionBanAuthor,
+}
+
+// WithModerationService は管理者による審査と、プレイヤーからの報告を扱うサービスを設定します
+// 未設定の場合は /admin/moderation と /quizzes/{id}/report のルートを提供しません
+func WithModerationService(moderationService service.ModerationService) Option {
+	return func(s *Server) {
+		s.moderationService = moderationService
+	}
+}
+
+// moderationReportResponse は管理者に返すプレイヤーからの報告です
+type moderationReportResponse struct {
+	ReporterID string `json:"reporter_id"`
+	Reason     string `json:"reason"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// moderationQuizResponse は管理者に返す審査待ちのクイズです
+// 判断に必要なため、投稿者の解釈と未確認の報告を含みます
+type moderationQuizResponse struct {
+	ID                   string                         `json:"id"`
+	Title                string                         `json:"title"`
+	ImageURLs            map[models.ImageVariant]string `json:"image_urls"`
+	AuthorID             string                         `json:"author_id"`
+	AuthorName           string                         `json:"author_name"`
+	AuthorInterpretation string                         `json:"author_interpretation"`
+	Tags                 []string                       `json:"tags"`
+	Status               models.QuizStatus              `json:"status"`
+	Reasons              []string                       `json:"reasons"`
+	Reports              []moderationReportResponse     `json:"reports"`
+	ReviewedBy           string                         `json:"reviewed_by,omitempty"`
+	CreatedAt            string                         `json:"created_at"`
+}
+
+// newModerationQuizResponse は管理者に返す審査待ちのクイズを作成します
+func newModerationQuizResponse(quiz *models.Quiz, urls map[models.ImageVariant]string) *moderationQuizResponse {
+	response := &moderationQuizResponse{
+		ID:                   quiz.ID,
+		Title:                quiz.Title,
+		ImageURLs:            urls,
+		AuthorID:             quiz.AuthorID,
+		AuthorName:           quiz.AuthorName,
+		AuthorInterpretation: quiz.AuthorInterpretation,
+		Tags:                 quiz.Tags,
+		Status:               quizStatus(quiz),
+		Reasons:              quiz.ModerationReasons,
+		Reports:              []moderationReportResponse{},
+		ReviewedBy:           quiz.ReviewedBy,
+		CreatedAt:            quiz.CreatedAt.Format(time.RFC3339),
+	}
+	if response.Reasons == nil {
+		response.Reasons = []string{}
+	}
+	for _, report := range quiz.PendingReports() {
+		response.Reports = append(response.Reports, moderationReportResponse{
+			ReporterID: report.ReporterID,
+			Reason:     report.Reason,
+			CreatedAt:  report.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return response
+}
+
+// handleListModeration は管理者の確認を待っているクイズを古い順に返すハンドラーです
+func (s *Server) handleListModeration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit は1以上の整数で指定してください", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	queue, err := s.moderationService.ListPending(r.Context(), limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		logging.Error("handleListModeration: 審査待ちのクイズの取得に失敗: %v", err)
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			http.Error(w, "cursor が不正です", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "審査待ちのクイズの取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	response := struct {
+		Quizzes    []*moderationQuizResponse `json:"quizzes"`
+		NextCursor string                    `json:"next_cursor,omitempty"`
+	}{
+		Quizzes:    make([]*moderationQuizResponse, 0, len(queue.Quizzes)),
+		NextCursor: queue.NextCursor,
+	}
+	for _, quiz := range queue.Quizzes {
+		urls, err := s.imageURLs(r.Context(), quiz.Image())
+		if err != nil {
+			logging.Error("handleListModeration: 画像URLの生成に失敗: id=%s, err=%v", quiz.ID, err)
+			http.Error(w, "画像URLの生成に失敗しました", http.StatusInternalServerError)
+			return
+		}
+		response.Quizzes = append(response.Quizzes, newModerationQuizResponse(quiz, urls))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.Error("handleListModeration: レスポンスの送信に失敗: %v", err)
+	}
+}
+
+// handleReviewQuiz は管理者がクイズを審査するハンドラーです
+// POST /admin/moderation/{id}/{approve|reject|ban-author} を受け付け、許可・拒否のいずれも監査ログに記録します
+func (s *Server) handleReviewQuiz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	quizID, verb, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, moderationPath+"/"), "/")
+	reviewAction := service.ReviewAction(verb)
+	act, ok := reviewActions[reviewAction]
+	if quizID == "" || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.requirePermissionOn(act, quizID, func(w http.ResponseWriter, r *http.Request) {
+		s.reviewQuiz(w, r, quizID, reviewAction)
+	})(w, r)
+}
+
+// reviewQuiz はクイズの審査を行い、結果を返します
+// 認可と監査ログへの記録は呼び出し元の requirePermissionOn が行います
+func (s *Server) reviewQuiz(w http.ResponseWriter, r *http.Request, quizID string, reviewAction service.ReviewAction) {
+	identity, _ := auth.FromContext(r.Context())
+
+	// 理由は省略できるため、空のボディも受け付ける
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "リクエストの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.moderationService.Review(r.Context(), quizID, &service.ReviewInput{
+		Action:     reviewAction,
+		ReviewerID: identity.UserID,
+		Reason:     request.Reason,
+	})
+	if err != nil {
+		logging.Error("handleReviewQuiz: 審査に失敗: id=%s, action=%s, err=%v", quizID, reviewAction, err)
+		status := quizErrorStatus(err)
+		if errors.Is(err, service.ErrInvalidReview) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("審査に失敗しました: %v", err), status)
+		return
+	}
+
+	// 利用停止は投稿者に対する操作のため、監査ログの対象を投稿者にする
+	if result.Ban != nil {
+		setAuditTarget(w, result.Ban.AuthorID)
+	}
+
+	response := struct {
+		Quiz            *moderationQuizResponse `json:"quiz,omitempty"`
+		BannedAuthorID  string                  `json:"banned_author_id,omitempty"`
+		RejectedQuizzes int                     `json:"rejected_quizzes,omitempty"`
+	}{
+		RejectedQuizzes: result.RejectedQuizzes,
+	}
+	if result.Quiz != nil {
+		urls, err := s.imageURLs(r.Context(), result.Quiz.Image())
+		if err != nil {
+			logging.Error("handleReviewQuiz: 画像URLの生成に失敗: %v", err)
+			http.Error(w, "画像URLの生成に失敗しました", http.StatusInternalServerError)
+			return
+		}
+		response.Quiz = newModerationQuizResponse(result.Quiz, urls)
+	}
+	if result.Ban != nil {
+		response.BannedAuthorID = result.Ban.AuthorID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.Error("handleReviewQuiz: レスポンスの送信に失敗: %v", err)
+	}
+}
+
+// handleReportQuiz はプレイヤーが不適切なクイズを報告するハンドラーです
+// 未ログインのプレイヤーも報告でき、その場合は匿名のプレイヤーIDを報告者として記録します
+// 匿名のプレイヤーIDはクライアントが指定できるため、匿名の報告だけではクイズの公開を自動で保留にしません
+func (s *Server) handleReportQuiz(w http.ResponseWriter, r *http.Request, quizID string) {
+	if s.moderationService == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 閲覧できないクイズは、存在を知られないよう見つからないものとして扱う
+	quiz, err := s.quizService.GetQuiz(r.Context(), quizID)
+	if err != nil {
+		logging.Warn("handleReportQuiz: クイズの取得に失敗: id=%s, err=%v", quizID, err)
+		if errors.Is(err, storage.ErrQuizNotFound) {
+			http.Error(w, "クイズが見つかりません", http.StatusNotFound)
+		} else {
+			http.Error(w, "報告の受け付けに失敗しました", http.StatusInternalServerError)
+		}
+		return
+	}
+	if !canView(r, quiz) {
+		http.Error(w, "クイズが見つかりません", http.StatusNotFound)
+		return
+	}
+
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "リクエストの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	reporterID := ""
+	if identity, ok := auth.FromContext(r.Context()); ok {
+		reporterID = identity.UserID
+	} else {
+		player, err := playerID(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reporterID = models.AnonymousReporterPrefix + player
+	}
+
+	_, err = s.moderationService.ReportQuiz(r.Context(), quizID, &service.ReportInput{
+		ReporterID: reporterID,
+		Reason:     request.Reason,
+	})
+	if err != nil {
+		logging.Warn("handleReportQuiz: 報告の受け付けに失敗: id=%s, err=%v", quizID, err)
+		switch {
+		case errors.Is(err, service.ErrInvalidReport):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrAlreadyReported):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrReportLimitReached):
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case errors.Is(err, storage.ErrQuizNotFound):
+			http.Error(w, "クイズが見つかりません", http.StatusNotFound)
+		default:
+			http.Error(w, "報告の受け付けに失敗しました", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "報告を受け付けました",
+	})
+}
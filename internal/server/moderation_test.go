@@ -0,0 +1,393 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// MockModerationService はModerationServiceのモック
+type MockModerationService struct {
+	mock.Mock
+}
+
+func (m *MockModerationService) ListPending(ctx context.Context, limit int, cursor string) (*service.ModerationQueue, error) {
+	args := m.Called(ctx, limit, cursor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ModerationQueue), args.Error(1)
+}
+
+func (m *MockModerationService) Review(ctx context.Context, quizID string, input *service.ReviewInput) (*service.ReviewResult, error) {
+	args := m.Called(ctx, quizID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.ReviewResult), args.Error(1)
+}
+
+func (m *MockModerationService) ReportQuiz(ctx context.Context, quizID string, input *service.ReportInput) (*models.Quiz, error) {
+	args := m.Called(ctx, quizID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Quiz), args.Error(1)
+}
+
+// newModerationServer は画像URLの生成をモックしたサーバーを作成します
+func newModerationServer(moderationService service.ModerationService, opts ...Option) *Server {
+	mockService := &MockQuizService{}
+	mockService.On("GetSignedImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://example.com/test.png", nil)
+	return NewServer(mockService, append(opts, WithModerationService(moderationService))...)
+}
+
+var testAdmin = &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}}
+
+func TestHandleListModeration(t *testing.T) {
+	quiz := &models.Quiz{
+		ID:                "quiz-1",
+		ImagePath:         "images/test.png",
+		AuthorID:          "author-1",
+		Status:            models.QuizFlagged,
+		ModerationReasons: []string{"個人情報の可能性"},
+		Reports:           []models.QuizReport{{ReporterID: "player:abc", Reason: "電話番号が書かれている", CreatedAt: time.Now()}},
+		CreatedAt:         time.Now(),
+	}
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		query          string
+		expectedStatus int
+	}{
+		{name: "未認証は 401", expectedStatus: http.StatusUnauthorized},
+		{name: "管理者ではない利用者は 403", identity: &auth.Identity{UserID: "user-1"}, expectedStatus: http.StatusForbidden},
+		{name: "管理者は一覧を取得できる", identity: testAdmin, query: "?limit=5&cursor=c1", expectedStatus: http.StatusOK},
+		{name: "不正な limit", identity: testAdmin, query: "?limit=x", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderationService := &MockModerationService{}
+			moderationService.On("ListPending", mock.Anything, 5, "c1").Return(&service.ModerationQueue{
+				Quizzes:    []*models.Quiz{quiz},
+				NextCursor: "c2",
+			}, nil)
+			recorder := &auditRecorder{}
+			srv := newModerationServer(moderationService, WithAuditLogger(recorder))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/moderation"+tt.query, nil)
+			if tt.identity != nil {
+				req = withIdentity(req, tt.identity)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if assert.Len(t, recorder.entries, 1) {
+				assert.Equal(t, string(actionModerate), recorder.entries[0].Action)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Quizzes []struct {
+					ID      string   `json:"id"`
+					Status  string   `json:"status"`
+					Reasons []string `json:"reasons"`
+					Reports []struct {
+						ReporterID string `json:"reporter_id"`
+						Reason     string `json:"reason"`
+					} `json:"reports"`
+				} `json:"quizzes"`
+				NextCursor string `json:"next_cursor"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("レスポンスのデコードに失敗: %v", err)
+			}
+			if assert.Len(t, response.Quizzes, 1) {
+				got := response.Quizzes[0]
+				assert.Equal(t, "quiz-1", got.ID)
+				assert.Equal(t, "flagged", got.Status)
+				assert.Equal(t, []string{"個人情報の可能性"}, got.Reasons)
+				if assert.Len(t, got.Reports, 1) {
+					assert.Equal(t, "電話番号が書かれている", got.Reports[0].Reason)
+				}
+			}
+			assert.Equal(t, "c2", response.NextCursor)
+		})
+	}
+}
+
+func TestHandleReviewQuiz(t *testing.T) {
+	approved := &models.Quiz{ID: "quiz-1", ImagePath: "images/test.png", AuthorID: "author-1", Status: models.QuizApproved}
+	rejected := &models.Quiz{ID: "quiz-1", ImagePath: "images/test.png", AuthorID: "author-1", Status: models.QuizRejected}
+
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		path           string
+		body           string
+		result         *service.ReviewResult
+		reviewErr      error
+		expectedInput  *service.ReviewInput
+		expectedStatus int
+		expectedAction action
+		expectedTarget string
+		expectedAllow  bool
+	}{
+		{
+			name:           "公開",
+			identity:       testAdmin,
+			path:           "/admin/moderation/quiz-1/approve",
+			result:         &service.ReviewResult{Quiz: approved},
+			expectedInput:  &service.ReviewInput{Action: service.ReviewApprove, ReviewerID: "admin-1"},
+			expectedStatus: http.StatusOK,
+			expectedAction: actionApproveQuiz,
+			expectedTarget: "quiz-1",
+			expectedAllow:  true,
+		},
+		{
+			name:           "理由を付けて拒否",
+			identity:       testAdmin,
+			path:           "/admin/moderation/quiz-1/reject",
+			body:           `{"reason":"規約違反"}`,
+			result:         &service.ReviewResult{Quiz: rejected},
+			expectedInput:  &service.ReviewInput{Action: service.ReviewReject, ReviewerID: "admin-1", Reason: "規約違反"},
+			expectedStatus: http.StatusOK,
+			expectedAction: actionRejectQuiz,
+			expectedTarget: "quiz-1",
+			expectedAllow:  true,
+		},
+		{
+			name:           "投稿者の利用停止は投稿者を対象に記録",
+			identity:       testAdmin,
+			path:           "/admin/moderation/quiz-1/ban-author",
+			body:           `{"reason":"スパム"}`,
+			result:         &service.ReviewResult{Quiz: rejected, Ban: &models.AuthorBan{AuthorID: "author-1"}, RejectedQuizzes: 3},
+			expectedInput:  &service.ReviewInput{Action: service.ReviewBanAuthor, ReviewerID: "admin-1", Reason: "スパム"},
+			expectedStatus: http.StatusOK,
+			expectedAction: actionBanAuthor,
+			expectedTarget: "author-1",
+			expectedAllow:  true,
+		},
+		{
+			name:           "未認証は 401",
+			path:           "/admin/moderation/quiz-1/reject",
+			expectedStatus: http.StatusUnauthorized,
+			expectedAction: actionRejectQuiz,
+			expectedTarget: "quiz-1",
+		},
+		{
+			name:           "管理者ではない利用者は 403",
+			identity:       &auth.Identity{UserID: "user-1"},
+			path:           "/admin/moderation/quiz-1/approve",
+			expectedStatus: http.StatusForbidden,
+			expectedAction: actionApproveQuiz,
+			expectedTarget: "quiz-1",
+		},
+		{
+			name:           "存在しないクイズ",
+			identity:       testAdmin,
+			path:           "/admin/moderation/missing/approve",
+			reviewErr:      fmt.Errorf("クイズの審査に失敗: %w", storage.ErrQuizNotFound),
+			expectedInput:  &service.ReviewInput{Action: service.ReviewApprove, ReviewerID: "admin-1"},
+			expectedStatus: http.StatusNotFound,
+			expectedAction: actionApproveQuiz,
+			expectedTarget: "missing",
+			expectedAllow:  true,
+		},
+		{
+			name:           "作成者のいないクイズの利用停止",
+			identity:       testAdmin,
+			path:           "/admin/moderation/quiz-1/ban-author",
+			reviewErr:      fmt.Errorf("%w: 作成者が記録されていないクイズです", service.ErrInvalidReview),
+			expectedInput:  &service.ReviewInput{Action: service.ReviewBanAuthor, ReviewerID: "admin-1"},
+			expectedStatus: http.StatusBadRequest,
+			expectedAction: actionBanAuthor,
+			expectedTarget: "quiz-1",
+			expectedAllow:  true,
+		},
+		{
+			name:           "不明な操作",
+			identity:       testAdmin,
+			path:           "/admin/moderation/quiz-1/delete",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderationService := &MockModerationService{}
+			if tt.expectedInput != nil {
+				moderationService.On("Review", mock.Anything, strings.Split(tt.path, "/")[3], tt.expectedInput).Return(tt.result, tt.reviewErr)
+			}
+			recorder := &auditRecorder{}
+			srv := newModerationServer(moderationService, WithAuditLogger(recorder))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			var actor string
+			if tt.identity != nil {
+				req = withIdentity(req, tt.identity)
+				actor = tt.identity.UserID
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			moderationService.AssertExpectations(t)
+			if tt.expectedAction == "" {
+				assert.Empty(t, recorder.entries)
+				return
+			}
+			if assert.Len(t, recorder.entries, 1) {
+				entry := recorder.entries[0]
+				assert.Equal(t, string(tt.expectedAction), entry.Action)
+				assert.Equal(t, tt.expectedTarget, entry.Target)
+				assert.Equal(t, actor, entry.Actor)
+				assert.Equal(t, tt.expectedAllow, entry.Allowed)
+				assert.Equal(t, tt.expectedStatus, entry.Status)
+			}
+		})
+	}
+}
+
+func TestHandleReportQuiz(t *testing.T) {
+	tests := []struct {
+		name             string
+		identity         *auth.Identity
+		playerID         string
+		body             string
+		quiz             *models.Quiz
+		quizErr          error
+		reportErr        error
+		expectedReporter string
+		expectedStatus   int
+	}{
+		{
+			name:             "ログインした利用者の報告",
+			identity:         &auth.Identity{UserID: "user-1"},
+			body:             `{"reason":"不適切な画像"}`,
+			expectedReporter: "user-1",
+			expectedStatus:   http.StatusAccepted,
+		},
+		{
+			name:             "匿名のプレイヤーの報告",
+			playerID:         "player-abc",
+			body:             `{"reason":"不適切な画像"}`,
+			expectedReporter: "player:player-abc",
+			expectedStatus:   http.StatusAccepted,
+		},
+		{
+			name:             "同じ利用者からの2回目の報告",
+			identity:         &auth.Identity{UserID: "user-1"},
+			body:             `{"reason":"不適切な画像"}`,
+			reportErr:        service.ErrAlreadyReported,
+			expectedReporter: "user-1",
+			expectedStatus:   http.StatusConflict,
+		},
+		{
+			name:             "匿名の報告が上限に達している",
+			playerID:         "player-abc",
+			body:             `{"reason":"不適切な画像"}`,
+			reportErr:        service.ErrReportLimitReached,
+			expectedReporter: "player:player-abc",
+			expectedStatus:   http.StatusTooManyRequests,
+		},
+		{
+			name:             "理由なし",
+			identity:         &auth.Identity{UserID: "user-1"},
+			body:             `{"reason":""}`,
+			reportErr:        fmt.Errorf("%w: 理由が必要です", service.ErrInvalidReport),
+			expectedReporter: "user-1",
+			expectedStatus:   http.StatusBadRequest,
+		},
+		{
+			name:             "公開されていないクイズ",
+			identity:         &auth.Identity{UserID: "user-1"},
+			body:             `{"reason":"不適切な画像"}`,
+			reportErr:        storage.ErrQuizNotFound,
+			expectedReporter: "user-1",
+			expectedStatus:   http.StatusNotFound,
+		},
+		{
+			name:             "作成者による報告",
+			identity:         &auth.Identity{UserID: "author-1"},
+			body:             `{"reason":"不適切な画像"}`,
+			reportErr:        fmt.Errorf("%w: 自分のクイズは報告できません", service.ErrInvalidReport),
+			expectedReporter: "author-1",
+			expectedStatus:   http.StatusBadRequest,
+		},
+		{
+			name:           "閲覧できないクイズ",
+			identity:       &auth.Identity{UserID: "user-1"},
+			body:           `{"reason":"不適切な画像"}`,
+			quiz:           &models.Quiz{ID: "quiz-1", AuthorID: "author-1", Status: models.QuizFlagged},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "存在しないクイズ",
+			playerID:       "player-abc",
+			body:           `{"reason":"不適切な画像"}`,
+			quizErr:        fmt.Errorf("%w: quiz-1", storage.ErrQuizNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "不正なリクエスト",
+			identity:       &auth.Identity{UserID: "user-1"},
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quiz := tt.quiz
+			if quiz == nil && tt.quizErr == nil {
+				quiz = &models.Quiz{ID: "quiz-1", AuthorID: "author-1"}
+			}
+			quizService := &MockQuizService{}
+			quizService.On("GetQuiz", mock.Anything, "quiz-1").Return(quiz, tt.quizErr)
+			moderationService := &MockModerationService{}
+			moderationService.On("ReportQuiz", mock.Anything, "quiz-1", mock.MatchedBy(func(input *service.ReportInput) bool {
+				return input.ReporterID == tt.expectedReporter
+			})).Return(&models.Quiz{ID: "quiz-1"}, tt.reportErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/quizzes/quiz-1/report", strings.NewReader(tt.body))
+			if tt.identity != nil {
+				req = withIdentity(req, tt.identity)
+			}
+			if tt.playerID != "" {
+				req.Header.Set(playerIDHeader, tt.playerID)
+			}
+			rec := httptest.NewRecorder()
+			NewServer(quizService, WithModerationService(moderationService)).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedReporter == "" {
+				moderationService.AssertNotCalled(t, "ReportQuiz", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandleReportQuiz_Disabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/quizzes/quiz-1/report", strings.NewReader(`{"reason":"不適切"}`))
+	rec := httptest.NewRecorder()
+	NewServer(&MockQuizService{}).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
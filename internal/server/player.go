@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+)
+
+const (
+	// playerIDHeader はクライアントが匿名のプレイヤーIDを送るためのヘッダーです
+	playerIDHeader = "X-Player-ID"
+	// playerIDCookie は匿名のプレイヤーIDを保持するクッキーです
+	playerIDCookie = "player_id"
+	// playerIDCookieMaxAge はプレイヤーIDのクッキーの有効期間です
+	playerIDCookieMaxAge = 365 * 24 * time.Hour
+)
+
+// playerID はリクエストから匿名のプレイヤーIDを取得します
+// ヘッダー、クッキーの順に参照し、どちらもない場合は新しいIDを発行してクッキーに設定します
+func playerID(w http.ResponseWriter, r *http.Request) (string, error) {
+	if id := r.Header.Get(playerIDHeader); id != "" {
+		return id, service.ValidatePlayerID(id)
+	}
+	if cookie, err := r.Cookie(playerIDCookie); err == nil && service.ValidatePlayerID(cookie.Value) == nil {
+		return cookie.Value, nil
+	}
+
+	id := service.NewPlayerID()
+	http.SetCookie(w, &http.Cookie{
+		Name:     playerIDCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(playerIDCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id, nil
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/audit"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+// action は認可の対象となる操作です
+type action string
+
+const (
+	// actionDeleteAllQuizzes はすべてのクイズを削除する操作です
+	actionDeleteAllQuizzes action = "quizzes.delete_all"
+	// actionDeleteAnyQuiz は他の利用者が作成したクイズを削除する操作です
+	actionDeleteAnyQuiz action = "quizzes.delete_any"
+	// actionModerate はモデレーションの操作です（審査待ちのクイズの一覧など）
+	actionModerate action = "moderation.manage"
+	// actionApproveQuiz は審査待ちのクイズを公開する操作です
+	actionApproveQuiz action = "moderation.approve"
+	// actionRejectQuiz はクイズの公開を拒否する操作です
+	actionRejectQuiz action = "moderation.reject"
+	// actionBanAuthor は投稿者を利用停止にする操作です
+	actionBanAuthor action = "moderation.ban_author"
+)
+
+// policy は操作ごとに必要なロールを定義します
+// いずれかのロールを持つ呼び出し元のみ操作を許可します
+var policy = map[action][]string{
+	actionDeleteAllQuizzes: {auth.RoleAdmin},
+	actionDeleteAnyQuiz:    {auth.RoleAdmin},
+	actionModerate:         {auth.RoleAdmin},
+	actionApproveQuiz:      {auth.RoleAdmin},
+	actionRejectQuiz:       {auth.RoleAdmin},
+	actionBanAuthor:        {auth.RoleAdmin},
+}
+
+// WithAuditLogger は権限が必要な操作の記録先を設定します
+// 設定しない場合はアプリケーションのログに出力します
+func WithAuditLogger(logger audit.Logger) Option {
+	return func(s *Server) {
+		s.auditLogger = logger
+	}
+}
+
+// allows は呼び出し元が操作を許可されているかを返します
+// ポリシーに定義されていない操作は許可しません
+func allows(identity *auth.Identity, act action) bool {
+	for _, role := range policy[act] {
+		if identity.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuizOwner は呼び出し元がクイズの作成者かを返します
+// 作成者が記録されていないクイズは、誰の所有にもなりません
+func isQuizOwner(identity *auth.Identity, quiz *models.Quiz) bool {
+	return quiz.AuthorID != "" && quiz.AuthorID == identity.UserID
+}
+
+// canView は呼び出し元がクイズを閲覧できるかを返します
+// 審査で公開されていないクイズは、作成者とモデレーションの権限を持つ利用者のみ閲覧できます
+func canView(r *http.Request, quiz *models.Quiz) bool {
+	if quiz.Status.Public() {
+		return true
+	}
+	identity, ok := auth.FromContext(r.Context())
+	return ok && (isQuizOwner(identity, quiz) || allows(identity, actionModerate))
+}
+
+// requirePermission は操作に必要なロールを持つ呼び出し元のみハンドラーを実行します
+// 未認証の場合は 401、権限がない場合は 403 を返し、いずれの場合も監査ログに記録します
+func (s *Server) requirePermission(act action, next http.HandlerFunc) http.HandlerFunc {
+	return s.requirePermissionOn(act, "", next)
+}
+
+// requirePermissionOn は requirePermission と同じく認可を行い、監査ログに操作の対象として target を記録します
+// ハンドラーは setAuditTarget で記録する対象を変更できます
+func (s *Server) requirePermissionOn(act action, target string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := auth.FromContext(r.Context())
+		if !ok {
+			s.recordAudit(r, nil, act, target, false, http.StatusUnauthorized)
+			unauthorized(w, "認証が必要です")
+			return
+		}
+		if !allows(identity, act) {
+			logging.Warn("requirePermission: 権限がありません: user=%s, action=%s", identity.UserID, act)
+			s.recordAudit(r, identity, act, target, false, http.StatusForbidden)
+			http.Error(w, "この操作を行う権限がありません", http.StatusForbidden)
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK, target: target}
+		next(sw, r)
+		s.recordAudit(r, identity, act, sw.target, true, sw.status)
+	}
+}
+
+// setAuditTarget は requirePermission が監査ログに記録する操作の対象を変更します
+func setAuditTarget(w http.ResponseWriter, target string) {
+	if sw, ok := w.(*statusWriter); ok {
+		sw.target = target
+	}
+}
+
+// recordAudit は権限が必要な操作を監査ログに記録します
+func (s *Server) recordAudit(r *http.Request, identity *auth.Identity, act action, target string, allowed bool, status int) {
+	entry := &audit.Entry{
+		Time:       time.Now(),
+		Action:     string(act),
+		Target:     target,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    allowed,
+		Status:     status,
+	}
+	if identity != nil {
+		entry.Actor = identity.UserID
+	}
+	s.auditLogger.Record(r.Context(), entry)
+}
+
+// statusWriter は応答したステータスコードを記録する ResponseWriter です
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	// target は監査ログに記録する操作の対象です
+	target string
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
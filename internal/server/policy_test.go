@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/audit"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+)
+
+// auditRecorder は記録された監査ログを保持するテスト用の Logger です
+type auditRecorder struct {
+	mu      sync.Mutex
+	entries []*audit.Entry
+}
+
+func (r *auditRecorder) Record(ctx context.Context, entry *audit.Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name           string
+		identity       *auth.Identity
+		expectedStatus int
+		expectedActor  string
+		expectedAllow  bool
+	}{
+		{
+			name:           "未認証は 401",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "管理者ではない利用者は 403",
+			identity:       &auth.Identity{UserID: "user-1", Roles: []string{"editor"}},
+			expectedStatus: http.StatusForbidden,
+			expectedActor:  "user-1",
+		},
+		{
+			name:           "管理者は実行できる",
+			identity:       &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}},
+			expectedStatus: http.StatusOK,
+			expectedActor:  "admin-1",
+			expectedAllow:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			mockService.On("DeleteAllQuizzes", mock.Anything).Return(nil)
+			recorder := &auditRecorder{}
+			signer := newTestSigner(t)
+			srv := NewServer(mockService, WithVerifier(signer), WithAuditLogger(recorder))
+
+			req := httptest.NewRequest(http.MethodDelete, "/delete-all-quizzes", nil)
+			if tt.identity != nil {
+				authorize(t, req, signer, tt.identity)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedAllow {
+				mockService.AssertCalled(t, "DeleteAllQuizzes", mock.Anything)
+			} else {
+				mockService.AssertNotCalled(t, "DeleteAllQuizzes", mock.Anything)
+			}
+
+			// 拒否された呼び出しも含めて、1回の呼び出しにつき1件記録される
+			if assert.Len(t, recorder.entries, 1) {
+				entry := recorder.entries[0]
+				assert.Equal(t, string(actionDeleteAllQuizzes), entry.Action)
+				assert.Equal(t, tt.expectedActor, entry.Actor)
+				assert.Equal(t, tt.expectedAllow, entry.Allowed)
+				assert.Equal(t, tt.expectedStatus, entry.Status)
+				assert.Equal(t, http.MethodDelete, entry.Method)
+				assert.Equal(t, "/delete-all-quizzes", entry.Path)
+			}
+		})
+	}
+}
+
+func TestRequirePermission_RecordsHandlerStatus(t *testing.T) {
+	recorder := &auditRecorder{}
+	signer := newTestSigner(t)
+	srv := NewServer(&MockQuizService{}, WithVerifier(signer), WithAuditLogger(recorder))
+
+	// 許可された呼び出しでは、ハンドラーが返したステータスを記録する
+	req := httptest.NewRequest(http.MethodGet, "/delete-all-quizzes", nil)
+	authorize(t, req, signer, &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	if assert.Len(t, recorder.entries, 1) {
+		assert.True(t, recorder.entries[0].Allowed)
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.entries[0].Status)
+	}
+}
+
+func TestPolicyRequiresAdmin(t *testing.T) {
+	admin := &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}}
+	user := &auth.Identity{UserID: "user-1"}
+
+	for act := range policy {
+		assert.True(t, allows(admin, act), "admin should be allowed to %s", act)
+		assert.False(t, allows(user, act), "user should not be allowed to %s", act)
+	}
+	assert.False(t, allows(admin, action("undefined")), "undefined actions must be denied")
+}
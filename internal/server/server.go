@@ -1,32 +1,89 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/ai"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/audit"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
 )
 
 // Server はHTTPサーバーを表します
 type Server struct {
-	quizService service.QuizService
-	mux         *http.ServeMux
+	quizService    service.QuizService
+	verifier       auth.Verifier
+	auditLogger    audit.Logger
+	imageValidator service.ImageValidatorInterface
+	uploadService  service.UploadService
+	jobQueue       service.JobQueue
+	// moderationService は管理者による審査と、プレイヤーからの報告を扱います
+	moderationService service.ModerationService
+	mux               *http.ServeMux
 }
 
-// enableCORS はCORSを有効にするミドルウェアです
-func enableCORS(handler http.Handler) http.Handler {
+// MaxUploadSize はアップロードできる画像の最大サイズです
+const MaxUploadSize = 5 * 1024 * 1024 // 5MB
+
+// WithImageValidator はアップロードされた画像の検証に使用するバリデーターを設定します
+// 未設定の場合は service.DefaultAllowedImageTypes のうち、デコーダーが登録されている形式を受け付けます
+func WithImageValidator(validator service.ImageValidatorInterface) Option {
+	return func(s *Server) {
+		s.imageValidator = validator
+	}
+}
+
+// corsPolicy はルートごとの CORS とキャッシュの設定です
+type corsPolicy struct {
+	// methods は Access-Control-Allow-Methods の値です
+	methods string
+	// headers は Access-Control-Allow-Headers の値です
+	headers string
+	// expose は Access-Control-Expose-Headers の値です
+	expose string
+	// noStore はレスポンスをキャッシュさせないかどうかです
+	noStore bool
+}
+
+var (
+	// apiCORS はJSONを返すAPIのルートの設定です（レスポンスはキャッシュさせない）
+	apiCORS = corsPolicy{
+		methods: "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		headers: "Content-Type, Content-Range, Authorization, X-Requested-With, " + playerIDHeader,
+		expose:  "Content-Length, Content-Type",
+		noStore: true,
+	}
+	// imageCORS は画像を配信するルートの設定です（キャッシュの指定はハンドラーに任せる）
+	imageCORS = corsPolicy{
+		methods: "GET, HEAD, OPTIONS",
+		headers: "Range, If-None-Match, If-Range",
+		expose:  "Content-Length, Content-Type, Accept-Ranges, Content-Range, ETag",
+	}
+)
+
+// withCORS はCORSを有効にするミドルウェアです
+func withCORS(policy corsPolicy, handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// CORSヘッダーの設定
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		w.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type, Accept-Ranges, Content-Range")
-		w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, proxy-revalidate")
-		w.Header().Set("Pragma", "no-cache")
-		w.Header().Set("Expires", "0")
+		w.Header().Set("Access-Control-Allow-Methods", policy.methods)
+		w.Header().Set("Access-Control-Allow-Headers", policy.headers)
+		w.Header().Set("Access-Control-Expose-Headers", policy.expose)
+		if policy.noStore {
+			w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, proxy-revalidate")
+			w.Header().Set("Pragma", "no-cache")
+			w.Header().Set("Expires", "0")
+		}
 
 		// プリフライトリクエストの処理
 		if r.Method == "OPTIONS" {
@@ -40,30 +97,59 @@ func enableCORS(handler http.Handler) http.Handler {
 }
 
 // NewServer は新しいサーバーを作成します
-func NewServer(quizService service.QuizService) *Server {
+func NewServer(quizService service.QuizService, opts ...Option) *Server {
 	s := &Server{
 		quizService: quizService,
+		auditLogger: audit.NewLogger(),
 		mux:         http.NewServeMux(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.imageValidator == nil {
+		validator, err := service.NewImageValidator(MaxUploadSize, nil)
+		if err != nil {
+			// 既定の形式のデコーダーは常に登録されているため、ここには到達しない
+			panic(err)
+		}
+		s.imageValidator = validator
+	}
 	s.setupRoutes()
 	return s
 }
 
 // ServeHTTP はHTTPリクエストを処理します
+// CORS はルートごとに設定するため、ここでは認証のみ適用します
+// （プリフライトリクエストは Authorization ヘッダーを持たないため、認証されずにルートの CORS に届きます）
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// CORSミドルウェアを適用
-	handler := enableCORS(s.mux)
-	handler.ServeHTTP(w, r)
+	s.authenticate(s.mux).ServeHTTP(w, r)
+}
+
+// handleAPI はJSONを返すAPIのハンドラーをルーティングに登録します
+func (s *Server) handleAPI(pattern string, handler http.HandlerFunc) {
+	s.mux.Handle(pattern, withCORS(apiCORS, handler))
 }
 
 // setupRoutes はルーティングを設定します
 func (s *Server) setupRoutes() {
-	s.mux.HandleFunc("/health", s.handleHealth)
-	s.mux.HandleFunc("/quizzes", s.handleGetQuizList)
-	s.mux.HandleFunc("/quizzes/", s.handleGetQuiz)
-	s.mux.HandleFunc("/upload", s.handleUpload)
-	s.mux.HandleFunc("/verify-answer", s.handleVerifyAnswer)
-	s.mux.HandleFunc("/delete-all-quizzes", s.handleDeleteAllQuizzes)
+	s.handleAPI("/health", s.handleHealth)
+	s.handleAPI("/quizzes", s.handleGetQuizList)
+	s.handleAPI("/quizzes/", s.handleQuiz)
+	s.handleAPI("/upload", requireAuth(s.handleUpload))
+	if s.uploadService != nil {
+		s.handleAPI(uploadsPath, requireAuth(s.handleCreateUpload))
+		s.handleAPI(uploadsPath+"/", requireAuth(s.handleUploadSession))
+	}
+	if s.jobQueue != nil {
+		s.handleAPI(jobsPath, requireAuth(s.handleGetJob))
+	}
+	if s.moderationService != nil {
+		s.handleAPI(moderationPath, s.requirePermission(actionModerate, s.handleListModeration))
+		s.handleAPI(moderationPath+"/", s.handleReviewQuiz)
+	}
+	s.handleAPI("/verify-answer", s.handleVerifyAnswer)
+	s.handleAPI("/delete-all-quizzes", s.requirePermission(actionDeleteAllQuizzes, s.handleDeleteAllQuizzes))
+	s.mux.Handle(service.ImageProxyPrefix, withCORS(imageCORS, http.HandlerFunc(s.handleGetImage)))
 	logging.Info("routes: ルーティングを設定しました")
 }
 
@@ -71,6 +157,13 @@ func (s *Server) setupRoutes() {
 func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 	logging.Info("handleUpload: リクエストを受信")
 
+	// 投稿者の取得
+	identity, ok := auth.FromContext(r.Context())
+	if !ok {
+		unauthorized(w, "認証が必要です")
+		return
+	}
+
 	// マルチパートフォームの解析
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
 		logging.Error("handleUpload: フォームの解析に失敗: %v", err)
@@ -95,48 +188,68 @@ func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// おとりの数の取得（省略時はデフォルト）
+	decoyCount := 0
+	if v := r.FormValue("decoy_count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < service.MinDecoyCount || n > service.MaxDecoyCount {
+			logging.Error("handleUpload: 不正なおとりの数: %q", v)
+			http.Error(w, fmt.Sprintf("decoy_count は%dから%dの整数で指定してください", service.MinDecoyCount, service.MaxDecoyCount), http.StatusBadRequest)
+			return
+		}
+		decoyCount = n
+	}
+
+	// タグの取得（カンマ区切り、省略可）
+	var tags []string
+	if v := r.FormValue("tags"); v != "" {
+		tags = strings.Split(v, ",")
+	}
+
 	// 画像の検証と保存
-	validator := service.NewImageValidator(5 * 1024 * 1024) // 5MB
-	buf, err := validator.ValidateAndCopy(file, header.Filename)
+	buf, mimeType, err := s.imageValidator.ValidateAndCopy(file, header.Filename)
 	if err != nil {
 		logging.Error("handleUpload: 画像の検証に失敗: %v", err)
 		http.Error(w, fmt.Sprintf("画像の検証に失敗しました: %v", err), http.StatusBadRequest)
 		return
 	}
 
+	input := &service.CreateQuizInput{
+		ImageData:            buf.Bytes(),
+		ImageMimeType:        mimeType,
+		AuthorInterpretation: interpretation,
+		Title:                r.FormValue("title"),
+		DecoyCount:           decoyCount,
+		AuthorID:             identity.UserID,
+		AuthorName:           identity.DisplayName,
+		Tags:                 tags,
+	}
+
+	// 非同期が指定された場合は、AIによる生成を待たずにジョブのIDを返す
+	if s.jobQueue != nil && wantsAsync(r) {
+		s.enqueueQuiz(w, r, input)
+		return
+	}
+
 	// クイズの作成
-	quiz, err := s.quizService.CreateQuiz(r.Context(), buf.Bytes(), interpretation)
+	quiz, err := s.quizService.CreateQuiz(r.Context(), input)
 	if err != nil {
 		logging.Error("handleUpload: クイズの作成に失敗: %v", err)
-		http.Error(w, fmt.Sprintf("クイズの作成に失敗しました: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("クイズの作成に失敗しました: %v", err), quizErrorStatus(err))
 		return
 	}
 
-	// レスポンスの送信
-	w.Header().Set("Content-Type", "application/json")
-	response := struct {
-		ID                   string `json:"id"`
-		ImageURL             string `json:"image_url"`
-		CreatedAt            string `json:"created_at"`
-		AuthorInterpretation string `json:"author_interpretation"`
-		AIInterpretation     string `json:"ai_interpretation"`
-	}{
-		ID:                   quiz.ID,
-		CreatedAt:            quiz.CreatedAt.Format("2006-01-02 15:04:05"),
-		AuthorInterpretation: quiz.AuthorInterpretation,
-		AIInterpretation:     quiz.AIInterpretation,
-	}
-
 	// 画像URLの生成
-	imageURL, err := s.quizService.GetSignedImageURL(r.Context(), quiz.ImagePath)
+	imageURLs, err := s.imageURLs(r.Context(), quiz.Image())
 	if err != nil {
 		logging.Error("handleUpload: 画像URLの生成に失敗: %v", err)
 		http.Error(w, fmt.Sprintf("画像URLの生成に失敗しました: %v", err), http.StatusInternalServerError)
 		return
 	}
-	response.ImageURL = imageURL
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	// レスポンスの送信
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newAuthorQuizResponse(quiz, imageURLs)); err != nil {
 		logging.Error("handleUpload: レスポンスの送信に失敗: %v", err)
 		http.Error(w, "レスポンスの送信に失敗しました", http.StatusInternalServerError)
 		return
@@ -145,11 +258,105 @@ func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 	logging.Info("handleUpload: クイズの作成に成功: id=%s", quiz.ID)
 }
 
+// authorQuizResponse は投稿者に返すクイズの内容です
+// プレイヤー向けと異なり、どれが投稿者の解釈かを含みます
+type authorQuizResponse struct {
+	ID                   string                         `json:"id"`
+	ImageURL             string                         `json:"image_url"`
+	ImageURLs            map[models.ImageVariant]string `json:"image_urls"`
+	CreatedAt            string                         `json:"created_at"`
+	Title                string                         `json:"title"`
+	AuthorID             string                         `json:"author_id"`
+	AuthorName           string                         `json:"author_name"`
+	AuthorInterpretation string                         `json:"author_interpretation"`
+	AIInterpretation     string                         `json:"ai_interpretation"`
+	AIInterpretations    []string                       `json:"ai_interpretations"`
+	Tags                 []string                       `json:"tags"`
+	Difficulty           string                         `json:"difficulty"`
+	// Status は審査の状態です（flagged の場合は管理者の確認が終わるまで公開されません）
+	Status models.QuizStatus `json:"status"`
+}
+
+// newAuthorQuizResponse は投稿者に返すクイズの内容を作成します
+func newAuthorQuizResponse(quiz *models.Quiz, urls map[models.ImageVariant]string) *authorQuizResponse {
+	return &authorQuizResponse{
+		ID:                   quiz.ID,
+		ImageURL:             urls[models.ImageFull],
+		ImageURLs:            urls,
+		CreatedAt:            quiz.CreatedAt.Format("2006-01-02 15:04:05"),
+		Title:                quiz.Title,
+		AuthorID:             quiz.AuthorID,
+		AuthorName:           quiz.AuthorName,
+		AuthorInterpretation: quiz.AuthorInterpretation,
+		AIInterpretation:     quiz.AIInterpretation,
+		AIInterpretations:    quiz.Decoys(),
+		Tags:                 quiz.Tags,
+		Difficulty:           quiz.Difficulty(),
+		Status:               quizStatus(quiz),
+	}
+}
+
+// quizStatus はクイズの審査の状態を返します（審査の導入前のクイズは公開として扱います）
+func quizStatus(quiz *models.Quiz) models.QuizStatus {
+	if quiz.Status == "" {
+		return models.QuizApproved
+	}
+	return quiz.Status
+}
+
+// imageURLs はクイズの画像のすべての種類のURLを生成します
+// 派生画像のないクイズでは、すべての種類が元の画像のURLになります
+func (s *Server) imageURLs(ctx context.Context, image models.QuizImage) (map[models.ImageVariant]string, error) {
+	urls := make(map[models.ImageVariant]string, len(models.ImageVariants))
+	for _, variant := range models.ImageVariants {
+		url, err := s.quizService.GetSignedImageURL(ctx, image, variant)
+		if err != nil {
+			return nil, err
+		}
+		urls[variant] = url
+	}
+	return urls, nil
+}
+
+// handleQuiz は /quizzes/{id} 以下のリクエストを振り分けます
+func (s *Server) handleQuiz(w http.ResponseWriter, r *http.Request) {
+	quizID, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/quizzes/"), "/")
+	switch sub {
+	case "":
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			s.handleGetQuiz(w, r, quizID)
+		case http.MethodDelete:
+			s.handleDeleteQuiz(w, r, quizID)
+		case http.MethodPatch:
+			s.handleUpdateQuiz(w, r, quizID)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PATCH, DELETE")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case "stats":
+		s.handleGetQuizStats(w, r, quizID)
+	case "report":
+		s.handleReportQuiz(w, r, quizID)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
 // handleGetQuiz はクイズを取得するハンドラーです
-func (s *Server) handleGetQuiz(w http.ResponseWriter, r *http.Request) {
-	quizID := strings.TrimPrefix(r.URL.Path, "/quizzes/")
+func (s *Server) handleGetQuiz(w http.ResponseWriter, r *http.Request, quizID string) {
 	logging.Info("handleGetQuiz: クイズID=%s の取得を開始", quizID)
 
+	// セッションIDの取得（未指定の場合は新しいセッションを開始）
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		sessionID = service.NewSessionID()
+	} else if err := service.ValidateSessionID(sessionID); err != nil {
+		logging.Error("handleGetQuiz: 不正なセッションID: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// クイズの取得
 	quiz, err := s.quizService.GetQuiz(r.Context(), quizID)
 	if err != nil {
@@ -157,30 +364,43 @@ func (s *Server) handleGetQuiz(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("クイズの取得に失敗しました: %v", err), http.StatusNotFound)
 		return
 	}
+	if !canView(r, quiz) {
+		logging.Warn("handleGetQuiz: 公開されていないクイズです: quizID=%s, status=%s", quizID, quiz.Status)
+		http.Error(w, "クイズが見つかりません", http.StatusNotFound)
+		return
+	}
 	logging.Debug("handleGetQuiz: クイズ取得成功: imagePath=%s", quiz.ImagePath)
 
 	// 画像URLの生成
-	imageURL, err := s.quizService.GetSignedImageURL(r.Context(), quiz.ImagePath)
+	imageURLs, err := s.imageURLs(r.Context(), quiz.Image())
 	if err != nil {
 		logging.Error("handleGetQuiz: 画像URLの生成に失敗: %v", err)
 		http.Error(w, fmt.Sprintf("画像URLの生成に失敗しました: %v", err), http.StatusInternalServerError)
 		return
 	}
-	logging.Debug("handleGetQuiz: 画像URL生成成功: URL=%s", imageURL)
+	logging.Debug("handleGetQuiz: 画像URL生成成功: URLs=%v", imageURLs)
 
-	// レスポンスの構築
+	// レスポンスの構築（どの選択肢が投稿者の解釈かは含めない）
 	response := struct {
-		ID                   string `json:"id"`
-		ImageURL             string `json:"image_url"`
-		CreatedAt            string `json:"created_at"`
-		AuthorInterpretation string `json:"author_interpretation"`
-		AIInterpretation     string `json:"ai_interpretation"`
+		ID         string                         `json:"id"`
+		Title      string                         `json:"title"`
+		AuthorName string                         `json:"author_name"`
+		ImageURL   string                         `json:"image_url"`
+		ImageURLs  map[models.ImageVariant]string `json:"image_urls"`
+		CreatedAt  string                         `json:"created_at"`
+		SessionID  string                         `json:"session_id"`
+		Options    []models.QuizOption            `json:"options"`
+		Difficulty string                         `json:"difficulty"`
 	}{
-		ID:                   quiz.ID,
-		ImageURL:             imageURL,
-		CreatedAt:            quiz.CreatedAt.Format("2006-01-02 15:04:05"),
-		AuthorInterpretation: quiz.AuthorInterpretation,
-		AIInterpretation:     quiz.AIInterpretation,
+		ID:         quiz.ID,
+		Title:      quiz.Title,
+		AuthorName: quiz.AuthorName,
+		ImageURL:   imageURLs[models.ImageFull],
+		ImageURLs:  imageURLs,
+		CreatedAt:  quiz.CreatedAt.Format("2006-01-02 15:04:05"),
+		SessionID:  sessionID,
+		Options:    s.quizService.GetOptions(quiz, sessionID),
+		Difficulty: quiz.Difficulty(),
 	}
 
 	// レスポンスの送信
@@ -194,6 +414,141 @@ func (s *Server) handleGetQuiz(w http.ResponseWriter, r *http.Request) {
 	logging.Info("handleGetQuiz: レスポンス送信完了: quizID=%s", quizID)
 }
 
+// handleUpdateQuiz はクイズの作成者が投稿者の解釈を編集するハンドラーです
+// regenerate_ai が true の場合は、おとりの解釈も生成し直します
+func (s *Server) handleUpdateQuiz(w http.ResponseWriter, r *http.Request, quizID string) {
+	logging.Info("handleUpdateQuiz: クイズID=%s の更新を開始", quizID)
+
+	identity, ok := auth.FromContext(r.Context())
+	if !ok {
+		unauthorized(w, "認証が必要です")
+		return
+	}
+
+	// リクエストボディの解析
+	var request struct {
+		AuthorInterpretation *string `json:"author_interpretation"`
+		Title                *string `json:"title"`
+		RegenerateAI         bool    `json:"regenerate_ai"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logging.Error("handleUpdateQuiz: リクエストの解析に失敗: %v", err)
+		http.Error(w, "リクエストの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+	if request.AuthorInterpretation == nil && request.Title == nil && !request.RegenerateAI {
+		http.Error(w, "author_interpretation、title、regenerate_ai のいずれかを指定してください", http.StatusBadRequest)
+		return
+	}
+	if request.AuthorInterpretation != nil && *request.AuthorInterpretation == "" {
+		http.Error(w, "投稿者の解釈が必要です", http.StatusBadRequest)
+		return
+	}
+
+	// 作成者の確認
+	quiz, err := s.quizService.GetQuiz(r.Context(), quizID)
+	if err != nil {
+		logging.Error("handleUpdateQuiz: クイズの取得に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("クイズの取得に失敗しました: %v", err), http.StatusNotFound)
+		return
+	}
+	if !isQuizOwner(identity, quiz) {
+		logging.Warn("handleUpdateQuiz: 作成者ではありません: user=%s, quizID=%s", identity.UserID, quizID)
+		http.Error(w, "クイズの作成者のみ編集できます", http.StatusForbidden)
+		return
+	}
+
+	// クイズの更新
+	quiz, err = s.quizService.UpdateQuiz(r.Context(), quizID, &service.UpdateQuizInput{
+		AuthorInterpretation: request.AuthorInterpretation,
+		Title:                request.Title,
+		RegenerateDecoys:     request.RegenerateAI,
+	})
+	if err != nil {
+		logging.Error("handleUpdateQuiz: クイズの更新に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("クイズの更新に失敗しました: %v", err), quizErrorStatus(err))
+		return
+	}
+
+	imageURLs, err := s.imageURLs(r.Context(), quiz.Image())
+	if err != nil {
+		logging.Error("handleUpdateQuiz: 画像URLの生成に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("画像URLの生成に失敗しました: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newAuthorQuizResponse(quiz, imageURLs)); err != nil {
+		logging.Error("handleUpdateQuiz: レスポンスの送信に失敗: %v", err)
+		http.Error(w, "レスポンスの送信に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	logging.Info("handleUpdateQuiz: クイズの更新に成功: id=%s", quizID)
+}
+
+// handleDeleteQuiz はクイズを削除するハンドラーです
+// クイズの作成者のほか、actionDeleteAnyQuiz を許可された利用者も削除でき、その場合は監査ログに記録します
+func (s *Server) handleDeleteQuiz(w http.ResponseWriter, r *http.Request, quizID string) {
+	logging.Info("handleDeleteQuiz: クイズID=%s の削除を開始", quizID)
+
+	identity, ok := auth.FromContext(r.Context())
+	if !ok {
+		unauthorized(w, "認証が必要です")
+		return
+	}
+
+	quiz, err := s.quizService.GetQuiz(r.Context(), quizID)
+	if err != nil {
+		logging.Error("handleDeleteQuiz: クイズの取得に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("クイズの取得に失敗しました: %v", err), http.StatusNotFound)
+		return
+	}
+
+	// 作成者以外による削除は権限が必要な操作として扱う
+	if isQuizOwner(identity, quiz) {
+		s.deleteQuiz(w, r, quizID)
+		return
+	}
+	s.requirePermissionOn(actionDeleteAnyQuiz, quizID, func(w http.ResponseWriter, r *http.Request) {
+		s.deleteQuiz(w, r, quizID)
+	})(w, r)
+}
+
+// deleteQuiz はクイズを削除し、結果を返します
+func (s *Server) deleteQuiz(w http.ResponseWriter, r *http.Request, quizID string) {
+	if err := s.quizService.DeleteQuiz(r.Context(), quizID); err != nil {
+		logging.Error("handleDeleteQuiz: クイズの削除に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("クイズの削除に失敗しました: %v", err), quizErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "クイズを削除しました",
+	})
+	logging.Info("handleDeleteQuiz: クイズの削除に成功: id=%s", quizID)
+}
+
+// quizErrorStatus はクイズ操作のエラーに対応するステータスコードを返します
+func quizErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrQuizNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidInterpretation) || errors.Is(err, service.ErrInvalidTag) || errors.Is(err, service.ErrInvalidTitle) || errors.Is(err, service.ErrInvalidImage):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrContentRejected):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrAuthorBanned):
+		return http.StatusForbidden
+	case errors.Is(err, ai.ErrInvalidOutput):
+		return http.StatusBadGateway
+	case errors.Is(err, ai.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // handleHealth はヘルスチェックを処理します
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -206,35 +561,53 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status":"ok"}`))
 }
 
-// handleGetQuizList はクイズ一覧を取得するハンドラーです
+// handleGetQuizList はクイズ一覧を1ページ分取得するハンドラーです
+// 続きのページは、レスポンスの next_cursor を cursor に指定して取得します
 func (s *Server) handleGetQuizList(w http.ResponseWriter, r *http.Request) {
 	logging.Info("handleGetQuizList: リクエストを受信")
 
 	// キャッシュ制御の設定
 	w.Header().Set("Cache-Control", "max-age=15")
 
+	query, err := parseQuizListQuery(r)
+	if err != nil {
+		logging.Error("handleGetQuizList: 不正な検索条件: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// クイズ一覧の取得
-	quizzes, err := s.quizService.GetQuizList(r.Context())
+	page, err := s.quizService.GetQuizList(r.Context(), query)
 	if err != nil {
 		logging.Error("handleGetQuizList: クイズ一覧の取得に失敗: %v", err)
-		http.Error(w, fmt.Sprintf("クイズ一覧の取得に失敗しました: %v", err), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("クイズ一覧の取得に失敗しました: %v", err), status)
 		return
 	}
 
 	// レスポンスの構築
-	response := make([]struct {
-		ID        string `json:"id"`
-		CreatedAt string `json:"created_at"`
-	}, len(quizzes))
-
-	for i, quiz := range quizzes {
-		response[i] = struct {
-			ID        string `json:"id"`
-			CreatedAt string `json:"created_at"`
-		}{
-			ID:        quiz.ID,
-			CreatedAt: quiz.CreatedAt.Format("2006-01-02 15:04:05"),
+	response := struct {
+		Quizzes    []*models.QuizListResponse `json:"quizzes"`
+		NextCursor string                     `json:"next_cursor,omitempty"`
+	}{
+		Quizzes:    make([]*models.QuizListResponse, len(page.Quizzes)),
+		NextCursor: page.NextCursor,
+	}
+	for i, summary := range page.Quizzes {
+		// 画像のパスを持たない古いインデックスのエントリでは、サムネイルを省略する
+		var thumbnailURL string
+		if summary.ImagePath != "" {
+			thumbnailURL, err = s.quizService.GetSignedImageURL(r.Context(), summary.Image(), models.ImageThumbnail)
+			if err != nil {
+				logging.Error("handleGetQuizList: 画像URLの生成に失敗: %v", err)
+				http.Error(w, fmt.Sprintf("画像URLの生成に失敗しました: %v", err), http.StatusInternalServerError)
+				return
+			}
 		}
+		response.Quizzes[i] = models.NewQuizListResponse(summary, thumbnailURL)
 	}
 
 	// レスポンスの送信
@@ -245,7 +618,61 @@ func (s *Server) handleGetQuizList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logging.Info("handleGetQuizList: クイズ一覧の送信完了: count=%d", len(quizzes))
+	logging.Info("handleGetQuizList: クイズ一覧の送信完了: count=%d", len(page.Quizzes))
+}
+
+// parseQuizListQuery はクエリパラメータからクイズ一覧の取得条件を作成します
+// from・to は RFC 3339 形式か日付（2006-01-02）で指定し、日付の to はその日の終わりまでを含みます
+func parseQuizListQuery(r *http.Request) (*models.QuizListQuery, error) {
+	params := r.URL.Query()
+	query := &models.QuizListQuery{
+		AuthorID: params.Get("author"),
+		Tag:      params.Get("tag"),
+		Cursor:   params.Get("cursor"),
+	}
+
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > service.MaxPageSize {
+			return nil, fmt.Errorf("limit は1から%dの整数で指定してください", service.MaxPageSize)
+		}
+		query.Limit = n
+	}
+
+	switch order := models.SortOrder(params.Get("order")); order {
+	case "", models.SortNewestFirst, models.SortOldestFirst:
+		query.Order = order
+	default:
+		return nil, fmt.Errorf("order は %s または %s で指定してください", models.SortNewestFirst, models.SortOldestFirst)
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseListTime(params.Get("from"), false); err != nil {
+		return nil, fmt.Errorf("from の形式が不正です: %w", err)
+	}
+	if query.CreatedTo, err = parseListTime(params.Get("to"), true); err != nil {
+		return nil, fmt.Errorf("to の形式が不正です: %w", err)
+	}
+	return query, nil
+}
+
+// parseListTime は日時または日付を解析します（空の場合はゼロ値）
+// endOfDay が true の場合、日付のみの指定は翌日の0時として扱います
+func parseListTime(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
 }
 
 // handleVerifyAnswer は解答を検証するハンドラーです
@@ -254,14 +681,28 @@ func (s *Server) handleVerifyAnswer(w http.ResponseWriter, r *http.Request) {
 
 	// リクエストの解析
 	var request struct {
-		QuizID                 string `json:"quiz_id"`
-		SelectedInterpretation string `json:"selected_interpretation"`
+		QuizID    string `json:"quiz_id"`
+		SessionID string `json:"session_id"`
+		OptionID  string `json:"option_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		logging.Error("handleVerifyAnswer: リクエストの解析に失敗: %v", err)
 		http.Error(w, "リクエストの解析に失敗しました", http.StatusBadRequest)
 		return
 	}
+	if request.OptionID == "" {
+		logging.Error("handleVerifyAnswer: 選択肢IDが空です")
+		http.Error(w, "option_id が必要です", http.StatusBadRequest)
+		return
+	}
+
+	// 匿名のプレイヤーIDの取得
+	player, err := playerID(w, r)
+	if err != nil {
+		logging.Error("handleVerifyAnswer: 不正なプレイヤーID: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// クイズの取得
 	quiz, err := s.quizService.GetQuiz(r.Context(), request.QuizID)
@@ -270,15 +711,36 @@ func (s *Server) handleVerifyAnswer(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("クイズの取得に失敗しました: %v", err), http.StatusNotFound)
 		return
 	}
+	if !canView(r, quiz) {
+		logging.Warn("handleVerifyAnswer: 公開されていないクイズです: quizID=%s, status=%s", request.QuizID, quiz.Status)
+		http.Error(w, "クイズが見つかりません", http.StatusNotFound)
+		return
+	}
 
-	// 解答の検証
-	isCorrect := s.quizService.VerifyAnswer(quiz, request.SelectedInterpretation)
+	// 解答の検証と記録
+	answer, err := s.quizService.SubmitAnswer(r.Context(), quiz, &service.SubmitAnswerInput{
+		SessionID: request.SessionID,
+		OptionID:  request.OptionID,
+		PlayerID:  player,
+	})
+	if err != nil {
+		logging.Error("handleVerifyAnswer: 解答の検証に失敗: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUnknownOption) || errors.Is(err, service.ErrInvalidSessionID) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("解答の検証に失敗しました: %v", err), status)
+		return
+	}
+	isCorrect := answer.IsCorrect
 
-	// レスポンスの送信
+	// レスポンスの送信（回答後は正解の選択肢を明かす）
 	response := struct {
-		IsCorrect bool `json:"is_correct"`
+		IsCorrect       bool   `json:"is_correct"`
+		CorrectOptionID string `json:"correct_option_id"`
 	}{
-		IsCorrect: isCorrect,
+		IsCorrect:       isCorrect,
+		CorrectOptionID: s.quizService.CorrectOptionID(quiz, request.SessionID),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -291,6 +753,68 @@ func (s *Server) handleVerifyAnswer(w http.ResponseWriter, r *http.Request) {
 	logging.Info("handleVerifyAnswer: 解答検証完了: quizID=%s, isCorrect=%v", request.QuizID, isCorrect)
 }
 
+// handleGetQuizStats はクイズの回答の集計を返すハンドラーです
+func (s *Server) handleGetQuizStats(w http.ResponseWriter, r *http.Request, quizID string) {
+	logging.Info("handleGetQuizStats: クイズID=%s の集計を取得", quizID)
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// クイズの存在確認
+	quiz, err := s.quizService.GetQuiz(r.Context(), quizID)
+	if err != nil {
+		logging.Error("handleGetQuizStats: クイズの取得に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("クイズの取得に失敗しました: %v", err), http.StatusNotFound)
+		return
+	}
+	if !canView(r, quiz) {
+		logging.Warn("handleGetQuizStats: 公開されていないクイズです: quizID=%s, status=%s", quizID, quiz.Status)
+		http.Error(w, "クイズが見つかりません", http.StatusNotFound)
+		return
+	}
+
+	stats, err := s.quizService.GetQuizStats(r.Context(), quizID)
+	if err != nil {
+		logging.Error("handleGetQuizStats: 集計の取得に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("集計の取得に失敗しました: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// レスポンスの構築（プレイヤーIDは含めない）
+	decoyPicks := stats.DecoyPicks
+	if decoyPicks == nil {
+		decoyPicks = []int{}
+	}
+	response := struct {
+		QuizID          string  `json:"quiz_id"`
+		Attempts        int     `json:"attempts"`
+		CorrectAttempts int     `json:"correct_attempts"`
+		FooledAttempts  int     `json:"fooled_attempts"`
+		Players         int     `json:"players"`
+		FooledPlayers   int     `json:"fooled_players"`
+		FooledRate      float64 `json:"fooled_rate"`
+		DecoyPicks      []int   `json:"decoy_picks"`
+	}{
+		QuizID:          quizID,
+		Attempts:        stats.Attempts,
+		CorrectAttempts: stats.CorrectAttempts,
+		FooledAttempts:  stats.FooledAttempts(),
+		Players:         stats.PlayerCount,
+		FooledPlayers:   stats.FooledPlayerCount,
+		FooledRate:      stats.FooledRate(),
+		DecoyPicks:      decoyPicks,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.Error("handleGetQuizStats: レスポンスの送信に失敗: %v", err)
+		http.Error(w, "レスポンスの送信に失敗しました", http.StatusInternalServerError)
+		return
+	}
+}
+
 // handleDeleteAllQuizzes は全てのクイズを削除するハンドラーです
 func (s *Server) handleDeleteAllQuizzes(w http.ResponseWriter, r *http.Request) {
 	logging.Info("handleDeleteAllQuizzes: リクエストを受信")
@@ -5,6 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -13,8 +18,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/ai"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
 )
 
 func init() {
@@ -27,8 +36,8 @@ type MockQuizService struct {
 	mock.Mock
 }
 
-func (m *MockQuizService) CreateQuiz(ctx context.Context, imageData []byte, authorInterpretation string) (*models.Quiz, error) {
-	args := m.Called(ctx, imageData, authorInterpretation)
+func (m *MockQuizService) CreateQuiz(ctx context.Context, input *service.CreateQuizInput) (*models.Quiz, error) {
+	args := m.Called(ctx, input)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -43,24 +52,85 @@ func (m *MockQuizService) GetQuiz(ctx context.Context, quizID string) (*models.Q
 	return args.Get(0).(*models.Quiz), args.Error(1)
 }
 
-func (m *MockQuizService) GetRandomizedInterpretations(quiz *models.Quiz) []string {
-	args := m.Called(quiz)
-	return args.Get(0).([]string)
+func (m *MockQuizService) UpdateQuiz(ctx context.Context, quizID string, input *service.UpdateQuizInput) (*models.Quiz, error) {
+	args := m.Called(ctx, quizID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Quiz), args.Error(1)
 }
 
-func (m *MockQuizService) VerifyAnswer(quiz *models.Quiz, selectedInterpretation string) bool {
-	args := m.Called(quiz, selectedInterpretation)
-	return args.Bool(0)
+func (m *MockQuizService) DeleteQuiz(ctx context.Context, quizID string) error {
+	args := m.Called(ctx, quizID)
+	return args.Error(0)
 }
 
-func (m *MockQuizService) GetSignedImageURL(ctx context.Context, imagePath string) (string, error) {
-	args := m.Called(ctx, imagePath)
+func (m *MockQuizService) GetOptions(quiz *models.Quiz, sessionID string) []models.QuizOption {
+	args := m.Called(quiz, sessionID)
+	return args.Get(0).([]models.QuizOption)
+}
+
+func (m *MockQuizService) VerifyAnswer(quiz *models.Quiz, sessionID, optionID string) (bool, error) {
+	args := m.Called(quiz, sessionID, optionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockQuizService) CorrectOptionID(quiz *models.Quiz, sessionID string) string {
+	args := m.Called(quiz, sessionID)
+	return args.String(0)
+}
+
+func (m *MockQuizService) SubmitAnswer(ctx context.Context, quiz *models.Quiz, input *service.SubmitAnswerInput) (*models.Answer, error) {
+	args := m.Called(ctx, quiz, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Answer), args.Error(1)
+}
+
+func (m *MockQuizService) GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error) {
+	args := m.Called(ctx, quizID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuizStats), args.Error(1)
+}
+
+func (m *MockQuizService) GetSignedImageURL(ctx context.Context, image models.QuizImage, variant models.ImageVariant) (string, error) {
+	args := m.Called(ctx, image, variant)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockQuizService) GetQuizList(ctx context.Context) ([]*models.Quiz, error) {
-	args := m.Called(ctx)
-	return args.Get(0).([]*models.Quiz), args.Error(1)
+func (m *MockQuizService) GetImageQuiz(ctx context.Context, quizID, imagePath string) (*models.Quiz, error) {
+	args := m.Called(ctx, quizID, imagePath)
+	if quiz := args.Get(0); quiz != nil {
+		return quiz.(*models.Quiz), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockQuizService) StatImage(ctx context.Context, imagePath string) (*models.ImageInfo, error) {
+	args := m.Called(ctx, imagePath)
+	if info := args.Get(0); info != nil {
+		return info.(*models.ImageInfo), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockQuizService) OpenImage(ctx context.Context, imagePath string, offset, length int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, imagePath, offset, length)
+	if reader := args.Get(0); reader != nil {
+		return reader.(io.ReadCloser), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockQuizService) GetQuizList(ctx context.Context, query *models.QuizListQuery) (*models.QuizPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuizPage), args.Error(1)
 }
 
 func (m *MockQuizService) DeleteAllQuizzes(ctx context.Context) error {
@@ -80,8 +150,10 @@ func TestHandleUpload(t *testing.T) {
 
 	// モックのサービスを設定
 	mockService := &MockQuizService{}
-	mockService.On("CreateQuiz", mock.Anything, mock.Anything, mock.Anything).Return(mockQuiz, nil)
-	mockService.On("GetSignedImageURL", mock.Anything, mockQuiz.ImagePath).Return("https://storage.example.com/test-image.jpg", nil)
+	mockService.On("CreateQuiz", mock.Anything, mock.MatchedBy(func(input *service.CreateQuizInput) bool {
+		return input.AuthorID == "user-1"
+	})).Return(mockQuiz, nil)
+	mockService.On("GetSignedImageURL", mock.Anything, mockQuiz.Image(), mock.Anything).Return("https://storage.example.com/test-image.jpg", nil)
 
 	// ハンドラーを作成
 	handler := NewServer(mockService)
@@ -141,7 +213,7 @@ func TestHandleUpload(t *testing.T) {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	rec := httptest.NewRecorder()
-	handler.handleUpload(rec, req)
+	handler.handleUpload(rec, withIdentity(req, &auth.Identity{UserID: "user-1"}))
 
 	if rec.Code != http.StatusOK {
 		t.Errorf("期待するステータスコード %d に対して、%d が返されました", http.StatusOK, rec.Code)
@@ -163,59 +235,463 @@ func TestHandleUpload(t *testing.T) {
 	}
 }
 
+func TestHandleUpload_DecoyCount(t *testing.T) {
+	tests := []struct {
+		name           string
+		decoyCount     string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "省略時は既定値", decoyCount: "", expectedStatus: http.StatusOK, expectedCount: 0},
+		{name: "おとり3つ", decoyCount: "3", expectedStatus: http.StatusOK, expectedCount: 3},
+		{name: "上限を超える", decoyCount: "6", expectedStatus: http.StatusBadRequest},
+		{name: "0は不正", decoyCount: "0", expectedStatus: http.StatusBadRequest},
+		{name: "数値でない", decoyCount: "many", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			mockService.On("CreateQuiz", mock.Anything, mock.MatchedBy(func(input *service.CreateQuizInput) bool {
+				return input.DecoyCount == tt.expectedCount
+			})).Return(&models.Quiz{ID: "test-quiz", ImagePath: "test-image.png"}, nil)
+			mockService.On("GetSignedImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://storage.example.com/test-image.png", nil)
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("file", "test.png")
+			if err != nil {
+				t.Fatalf("フォームファイルの作成に失敗: %v", err)
+			}
+			part.Write(testPNG(t))
+			writer.WriteField("interpretation", "投稿者の解釈")
+			if tt.decoyCount != "" {
+				writer.WriteField("decoy_count", tt.decoyCount)
+			}
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+			NewServer(mockService).handleUpload(rec, withIdentity(req, &auth.Identity{UserID: "user-1"}))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				mockService.AssertNotCalled(t, "CreateQuiz", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandleUpload_Moderation(t *testing.T) {
+	tests := []struct {
+		name           string
+		quiz           *models.Quiz
+		createErr      error
+		expectedStatus int
+		expectedQuiz   models.QuizStatus
+	}{
+		{
+			name:           "公開",
+			quiz:           &models.Quiz{ID: "test-quiz", ImagePath: "test-image.png", Status: models.QuizApproved},
+			expectedStatus: http.StatusOK,
+			expectedQuiz:   models.QuizApproved,
+		},
+		{
+			name:           "審査で保留",
+			quiz:           &models.Quiz{ID: "test-quiz", ImagePath: "test-image.png", Status: models.QuizFlagged},
+			expectedStatus: http.StatusOK,
+			expectedQuiz:   models.QuizFlagged,
+		},
+		{
+			name:           "審査で拒否",
+			createErr:      fmt.Errorf("%w: 他者を傷つける表現", service.ErrContentRejected),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "AIの出力が検証に通らない",
+			createErr:      fmt.Errorf("AIによる解釈の生成に失敗: %w", &ai.ValidationError{Attempts: 3, Violations: []ai.Violation{ai.ViolationRefusal}}),
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name:           "AIサービスが一時的に利用できない",
+			createErr:      fmt.Errorf("AIによる解釈の生成に失敗: %w: %w", ai.ErrUnavailable, ai.ErrCircuitOpen),
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			mockService.On("CreateQuiz", mock.Anything, mock.Anything).Return(tt.quiz, tt.createErr)
+			mockService.On("GetSignedImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://storage.example.com/test-image.png", nil)
+
+			rec := httptest.NewRecorder()
+			NewServer(mockService).handleUpload(rec, newUploadRequest(t, "/upload"))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response struct {
+				Status models.QuizStatus `json:"status"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("レスポンスのデコードに失敗: %v", err)
+			}
+			assert.Equal(t, tt.expectedQuiz, response.Status)
+		})
+	}
+}
+
+func TestHandleUpload_AllowedImageTypes(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.White}), nil); err != nil {
+		t.Fatalf("GIFの生成に失敗: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		allowedTypes   []string
+		filename       string
+		data           []byte
+		expectedStatus int
+		expectedType   string
+	}{
+		{name: "既定ではGIFを受け付ける", filename: "anim.gif", data: gifData.Bytes(), expectedStatus: http.StatusOK, expectedType: "image/gif"},
+		{name: "設定した形式のみ受け付ける", allowedTypes: []string{"image/jpeg"}, filename: "test.png", data: testPNG(t), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			mockService.On("CreateQuiz", mock.Anything, mock.MatchedBy(func(input *service.CreateQuizInput) bool {
+				return input.ImageMimeType == tt.expectedType
+			})).Return(&models.Quiz{ID: "test-quiz", ImagePath: "test-image.jpg"}, nil)
+			mockService.On("GetSignedImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://storage.example.com/test-image.jpg", nil)
+
+			validator, err := service.NewImageValidator(MaxUploadSize, tt.allowedTypes)
+			if err != nil {
+				t.Fatalf("NewImageValidator failed: %v", err)
+			}
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("file", tt.filename)
+			if err != nil {
+				t.Fatalf("フォームファイルの作成に失敗: %v", err)
+			}
+			part.Write(tt.data)
+			writer.WriteField("interpretation", "投稿者の解釈")
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+			NewServer(mockService, WithImageValidator(validator)).handleUpload(rec, withIdentity(req, &auth.Identity{UserID: "user-1"}))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				mockService.AssertNotCalled(t, "CreateQuiz", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// testPNG はテスト用の小さなPNG画像を生成します
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("PNGの生成に失敗: %v", err)
+	}
+	return buf.Bytes()
+}
+
 func TestHandleGetQuiz(t *testing.T) {
 	// モックの設定
 	mockService := &MockQuizService{}
 	mockService.On("GetQuiz", mock.Anything, "test-quiz").Return(&models.Quiz{
 		ID:                   "test-quiz",
 		ImagePath:            "/images/test.jpg",
+		ImageVariants:        []models.ImageVariant{models.ImageThumbnail, models.ImageMedium},
 		AuthorInterpretation: "投稿者の解釈",
 		AIInterpretation:     "AIの解釈",
 		CreatedAt:            time.Now(),
 	}, nil)
-	mockService.On("GetRandomizedInterpretations", mock.Anything).Return([]string{"投稿者の解釈", "AIの解釈"})
-	mockService.On("GetSignedImageURL", mock.Anything, "/images/test.jpg").Return("https://example.com/test.jpg", nil)
+	mockService.On("GetOptions", mock.Anything, "session-a").Return([]models.QuizOption{
+		{ID: "option-1", Text: "AIの解釈"},
+		{ID: "option-2", Text: "投稿者の解釈"},
+	})
+	for _, variant := range models.ImageVariants {
+		mockService.On("GetSignedImageURL", mock.Anything, mock.MatchedBy(func(image models.QuizImage) bool {
+			return image.Path == "/images/test.jpg" && len(image.Variants) == 2
+		}), variant).Return("https://example.com/test_"+string(variant)+".jpg", nil)
+	}
 
 	srv := NewServer(mockService)
 
 	// リクエストの作成
-	req := httptest.NewRequest("GET", "/quizzes/test-quiz", nil)
+	req := httptest.NewRequest("GET", "/quizzes/test-quiz?session_id=session-a", nil)
 	rec := httptest.NewRecorder()
 
 	// ハンドラーの実行
-	srv.handleGetQuiz(rec, req)
+	srv.ServeHTTP(rec, req)
 
 	// レスポンスの検証
 	if rec.Code != http.StatusOK {
 		t.Errorf("expected status code %d, got %d", http.StatusOK, rec.Code)
 	}
 
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Errorf("レスポンスのデコードに失敗: %v", err)
+	}
+
+	assert.JSONEq(t, `"test-quiz"`, string(response["id"]))
+	assert.JSONEq(t, `"session-a"`, string(response["session_id"]))
+	assert.JSONEq(t, `[{"id":"option-1","text":"AIの解釈"},{"id":"option-2","text":"投稿者の解釈"}]`, string(response["options"]))
+	assert.JSONEq(t, `"https://example.com/test_full.jpg"`, string(response["image_url"]))
+	assert.JSONEq(t, `{
+		"thumbnail": "https://example.com/test_thumbnail.jpg",
+		"medium": "https://example.com/test_medium.jpg",
+		"full": "https://example.com/test_full.jpg"
+	}`, string(response["image_urls"]))
+
+	// どれが投稿者の解釈かはレスポンスに含めない
+	for _, key := range []string{"author_interpretation", "ai_interpretation", "ai_interpretations"} {
+		if _, ok := response[key]; ok {
+			t.Errorf("レスポンスに %q が含まれています", key)
+		}
+	}
+}
+
+func TestHandleGetQuiz_StartsNewSession(t *testing.T) {
+	mockService := &MockQuizService{}
+	mockService.On("GetQuiz", mock.Anything, "test-quiz").Return(&models.Quiz{ID: "test-quiz", ImagePath: "/images/test.jpg"}, nil)
+	mockService.On("GetOptions", mock.Anything, mock.Anything).Return([]models.QuizOption{})
+	mockService.On("GetSignedImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://example.com/test.jpg", nil)
+
+	rec := httptest.NewRecorder()
+	NewServer(mockService).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/test-quiz", nil))
+
 	var response struct {
-		ID                   string `json:"id"`
-		ImageURL             string `json:"image_url"`
-		CreatedAt            string `json:"created_at"`
-		AuthorInterpretation string `json:"author_interpretation"`
-		AIInterpretation     string `json:"ai_interpretation"`
+		SessionID string `json:"session_id"`
 	}
 	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
-		t.Errorf("レスポンスのデコードに失敗: %v", err)
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
 	}
+	assert.NotEmpty(t, response.SessionID)
+	mockService.AssertCalled(t, "GetOptions", mock.Anything, response.SessionID)
+}
 
-	if response.ID != "test-quiz" {
-		t.Errorf("期待するクイズID %q に対して、%q が返されました", "test-quiz", response.ID)
+func TestHandleQuiz_MethodNotAllowed(t *testing.T) {
+	mockService := &MockQuizService{}
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		rec := httptest.NewRecorder()
+		NewServer(mockService).ServeHTTP(rec, httptest.NewRequest(method, "/quizzes/test-quiz", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, method)
+		assert.Equal(t, "GET, HEAD, PATCH, DELETE", rec.Header().Get("Allow"), method)
 	}
-	if response.AuthorInterpretation != "投稿者の解釈" {
-		t.Errorf("期待する投稿者の解釈 %q に対して、%q が返されました", "投稿者の解釈", response.AuthorInterpretation)
+	// クイズを取得せずに拒否する
+	mockService.AssertNotCalled(t, "GetQuiz", mock.Anything, mock.Anything)
+}
+
+func TestHandleGetQuiz_Unpublished(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     models.QuizStatus
+		identity   *auth.Identity
+		wantStatus int
+	}{
+		{name: "公開中のクイズ", status: models.QuizApproved, wantStatus: http.StatusOK},
+		{name: "保留中のクイズはプレイヤーには見えない", status: models.QuizFlagged, wantStatus: http.StatusNotFound},
+		{name: "拒否されたクイズはプレイヤーには見えない", status: models.QuizRejected, wantStatus: http.StatusNotFound},
+		{name: "作成者は保留中のクイズを確認できる", status: models.QuizFlagged, identity: &auth.Identity{UserID: "author-1"}, wantStatus: http.StatusOK},
+		{name: "他の利用者には見えない", status: models.QuizFlagged, identity: &auth.Identity{UserID: "user-2"}, wantStatus: http.StatusNotFound},
+		{name: "管理者は保留中のクイズを確認できる", status: models.QuizFlagged, identity: &auth.Identity{UserID: "admin", Roles: []string{auth.RoleAdmin}}, wantStatus: http.StatusOK},
 	}
-	if response.AIInterpretation != "AIの解釈" {
-		t.Errorf("期待するAIの解釈 %q に対して、%q が返されました", "AIの解釈", response.AIInterpretation)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			mockService.On("GetQuiz", mock.Anything, "test-quiz").Return(&models.Quiz{
+				ID:        "test-quiz",
+				ImagePath: "/images/test.jpg",
+				AuthorID:  "author-1",
+				Status:    tt.status,
+			}, nil)
+			mockService.On("GetOptions", mock.Anything, mock.Anything).Return([]models.QuizOption{})
+			mockService.On("GetSignedImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://example.com/test.jpg", nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/quizzes/test-quiz", nil)
+			if tt.identity != nil {
+				req = withIdentity(req, tt.identity)
+			}
+			rec := httptest.NewRecorder()
+			NewServer(mockService).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
 	}
 }
 
+func TestHandleVerifyAnswer(t *testing.T) {
+	quiz := &models.Quiz{ID: "test-quiz"}
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(*MockQuizService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "正常系：正解",
+			body: `{"quiz_id":"test-quiz","session_id":"s","option_id":"option-2"}`,
+			setup: func(m *MockQuizService) {
+				m.On("GetQuiz", mock.Anything, "test-quiz").Return(quiz, nil)
+				m.On("SubmitAnswer", mock.Anything, quiz, &service.SubmitAnswerInput{SessionID: "s", OptionID: "option-2", PlayerID: "player-1"}).Return(&models.Answer{IsCorrect: true}, nil)
+				m.On("CorrectOptionID", quiz, "s").Return("option-2")
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"is_correct":true,"correct_option_id":"option-2"}`,
+		},
+		{
+			name: "正常系：不正解",
+			body: `{"quiz_id":"test-quiz","session_id":"s","option_id":"option-1"}`,
+			setup: func(m *MockQuizService) {
+				m.On("GetQuiz", mock.Anything, "test-quiz").Return(quiz, nil)
+				m.On("SubmitAnswer", mock.Anything, quiz, &service.SubmitAnswerInput{SessionID: "s", OptionID: "option-1", PlayerID: "player-1"}).Return(&models.Answer{DecoyIndex: 0}, nil)
+				m.On("CorrectOptionID", quiz, "s").Return("option-2")
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"is_correct":false,"correct_option_id":"option-2"}`,
+		},
+		{
+			name: "異常系：存在しない選択肢",
+			body: `{"quiz_id":"test-quiz","session_id":"s","option_id":"unknown"}`,
+			setup: func(m *MockQuizService) {
+				m.On("GetQuiz", mock.Anything, "test-quiz").Return(quiz, nil)
+				m.On("SubmitAnswer", mock.Anything, quiz, mock.Anything).Return(nil, fmt.Errorf("wrap: %w", service.ErrUnknownOption))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "異常系：選択肢IDがない",
+			body:           `{"quiz_id":"test-quiz","session_id":"s"}`,
+			setup:          func(m *MockQuizService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			tt.setup(mockService)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/verify-answer", bytes.NewBufferString(tt.body))
+			req.Header.Set(playerIDHeader, "player-1")
+			NewServer(mockService).handleVerifyAnswer(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleVerifyAnswer_IssuesPlayerID(t *testing.T) {
+	quiz := &models.Quiz{ID: "test-quiz"}
+	mockService := &MockQuizService{}
+	mockService.On("GetQuiz", mock.Anything, "test-quiz").Return(quiz, nil)
+	mockService.On("SubmitAnswer", mock.Anything, quiz, mock.Anything).Return(&models.Answer{}, nil)
+	mockService.On("CorrectOptionID", quiz, "s").Return("option-2")
+	srv := NewServer(mockService)
+
+	// 初回はプレイヤーIDをクッキーで発行する
+	rec := httptest.NewRecorder()
+	srv.handleVerifyAnswer(rec, httptest.NewRequest(http.MethodPost, "/verify-answer", bytes.NewBufferString(`{"quiz_id":"test-quiz","session_id":"s","option_id":"o"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	cookies := rec.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, playerIDCookie, cookies[0].Name)
+		assert.NotEmpty(t, cookies[0].Value)
+	}
+
+	// 2回目以降はクッキーのプレイヤーIDを使う
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/verify-answer", bytes.NewBufferString(`{"quiz_id":"test-quiz","session_id":"s","option_id":"o"}`))
+	req.AddCookie(cookies[0])
+	srv.handleVerifyAnswer(rec, req)
+	assert.Empty(t, rec.Result().Cookies())
+	mockService.AssertCalled(t, "SubmitAnswer", mock.Anything, quiz, &service.SubmitAnswerInput{SessionID: "s", OptionID: "o", PlayerID: cookies[0].Value})
+
+	// 不正なプレイヤーIDは受け付けない
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/verify-answer", bytes.NewBufferString(`{"quiz_id":"test-quiz","session_id":"s","option_id":"o"}`))
+	req.Header.Set(playerIDHeader, "../../etc")
+	srv.handleVerifyAnswer(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandleGetQuizStats(t *testing.T) {
+	mockService := &MockQuizService{}
+	mockService.On("GetQuiz", mock.Anything, "test-quiz").Return(&models.Quiz{ID: "test-quiz"}, nil)
+	mockService.On("GetQuiz", mock.Anything, "missing").Return(nil, fmt.Errorf("クイズが見つかりません"))
+	mockService.On("GetQuiz", mock.Anything, "flagged").Return(&models.Quiz{ID: "flagged", Status: models.QuizFlagged}, nil)
+	mockService.On("GetQuizStats", mock.Anything, "test-quiz").Return(&models.QuizStats{
+		QuizID:            "test-quiz",
+		Attempts:          5,
+		CorrectAttempts:   2,
+		DecoyPicks:        []int{1, 2},
+		PlayerCount:       4,
+		FooledPlayerCount: 3,
+	}, nil)
+	srv := NewServer(mockService)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/test-quiz/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"quiz_id": "test-quiz",
+		"attempts": 5,
+		"correct_attempts": 2,
+		"fooled_attempts": 3,
+		"players": 4,
+		"fooled_players": 3,
+		"fooled_rate": 0.75,
+		"decoy_picks": [1, 2]
+	}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/missing/stats", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// 公開されていないクイズの集計は返さない
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/flagged/stats", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	mockService.AssertNotCalled(t, "GetQuizStats", mock.Anything, "flagged")
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quizzes/test-quiz/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
 func TestHandleDeleteAllQuizzes(t *testing.T) {
 	tests := []struct {
 		name         string
 		method       string
+		anonymous    bool
+		roles        []string
 		setup        func(*MockQuizService)
 		expectedCode int
 		expectedBody string
@@ -235,6 +711,20 @@ func TestHandleDeleteAllQuizzes(t *testing.T) {
 			setup:        func(m *MockQuizService) {},
 			expectedCode: http.StatusMethodNotAllowed,
 		},
+		{
+			name:         "異常系：未認証",
+			method:       http.MethodDelete,
+			anonymous:    true,
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系：管理者ではない",
+			method:       http.MethodDelete,
+			roles:        []string{},
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusForbidden,
+		},
 		{
 			name:   "異常系：サービス層でエラー発生",
 			method: http.MethodDelete,
@@ -250,8 +740,16 @@ func TestHandleDeleteAllQuizzes(t *testing.T) {
 			mockService := &MockQuizService{}
 			tt.setup(mockService)
 
-			server := NewServer(mockService)
+			signer := newTestSigner(t)
+			server := NewServer(mockService, WithVerifier(signer))
 			req := httptest.NewRequest(tt.method, "/delete-all-quizzes", nil)
+			if !tt.anonymous {
+				roles := tt.roles
+				if roles == nil {
+					roles = []string{auth.RoleAdmin}
+				}
+				authorize(t, req, signer, &auth.Identity{UserID: "user-1", Roles: roles})
+			}
 			rec := httptest.NewRecorder()
 
 			server.ServeHTTP(rec, req)
@@ -265,3 +763,274 @@ func TestHandleDeleteAllQuizzes(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleDeleteQuiz(t *testing.T) {
+	ownedQuiz := &models.Quiz{ID: "test-quiz", AuthorID: "author-1", ImagePath: "images/test.jpg"}
+
+	tests := []struct {
+		name         string
+		identity     *auth.Identity
+		setup        func(*MockQuizService)
+		expectedCode int
+		wantAudit    bool
+	}{
+		{
+			name:     "正常系：作成者による削除",
+			identity: &auth.Identity{UserID: "author-1"},
+			setup: func(m *MockQuizService) {
+				m.On("DeleteQuiz", mock.Anything, "test-quiz").Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:     "正常系：管理者による削除は監査ログに記録",
+			identity: &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}},
+			setup: func(m *MockQuizService) {
+				m.On("DeleteQuiz", mock.Anything, "test-quiz").Return(nil)
+			},
+			expectedCode: http.StatusOK,
+			wantAudit:    true,
+		},
+		{
+			name:     "異常系：管理者による削除の失敗も監査ログに記録",
+			identity: &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}},
+			setup: func(m *MockQuizService) {
+				m.On("DeleteQuiz", mock.Anything, "test-quiz").Return(fmt.Errorf("削除に失敗"))
+			},
+			expectedCode: http.StatusInternalServerError,
+			wantAudit:    true,
+		},
+		{
+			name:         "異常系：未認証",
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系：作成者ではない",
+			identity:     &auth.Identity{UserID: "user-2"},
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusForbidden,
+			wantAudit:    true,
+		},
+		{
+			name:     "異常系：削除中にクイズが削除された",
+			identity: &auth.Identity{UserID: "author-1"},
+			setup: func(m *MockQuizService) {
+				m.On("DeleteQuiz", mock.Anything, "test-quiz").Return(fmt.Errorf("%w: test-quiz", storage.ErrQuizNotFound))
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			mockService.On("GetQuiz", mock.Anything, "test-quiz").Return(ownedQuiz, nil).Maybe()
+			tt.setup(mockService)
+
+			recorder := &auditRecorder{}
+			server := NewServer(mockService, WithAuditLogger(recorder))
+			req := httptest.NewRequest(http.MethodDelete, "/quizzes/test-quiz", nil)
+			if tt.identity != nil {
+				req = withIdentity(req, tt.identity)
+			}
+			rec := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			if tt.wantAudit {
+				if assert.Len(t, recorder.entries, 1) {
+					entry := recorder.entries[0]
+					assert.Equal(t, string(actionDeleteAnyQuiz), entry.Action)
+					assert.Equal(t, "test-quiz", entry.Target)
+					assert.Equal(t, tt.expectedCode, entry.Status)
+				}
+			} else {
+				assert.Empty(t, recorder.entries)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleUpdateQuiz(t *testing.T) {
+	ownedQuiz := &models.Quiz{ID: "test-quiz", AuthorID: "author-1", ImagePath: "images/test.jpg"}
+	updatedQuiz := &models.Quiz{
+		ID:                   "test-quiz",
+		AuthorID:             "author-1",
+		ImagePath:            "images/test.jpg",
+		AuthorInterpretation: "新しい解釈",
+		AIInterpretation:     "新しいAIの解釈",
+	}
+
+	tests := []struct {
+		name         string
+		identity     *auth.Identity
+		body         string
+		setup        func(*MockQuizService)
+		expectedCode int
+	}{
+		{
+			name:     "正常系：解釈の編集とAIの解釈の再生成",
+			identity: &auth.Identity{UserID: "author-1"},
+			body:     `{"author_interpretation":"新しい解釈","regenerate_ai":true}`,
+			setup: func(m *MockQuizService) {
+				m.On("UpdateQuiz", mock.Anything, "test-quiz", mock.MatchedBy(func(input *service.UpdateQuizInput) bool {
+					return input.AuthorInterpretation != nil && *input.AuthorInterpretation == "新しい解釈" && input.RegenerateDecoys
+				})).Return(updatedQuiz, nil)
+				m.On("GetSignedImageURL", mock.Anything, updatedQuiz.Image(), mock.Anything).Return("https://example.com/test.jpg", nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系：未認証",
+			body:         `{"author_interpretation":"新しい解釈"}`,
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "異常系：作成者ではない",
+			identity:     &auth.Identity{UserID: "admin-1", Roles: []string{auth.RoleAdmin}},
+			body:         `{"author_interpretation":"新しい解釈"}`,
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "異常系：変更内容なし",
+			identity:     &auth.Identity{UserID: "author-1"},
+			body:         `{}`,
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系：空の解釈",
+			identity:     &auth.Identity{UserID: "author-1"},
+			body:         `{"author_interpretation":""}`,
+			setup:        func(m *MockQuizService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			mockService.On("GetQuiz", mock.Anything, "test-quiz").Return(ownedQuiz, nil).Maybe()
+			tt.setup(mockService)
+
+			server := NewServer(mockService)
+			req := httptest.NewRequest(http.MethodPatch, "/quizzes/test-quiz", bytes.NewBufferString(tt.body))
+			if tt.identity != nil {
+				req = withIdentity(req, tt.identity)
+			}
+			rec := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedCode == http.StatusOK {
+				var response authorQuizResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, "新しい解釈", response.AuthorInterpretation)
+				assert.Equal(t, []string{"新しいAIの解釈"}, response.AIInterpretations)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleGetQuizList(t *testing.T) {
+	createdAt := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        string
+		wantQuery    *models.QuizListQuery
+		serviceErr   error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "正常系：条件なし",
+			query:        "",
+			wantQuery:    &models.QuizListQuery{},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"quizzes":[{"id":"quiz-1","title":"夜の街","thumbnail_url":"https://storage.example.com/quiz-1.png","author_id":"author-1","author_name":"投稿者","tags":["夜"],"play_count":12,"difficulty":"normal","created_at":"2024-03-20 10:00:00"}],"next_cursor":"next"}`,
+		},
+		{
+			name:  "正常系：絞り込みとページング",
+			query: "?author=author-1&tag=夜&from=2024-03-01&to=2024-03-31&order=asc&limit=10&cursor=abc",
+			wantQuery: &models.QuizListQuery{
+				AuthorID:    "author-1",
+				Tag:         "夜",
+				CreatedFrom: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				CreatedTo:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+				Order:       models.SortOldestFirst,
+				Limit:       10,
+				Cursor:      "abc",
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "異常系：不正な件数",
+			query:        "?limit=0",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系：不正な並び順",
+			query:        "?order=random",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系：不正な日付",
+			query:        "?from=yesterday",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "異常系：不正なカーソル",
+			query:        "?cursor=broken",
+			wantQuery:    &models.QuizListQuery{Cursor: "broken"},
+			serviceErr:   fmt.Errorf("wrap: %w", storage.ErrInvalidCursor),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockQuizService{}
+			if tt.wantQuery != nil {
+				page := &models.QuizPage{
+					Quizzes: []*models.QuizSummary{{
+						QuizIndexEntry: models.QuizIndexEntry{
+							ID:         "quiz-1",
+							Title:      "夜の街",
+							ImagePath:  "images/quiz-1.png",
+							AuthorID:   "author-1",
+							AuthorName: "投稿者",
+							Tags:       []string{"夜"},
+							DecoyCount: 2,
+							CreatedAt:  createdAt,
+						},
+						PlayCount: 12,
+					}},
+					NextCursor: "next",
+				}
+				if tt.serviceErr != nil {
+					page = nil
+				}
+				mockService.On("GetQuizList", mock.Anything, tt.wantQuery).Return(page, tt.serviceErr)
+				mockService.On("GetSignedImageURL", mock.Anything, models.QuizImage{QuizID: "quiz-1", Path: "images/quiz-1.png"}, models.ImageThumbnail).Return("https://storage.example.com/quiz-1.png", nil).Maybe()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/quizzes"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			NewServer(mockService).handleGetQuizList(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// uploadsPath はアップロードセッションのAPIのURLパスです
+const uploadsPath = "/uploads"
+
+// WithUploadService は画像を2段階でアップロードするためのサービスを設定します
+// 未設定の場合、/uploads 以下のルートは登録しません
+func WithUploadService(uploadService service.UploadService) Option {
+	return func(s *Server) {
+		s.uploadService = uploadService
+	}
+}
+
+// uploadSessionResponse はアップロードセッションの状態です
+type uploadSessionResponse struct {
+	ID        string `json:"id"`
+	Filename  string `json:"filename"`
+	Size      int64  `json:"size"`
+	Received  int64  `json:"received"`
+	ExpiresAt string `json:"expires_at"`
+	// UploadURL は画像をアップロードするURLです（作成時のみ）
+	UploadURL string `json:"upload_url,omitempty"`
+	// UploadMethod は UploadURL に送るメソッドです（作成時のみ）
+	UploadMethod string `json:"upload_method,omitempty"`
+	// UploadHeaders は UploadURL に送る必要のあるヘッダーです（作成時のみ）
+	UploadHeaders map[string]string `json:"upload_headers,omitempty"`
+	// Resumable は UploadURL がサーバーの分割アップロードのエンドポイントかどうかです（作成時のみ）
+	Resumable bool `json:"resumable,omitempty"`
+}
+
+// newUploadSessionResponse はアップロードセッションの状態を作成します
+func newUploadSessionResponse(session *models.UploadSession, received int64) *uploadSessionResponse {
+	return &uploadSessionResponse{
+		ID:        session.ID,
+		Filename:  session.Filename,
+		Size:      session.Size,
+		Received:  received,
+		ExpiresAt: session.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// handleCreateUpload はアップロードセッションを作成するハンドラーです
+// 署名付きURLを発行できる場合はバケットへ直接 PUT するURLを、
+// できない場合はサーバーの分割アップロードのエンドポイントを返します
+func (s *Server) handleCreateUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	identity, ok := auth.FromContext(r.Context())
+	if !ok {
+		unauthorized(w, "認証が必要です")
+		return
+	}
+
+	var request struct {
+		Filename    string `json:"filename"`
+		ContentType string `json:"content_type"`
+		Size        int64  `json:"size"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logging.Error("handleCreateUpload: リクエストの解析に失敗: %v", err)
+		http.Error(w, "リクエストの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := s.uploadService.CreateUploadSession(r.Context(), &service.CreateUploadInput{
+		AuthorID:    identity.UserID,
+		Filename:    request.Filename,
+		ContentType: request.ContentType,
+		Size:        request.Size,
+	})
+	if err != nil {
+		logging.Error("handleCreateUpload: アップロードセッションの作成に失敗: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidUpload) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("アップロードセッションの作成に失敗しました: %v", err), status)
+		return
+	}
+
+	response := newUploadSessionResponse(ticket.Session, 0)
+	response.UploadMethod = http.MethodPut
+	if ticket.UploadURL != "" {
+		response.UploadURL = ticket.UploadURL
+		response.UploadHeaders = ticket.Headers
+	} else {
+		response.UploadURL = uploadsPath + "/" + ticket.Session.ID + "/data"
+		response.Resumable = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.Error("handleCreateUpload: レスポンスの送信に失敗: %v", err)
+		return
+	}
+	logging.Info("handleCreateUpload: アップロードセッションを作成しました: id=%s", ticket.Session.ID)
+}
+
+// handleUploadSession は /uploads/{id} 以下のリクエストを振り分けます
+func (s *Server) handleUploadSession(w http.ResponseWriter, r *http.Request) {
+	uploadID, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, uploadsPath+"/"), "/")
+	var handler func(http.ResponseWriter, *http.Request, *models.UploadSession)
+	switch {
+	case sub == "" && r.Method == http.MethodGet:
+		handler = s.handleGetUpload
+	case sub == "data" && r.Method == http.MethodPut:
+		handler = s.handleUploadData
+	case sub == "finalize" && r.Method == http.MethodPost:
+		handler = s.handleFinalizeUpload
+	case sub == "" || sub == "data" || sub == "finalize":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	session, ok := s.authorizeUpload(w, r, uploadID)
+	if !ok {
+		return
+	}
+	handler(w, r, session)
+}
+
+// authorizeUpload はアップロードセッションを取得し、呼び出し元がセッションの作成者であることを確認します
+// 確認できない場合はエラーのレスポンスを送信して false を返します
+func (s *Server) authorizeUpload(w http.ResponseWriter, r *http.Request, uploadID string) (*models.UploadSession, bool) {
+	identity, ok := auth.FromContext(r.Context())
+	if !ok {
+		unauthorized(w, "認証が必要です")
+		return nil, false
+	}
+
+	session, err := s.uploadService.GetUploadSession(r.Context(), uploadID)
+	if err != nil {
+		logging.Warn("authorizeUpload: アップロードセッションの取得に失敗: id=%s, err=%v", uploadID, err)
+		switch {
+		case errors.Is(err, storage.ErrUploadNotFound):
+			http.Error(w, "アップロードが見つかりません", http.StatusNotFound)
+		case errors.Is(err, service.ErrUploadExpired):
+			http.Error(w, "アップロードの有効期限が切れています", http.StatusGone)
+		default:
+			http.Error(w, "アップロードの取得に失敗しました", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	if session.AuthorID != identity.UserID {
+		logging.Warn("authorizeUpload: 作成者以外のアクセス: id=%s, user=%s", uploadID, identity.UserID)
+		http.Error(w, "アップロードの作成者のみ操作できます", http.StatusForbidden)
+		return nil, false
+	}
+	return session, true
+}
+
+// handleGetUpload はアップロードセッションの状態（受信済みのバイト数）を返すハンドラーです
+// 分割アップロードが中断した場合、クライアントは received から再開します
+func (s *Server) handleGetUpload(w http.ResponseWriter, r *http.Request, session *models.UploadSession) {
+	received, err := s.uploadService.UploadedSize(r.Context(), session.ID)
+	if err != nil {
+		logging.Error("handleGetUpload: 受信済みのバイト数の取得に失敗: id=%s, err=%v", session.ID, err)
+		http.Error(w, "アップロードの取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+	writeUploadStatus(w, http.StatusOK, newUploadSessionResponse(session, received))
+}
+
+// handleUploadData は分割アップロードの1つ分のデータを受け取るハンドラーです
+// Content-Range: bytes 先頭-末尾/全体 で開始位置を指定します（省略時は先頭から）
+// 開始位置が受信済みのバイト数と一致しない場合は 409 と受信済みのバイト数を返します
+func (s *Server) handleUploadData(w http.ResponseWriter, r *http.Request, session *models.UploadSession) {
+	offset, err := parseContentRangeStart(r.Header.Get("Content-Range"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxUploadSize))
+	if err != nil {
+		logging.Error("handleUploadData: データの読み込みに失敗: id=%s, err=%v", session.ID, err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "アップロードされたデータが大きすぎます", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "データの読み込みに失敗しました", http.StatusBadRequest)
+		return
+	}
+
+	received, err := s.uploadService.WriteUploadChunk(r.Context(), session, offset, data)
+	if err != nil {
+		logging.Warn("handleUploadData: 書き込みに失敗: id=%s, offset=%d, err=%v", session.ID, offset, err)
+		switch {
+		case errors.Is(err, storage.ErrUploadOffsetMismatch):
+			writeUploadStatus(w, http.StatusConflict, newUploadSessionResponse(session, received))
+		case errors.Is(err, service.ErrUploadTooLarge):
+			http.Error(w, "アップロードされたデータが大きすぎます", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, service.ErrInvalidUpload):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "データの保存に失敗しました", http.StatusInternalServerError)
+		}
+		return
+	}
+	writeUploadStatus(w, http.StatusOK, newUploadSessionResponse(session, received))
+}
+
+// handleFinalizeUpload はアップロードされた画像を検証してクイズを作成するハンドラーです
+// 作成に成功した場合はセッションとアップロードされた画像を削除し、/upload と同じ内容を返します
+func (s *Server) handleFinalizeUpload(w http.ResponseWriter, r *http.Request, session *models.UploadSession) {
+	identity, _ := auth.FromContext(r.Context())
+
+	var request struct {
+		Interpretation string   `json:"interpretation"`
+		Title          string   `json:"title"`
+		DecoyCount     int      `json:"decoy_count"`
+		Tags           []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logging.Error("handleFinalizeUpload: リクエストの解析に失敗: %v", err)
+		http.Error(w, "リクエストの解析に失敗しました", http.StatusBadRequest)
+		return
+	}
+	if request.Interpretation == "" {
+		http.Error(w, "投稿者の解釈が必要です", http.StatusBadRequest)
+		return
+	}
+	if request.DecoyCount != 0 && (request.DecoyCount < service.MinDecoyCount || request.DecoyCount > service.MaxDecoyCount) {
+		http.Error(w, fmt.Sprintf("decoy_count は%dから%dの整数で指定してください", service.MinDecoyCount, service.MaxDecoyCount), http.StatusBadRequest)
+		return
+	}
+
+	// アップロードされた画像の検証（バケットに直接アップロードされた画像も同じ検証を通す）
+	reader, err := s.uploadService.OpenUpload(r.Context(), session.ID)
+	if err != nil {
+		logging.Error("handleFinalizeUpload: 画像の読み込みに失敗: id=%s, err=%v", session.ID, err)
+		if errors.Is(err, storage.ErrUploadNotFound) {
+			http.Error(w, "画像がアップロードされていません", http.StatusConflict)
+			return
+		}
+		http.Error(w, "画像の読み込みに失敗しました", http.StatusInternalServerError)
+		return
+	}
+	buf, mimeType, err := s.imageValidator.ValidateAndCopy(reader, session.Filename)
+	reader.Close()
+	if err != nil {
+		logging.Error("handleFinalizeUpload: 画像の検証に失敗: id=%s, err=%v", session.ID, err)
+		http.Error(w, fmt.Sprintf("画像の検証に失敗しました: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	quiz, err := s.quizService.CreateQuiz(r.Context(), &service.CreateQuizInput{
+		ImageData:            buf.Bytes(),
+		ImageMimeType:        mimeType,
+		AuthorInterpretation: request.Interpretation,
+		Title:                request.Title,
+		DecoyCount:           request.DecoyCount,
+		AuthorID:             identity.UserID,
+		AuthorName:           identity.DisplayName,
+		Tags:                 request.Tags,
+	})
+	if err != nil {
+		logging.Error("handleFinalizeUpload: クイズの作成に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("クイズの作成に失敗しました: %v", err), quizErrorStatus(err))
+		return
+	}
+
+	// クイズは作成済みのため、削除に失敗してもログに残すだけにする（残った画像はライフサイクルルールで削除される）
+	if err := s.uploadService.DeleteUploadSession(r.Context(), session.ID); err != nil {
+		logging.Warn("handleFinalizeUpload: アップロードセッションの削除に失敗: id=%s, err=%v", session.ID, err)
+	}
+
+	imageURLs, err := s.imageURLs(r.Context(), quiz.Image())
+	if err != nil {
+		logging.Error("handleFinalizeUpload: 画像URLの生成に失敗: %v", err)
+		http.Error(w, fmt.Sprintf("画像URLの生成に失敗しました: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newAuthorQuizResponse(quiz, imageURLs)); err != nil {
+		logging.Error("handleFinalizeUpload: レスポンスの送信に失敗: %v", err)
+		return
+	}
+	logging.Info("handleFinalizeUpload: クイズの作成に成功: upload=%s, id=%s", session.ID, quiz.ID)
+}
+
+// writeUploadStatus はアップロードセッションの状態をJSONで送信します
+func writeUploadStatus(w http.ResponseWriter, status int, response *uploadSessionResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.Error("writeUploadStatus: レスポンスの送信に失敗: %v", err)
+	}
+}
+
+// parseContentRangeStart は Content-Range ヘッダー（bytes 先頭-末尾/全体）から開始位置を返します
+// ヘッダーがない場合は 0 を返します
+func parseContentRangeStart(header string) (int64, error) {
+	if header == "" {
+		return 0, nil
+	}
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, fmt.Errorf("不正な Content-Range です: %q", header)
+	}
+	byteRange, _, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, fmt.Errorf("不正な Content-Range です: %q", header)
+	}
+	first, _, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, fmt.Errorf("不正な Content-Range です: %q", header)
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, fmt.Errorf("不正な Content-Range です: %q", header)
+	}
+	return start, nil
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/ai"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/auth"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/service"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// newUploadSessionServer はアップロードセッションを有効にしたエンドツーエンドのサーバーを作成します
+func newUploadSessionServer(t *testing.T) (*Server, *auth.JWTSigner) {
+	t.Helper()
+
+	aiClient, err := ai.NewProvider(context.Background(), ai.ProviderStub, ai.ProviderConfig{})
+	if err != nil {
+		t.Fatalf("AIクライアントの作成に失敗: %v", err)
+	}
+	storageClient, err := storage.NewLocalClient(t.TempDir())
+	if err != nil {
+		t.Fatalf("ストレージクライアントの作成に失敗: %v", err)
+	}
+
+	signer := newTestSigner(t)
+	srv := NewServer(service.NewQuizService(aiClient, storageClient),
+		WithVerifier(signer),
+		WithUploadService(service.NewUploadService(storageClient, MaxUploadSize)),
+	)
+	return srv, signer
+}
+
+// serveJSON はJSONのリクエストを送信し、レスポンスを返します
+func serveJSON(t *testing.T, srv *Server, signer *auth.JWTSigner, identity *auth.Identity, method, path string, body io.Reader, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, body)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if identity != nil {
+		authorize(t, req, signer, identity)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUploadSessionFlow(t *testing.T) {
+	srv, signer := newUploadSessionServer(t)
+	author := &auth.Identity{UserID: "author-1", DisplayName: "投稿者"}
+	other := &auth.Identity{UserID: "other"}
+
+	imageData, err := os.ReadFile("../../fixture/generated/fake0.png")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+	size := len(imageData)
+
+	// 1. セッションの作成（ローカルストレージでは分割アップロード）
+	rec := serveJSON(t, srv, signer, author, http.MethodPost, "/uploads",
+		strings.NewReader(fmt.Sprintf(`{"filename":"fake0.png","content_type":"image/png","size":%d}`, size)), nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var created uploadSessionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
+	}
+	assert.True(t, created.Resumable)
+	assert.Equal(t, http.MethodPut, created.UploadMethod)
+	assert.Equal(t, "/uploads/"+created.ID+"/data", created.UploadURL)
+
+	// 2. 前半のアップロード
+	half := size / 2
+	rec = serveJSON(t, srv, signer, author, http.MethodPut, created.UploadURL, bytes.NewReader(imageData[:half]),
+		map[string]string{"Content-Range": fmt.Sprintf("bytes 0-%d/%d", half-1, size)})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// 作成者以外はアップロードできない
+	rec = serveJSON(t, srv, signer, other, http.MethodPut, created.UploadURL, bytes.NewReader(imageData[half:]), nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// 開始位置がずれている場合は受信済みのバイト数を返す
+	rec = serveJSON(t, srv, signer, author, http.MethodPut, created.UploadURL, bytes.NewReader(imageData[half:]),
+		map[string]string{"Content-Range": fmt.Sprintf("bytes 1-%d/%d", size-half, size)})
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+	}
+	var status uploadSessionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
+	}
+	assert.Equal(t, int64(half), status.Received)
+
+	// 3. 中断後の再開：受信済みのバイト数を確認して続きを送る
+	rec = serveJSON(t, srv, signer, author, http.MethodGet, "/uploads/"+created.ID, nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
+	}
+	assert.Equal(t, int64(half), status.Received)
+
+	rec = serveJSON(t, srv, signer, author, http.MethodPut, created.UploadURL, bytes.NewReader(imageData[half:]),
+		map[string]string{"Content-Range": fmt.Sprintf("bytes %d-%d/%d", half, size-1, size)})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
+	}
+	assert.Equal(t, int64(size), status.Received)
+
+	// 4. 確定してクイズを作成
+	rec = serveJSON(t, srv, signer, author, http.MethodPost, "/uploads/"+created.ID+"/finalize",
+		strings.NewReader(`{"interpretation":"夕暮れの街に灯る明かりは安心感を表しています。","title":"夕暮れの街","tags":["街"]}`), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var quiz authorQuizResponse
+	if err := json.NewDecoder(rec.Body).Decode(&quiz); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
+	}
+	assert.NotEmpty(t, quiz.ID)
+	assert.Equal(t, "author-1", quiz.AuthorID)
+	assert.Equal(t, "夕暮れの街", quiz.Title)
+	assert.True(t, strings.HasSuffix(quiz.ImageURL, ".png"), quiz.ImageURL)
+
+	// 確定後のセッションは削除される
+	rec = serveJSON(t, srv, signer, author, http.MethodGet, "/uploads/"+created.ID, nil, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestUploadSession_Errors(t *testing.T) {
+	srv, signer := newUploadSessionServer(t)
+	author := &auth.Identity{UserID: "author-1"}
+
+	rec := serveJSON(t, srv, signer, author, http.MethodPost, "/uploads", strings.NewReader(`{"filename":"a.png","size":10}`), nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var created uploadSessionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("レスポンスのデコードに失敗: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		identity   *auth.Identity
+		method     string
+		path       string
+		body       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{name: "未認証", method: http.MethodPost, path: "/uploads", body: `{"filename":"a.png","size":10}`, wantStatus: http.StatusUnauthorized},
+		{name: "最大サイズを超える", identity: author, method: http.MethodPost, path: "/uploads", body: fmt.Sprintf(`{"filename":"a.png","size":%d}`, MaxUploadSize), wantStatus: http.StatusBadRequest},
+		{name: "不正なJSON", identity: author, method: http.MethodPost, path: "/uploads", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "存在しないセッション", identity: author, method: http.MethodGet, path: "/uploads/upload_missing", wantStatus: http.StatusNotFound},
+		{name: "不正な Content-Range", identity: author, method: http.MethodPut, path: "/uploads/" + created.ID + "/data", body: "data", header: map[string]string{"Content-Range": "items 0-3/4"}, wantStatus: http.StatusBadRequest},
+		{name: "画像のない確定", identity: author, method: http.MethodPost, path: "/uploads/" + created.ID + "/finalize", body: `{"interpretation":"解釈"}`, wantStatus: http.StatusConflict},
+		{name: "解釈のない確定", identity: author, method: http.MethodPost, path: "/uploads/" + created.ID + "/finalize", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "不正なメソッド", identity: author, method: http.MethodDelete, path: "/uploads/" + created.ID + "/data", wantStatus: http.StatusMethodNotAllowed},
+		{name: "存在しないルート", identity: author, method: http.MethodGet, path: "/uploads/" + created.ID + "/unknown", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveJSON(t, srv, signer, tt.identity, tt.method, tt.path, strings.NewReader(tt.body), tt.header)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+
+	// 画像として読み込めないデータは確定できない
+	rec = serveJSON(t, srv, signer, author, http.MethodPut, "/uploads/"+created.ID+"/data", strings.NewReader("not an image"), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	rec = serveJSON(t, srv, signer, author, http.MethodPost, "/uploads/"+created.ID+"/finalize", strings.NewReader(`{"interpretation":"解釈"}`), nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestUploadSession_Disabled(t *testing.T) {
+	// アップロードサービスが未設定の場合はルートを登録しない
+	rec := httptest.NewRecorder()
+	NewServer(new(MockQuizService)).ServeHTTP(rec, withIdentity(httptest.NewRequest(http.MethodPost, "/uploads", nil), &auth.Identity{UserID: "u"}))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+const (
+	// optionIDLength は選択肢IDの16進数での長さです
+	optionIDLength = 16
+	// maxClientIDLength はクライアントから受け付けるセッションID・プレイヤーIDの最大長です
+	maxClientIDLength = 64
+)
+
+var (
+	// ErrUnknownOption は選択肢IDがクイズのセッションに存在しないことを表します
+	ErrUnknownOption = errors.New("選択肢が見つかりません")
+	// ErrInvalidSessionID はセッションIDの形式が不正であることを表します
+	ErrInvalidSessionID = errors.New("セッションIDが不正です")
+	// ErrInvalidPlayerID はプレイヤーIDの形式が不正であることを表します
+	ErrInvalidPlayerID = errors.New("プレイヤーIDが不正です")
+)
+
+// Option はQuizServiceImplの設定を変更する関数です
+type Option func(*QuizServiceImpl)
+
+// WithAnswerSecret は選択肢IDの生成に使用する秘密鍵を設定します
+// 複数のインスタンスでセッションを共有する場合は、同じ値を設定する必要があります
+func WithAnswerSecret(secret []byte) Option {
+	return func(s *QuizServiceImpl) {
+		if len(secret) > 0 {
+			s.answerSecret = secret
+		}
+	}
+}
+
+// NewSessionID はクイズのセッションIDを新しく生成します
+func NewSessionID() string {
+	return hex.EncodeToString(randomBytes(16))
+}
+
+// ValidateSessionID はクライアントから受け取ったセッションIDを検証します
+func ValidateSessionID(sessionID string) error {
+	if !validClientID(sessionID) {
+		return ErrInvalidSessionID
+	}
+	return nil
+}
+
+// NewPlayerID は匿名のプレイヤーIDを新しく生成します
+func NewPlayerID() string {
+	return hex.EncodeToString(randomBytes(16))
+}
+
+// ValidatePlayerID はクライアントから受け取ったプレイヤーIDを検証します
+func ValidatePlayerID(playerID string) error {
+	if !validClientID(playerID) {
+		return ErrInvalidPlayerID
+	}
+	return nil
+}
+
+// validClientID はクライアントが保持するIDとして受け付けられる文字列かを判定します
+// IDはオブジェクトのパスには使用しませんが、記録に残すため長さと文字種を制限します
+func validClientID(id string) bool {
+	if id == "" || len(id) > maxClientIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetOptions はセッションごとに並び順が固定された選択肢を返します
+// 選択肢のIDと並び順はクイズID・セッションID・秘密鍵から決まるため、
+// 同じセッションで再読み込みしても並び替わらず、どれが投稿者の解釈かもIDからは分かりません
+func (s *QuizServiceImpl) GetOptions(quiz *models.Quiz, sessionID string) []models.QuizOption {
+	texts := interpretationsOf(quiz)
+	options := make([]models.QuizOption, len(texts))
+	for i, text := range texts {
+		options[i] = models.QuizOption{
+			ID:   s.optionID(quiz.ID, sessionID, i),
+			Text: text,
+		}
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].ID < options[j].ID })
+	return options
+}
+
+// VerifyAnswer は選択された選択肢が投稿者の解釈かを検証します
+// 投稿者の解釈を選んだ場合のみ正解で、いずれかのおとりを選んだ場合は不正解です
+func (s *QuizServiceImpl) VerifyAnswer(quiz *models.Quiz, sessionID, optionID string) (bool, error) {
+	index, err := s.optionIndex(quiz, sessionID, optionID)
+	if err != nil {
+		return false, err
+	}
+	return index == 0, nil
+}
+
+// SubmitAnswerInput は回答の送信内容を表します
+type SubmitAnswerInput struct {
+	SessionID string
+	OptionID  string
+	// PlayerID は匿名のプレイヤーIDです
+	PlayerID string
+}
+
+// SubmitAnswer は回答を検証し、回答履歴として記録します
+func (s *QuizServiceImpl) SubmitAnswer(ctx context.Context, quiz *models.Quiz, input *SubmitAnswerInput) (*models.Answer, error) {
+	if input.PlayerID == "" {
+		return nil, fmt.Errorf("プレイヤーIDが必要です")
+	}
+	index, err := s.optionIndex(quiz, input.SessionID, input.OptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := &models.Answer{
+		ID:         fmt.Sprintf("answer_%d_%s", time.Now().UnixNano(), hex.EncodeToString(randomBytes(4))),
+		QuizID:     quiz.ID,
+		PlayerID:   input.PlayerID,
+		SessionID:  input.SessionID,
+		OptionID:   input.OptionID,
+		DecoyIndex: index - 1,
+		IsCorrect:  index == 0,
+		AnsweredAt: time.Now(),
+	}
+	if err := s.storageClient.SaveAnswer(ctx, answer); err != nil {
+		return nil, fmt.Errorf("回答の記録に失敗: %w", err)
+	}
+	return answer, nil
+}
+
+// GetQuizStats はクイズの回答の集計を取得します
+func (s *QuizServiceImpl) GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error) {
+	stats, err := s.storageClient.GetQuizStats(ctx, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("集計の取得に失敗: %w", err)
+	}
+	return stats, nil
+}
+
+// optionIndex は選択肢IDに対応する解釈の位置を返します（0 が投稿者の解釈）
+func (s *QuizServiceImpl) optionIndex(quiz *models.Quiz, sessionID, optionID string) (int, error) {
+	if err := ValidateSessionID(sessionID); err != nil {
+		return 0, err
+	}
+	for i := range interpretationsOf(quiz) {
+		if hmac.Equal([]byte(s.optionID(quiz.ID, sessionID, i)), []byte(optionID)) {
+			return i, nil
+		}
+	}
+	return 0, ErrUnknownOption
+}
+
+// CorrectOptionID はセッションにおける投稿者の解釈の選択肢IDを返します
+func (s *QuizServiceImpl) CorrectOptionID(quiz *models.Quiz, sessionID string) string {
+	return s.optionID(quiz.ID, sessionID, 0)
+}
+
+// interpretationsOf は投稿者の解釈を先頭に、すべての解釈を返します
+func interpretationsOf(quiz *models.Quiz) []string {
+	return append([]string{quiz.AuthorInterpretation}, quiz.Decoys()...)
+}
+
+// optionID は解釈の位置に対応する選択肢IDを生成します
+func (s *QuizServiceImpl) optionID(quizID, sessionID string, index int) string {
+	mac := hmac.New(sha256.New, s.answerSecret)
+	mac.Write([]byte(quizID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(sessionID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.Itoa(index)))
+	return hex.EncodeToString(mac.Sum(nil))[:optionIDLength]
+}
+
+// randomBytes は暗号論的に安全な乱数を n バイト返します
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		logging.Error("乱数の生成に失敗: %v", err)
+		panic(err)
+	}
+	return b
+}
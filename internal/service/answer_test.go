@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+func newAnswerTestQuiz() *models.Quiz {
+	return &models.Quiz{
+		ID:                   "quiz_1",
+		AuthorInterpretation: "投稿者の解釈",
+		AIInterpretation:     "AIの解釈1",
+		AIInterpretations:    []string{"AIの解釈1", "AIの解釈2", "AIの解釈3"},
+	}
+}
+
+func TestGetOptions(t *testing.T) {
+	service := NewQuizService(nil, nil, WithAnswerSecret([]byte("secret"))) // 依存関係不要
+	quiz := newAnswerTestQuiz()
+
+	options := service.GetOptions(quiz, "session-a")
+
+	texts := make([]string, len(options))
+	ids := make(map[string]bool)
+	for i, option := range options {
+		texts[i] = option.Text
+		ids[option.ID] = true
+		assert.Len(t, option.ID, optionIDLength)
+	}
+	assert.ElementsMatch(t, []string{"投稿者の解釈", "AIの解釈1", "AIの解釈2", "AIの解釈3"}, texts)
+	assert.Len(t, ids, len(options), "option IDs must be unique")
+
+	// 同じセッションでは再読み込みしても並び順とIDが変わらない
+	assert.Equal(t, options, service.GetOptions(quiz, "session-a"))
+
+	// 同じ秘密鍵を持つ別のインスタンスでも同じ結果になる
+	other := NewQuizService(nil, nil, WithAnswerSecret([]byte("secret")))
+	assert.Equal(t, options, other.GetOptions(quiz, "session-a"))
+}
+
+func TestGetOptions_DiffersBySession(t *testing.T) {
+	service := NewQuizService(nil, nil, WithAnswerSecret([]byte("secret")))
+	quiz := newAnswerTestQuiz()
+
+	// セッションが変われば並び順が変わることを確認
+	orders := make(map[string]bool)
+	for _, sessionID := range []string{"s1", "s2", "s3", "s4", "s5", "s6", "s7", "s8"} {
+		order := ""
+		for _, option := range service.GetOptions(quiz, sessionID) {
+			order += option.Text + "|"
+		}
+		orders[order] = true
+	}
+	if len(orders) < 2 {
+		t.Error("option order does not depend on the session")
+	}
+
+	// 別のセッションの選択肢IDでは回答できない
+	correct := service.CorrectOptionID(quiz, "s1")
+	_, err := service.VerifyAnswer(quiz, "s2", correct)
+	assert.True(t, errors.Is(err, ErrUnknownOption))
+}
+
+func TestVerifyAnswer(t *testing.T) {
+	service := NewQuizService(nil, nil) // 依存関係不要
+	quiz := newAnswerTestQuiz()
+	const sessionID = "session-a"
+
+	optionIDs := make(map[string]string)
+	for _, option := range service.GetOptions(quiz, sessionID) {
+		optionIDs[option.Text] = option.ID
+	}
+
+	tests := []struct {
+		name      string
+		sessionID string
+		optionID  string
+		want      bool
+		wantErr   error
+	}{
+		{name: "正解の場合", sessionID: sessionID, optionID: optionIDs["投稿者の解釈"], want: true},
+		{name: "おとり1を選んだ場合", sessionID: sessionID, optionID: optionIDs["AIの解釈1"], want: false},
+		{name: "おとり3を選んだ場合", sessionID: sessionID, optionID: optionIDs["AIの解釈3"], want: false},
+		{name: "存在しない選択肢の場合", sessionID: sessionID, optionID: "0123456789abcdef", wantErr: ErrUnknownOption},
+		{name: "解釈のテキストを送った場合", sessionID: sessionID, optionID: "投稿者の解釈", wantErr: ErrUnknownOption},
+		{name: "セッションIDがない場合", sessionID: "", optionID: optionIDs["投稿者の解釈"], wantErr: ErrInvalidSessionID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.VerifyAnswer(quiz, tt.sessionID, tt.optionID)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			if got != tt.want {
+				t.Errorf("VerifyAnswer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	assert.Equal(t, optionIDs["投稿者の解釈"], service.CorrectOptionID(quiz, sessionID))
+}
+
+func TestVerifyAnswer_LegacyQuiz(t *testing.T) {
+	service := NewQuizService(nil, nil)
+	// ai_interpretations を持たない旧形式のクイズ
+	quiz := &models.Quiz{ID: "quiz_old", AuthorInterpretation: "投稿者の解釈", AIInterpretation: "AIの解釈"}
+
+	options := service.GetOptions(quiz, "s")
+	assert.Len(t, options, 2)
+	for _, option := range options {
+		got, err := service.VerifyAnswer(quiz, "s", option.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, option.Text == quiz.AuthorInterpretation, got)
+	}
+}
+
+func TestSubmitAnswer(t *testing.T) {
+	quiz := newAnswerTestQuiz()
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("SaveAnswer", mock.Anything, mock.Anything).Return(nil)
+	service := NewQuizService(nil, mockStorage)
+
+	optionIDs := make(map[string]string)
+	for _, option := range service.GetOptions(quiz, "s") {
+		optionIDs[option.Text] = option.ID
+	}
+
+	answer, err := service.SubmitAnswer(context.Background(), quiz, &SubmitAnswerInput{
+		SessionID: "s",
+		OptionID:  optionIDs["AIの解釈2"],
+		PlayerID:  "player-1",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, answer.IsCorrect)
+	assert.Equal(t, 1, answer.DecoyIndex)
+	assert.Equal(t, "quiz_1", answer.QuizID)
+	assert.Equal(t, "player-1", answer.PlayerID)
+	assert.NotEmpty(t, answer.ID)
+	mockStorage.AssertCalled(t, "SaveAnswer", mock.Anything, answer)
+
+	answer, err = service.SubmitAnswer(context.Background(), quiz, &SubmitAnswerInput{
+		SessionID: "s",
+		OptionID:  optionIDs["投稿者の解釈"],
+		PlayerID:  "player-1",
+	})
+	if assert.NoError(t, err) {
+		assert.True(t, answer.IsCorrect)
+		assert.Equal(t, -1, answer.DecoyIndex)
+	}
+
+	// 存在しない選択肢は記録しない
+	_, err = service.SubmitAnswer(context.Background(), quiz, &SubmitAnswerInput{SessionID: "s", OptionID: "unknown", PlayerID: "player-1"})
+	assert.True(t, errors.Is(err, ErrUnknownOption))
+	mockStorage.AssertNumberOfCalls(t, "SaveAnswer", 2)
+}
+
+func TestSubmitAnswer_StorageError(t *testing.T) {
+	quiz := newAnswerTestQuiz()
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("SaveAnswer", mock.Anything, mock.Anything).Return(fmt.Errorf("storage error"))
+	service := NewQuizService(nil, mockStorage)
+
+	_, err := service.SubmitAnswer(context.Background(), quiz, &SubmitAnswerInput{
+		SessionID: "s",
+		OptionID:  service.CorrectOptionID(quiz, "s"),
+		PlayerID:  "player-1",
+	})
+	assert.Error(t, err)
+}
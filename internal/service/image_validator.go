@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"net/http"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/imaging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
 )
 
+// DefaultAllowedImageTypes は受け付ける画像形式が設定されていない場合の MIME タイプです
+// HEIC は変換コマンドが見つかり、デコーダーが登録されている場合のみ受け付けます
+var DefaultAllowedImageTypes = []string{imaging.MimeTypeJPEG, imaging.MimeTypePNG, imaging.MimeTypeGIF, imaging.MimeTypeWebP, imaging.MimeTypeHEIC}
+
 // ImageValidatorInterface は画像検証の機能を定義するインターフェース
 type ImageValidatorInterface interface {
-	ValidateAndCopy(file io.Reader, filename string) (*bytes.Buffer, error)
+	ValidateAndCopy(file io.Reader, filename string) (*bytes.Buffer, string, error)
 }
 
 // ImageValidator は画像の検証を行う構造体
@@ -22,46 +29,87 @@ type ImageValidator struct {
 }
 
 // NewImageValidator は新しいImageValidatorを作成します
-func NewImageValidator(maxFileSize int64) *ImageValidator {
-	return &ImageValidator{
-		maxFileSize: maxFileSize,
-		allowedMimeTypes: map[string]bool{
-			"image/jpeg": true,
-			"image/png":  true,
-		},
-		allowedExts: map[string]bool{
-			".jpg":  true,
-			".jpeg": true,
-			".png":  true,
-		},
+// allowedTypes は受け付ける画像の MIME タイプです（空の場合は DefaultAllowedImageTypes のうちデコーダーが登録されている形式）
+// デコーダーが登録されていない形式を指定した場合はエラーを返します
+func NewImageValidator(maxFileSize int64, allowedTypes []string) (*ImageValidator, error) {
+	if len(allowedTypes) == 0 {
+		for _, mimeType := range DefaultAllowedImageTypes {
+			if _, ok := imaging.LookupFormat(mimeType); !ok {
+				logging.Warn("デコーダーが登録されていないため、既定の画像形式から除きます: %s", mimeType)
+				continue
+			}
+			allowedTypes = append(allowedTypes, mimeType)
+		}
+	}
+	v := &ImageValidator{
+		maxFileSize:      maxFileSize,
+		allowedMimeTypes: make(map[string]bool, len(allowedTypes)),
+		allowedExts:      make(map[string]bool),
 	}
+	for _, mimeType := range allowedTypes {
+		format, ok := imaging.LookupFormat(mimeType)
+		if !ok {
+			return nil, fmt.Errorf("デコーダーが登録されていない画像形式です: %q (対応: %v)", mimeType, imaging.Formats())
+		}
+		v.allowedMimeTypes[mimeType] = true
+		for _, ext := range format.Extensions {
+			v.allowedExts[ext] = true
+		}
+	}
+	return v, nil
 }
 
 // ValidateAndCopy は画像を検証し、バッファにコピーします
-func (v *ImageValidator) ValidateAndCopy(file io.Reader, filename string) (*bytes.Buffer, error) {
+// 画像データの内容から判定した MIME タイプも返します（拡張子とは一致しない場合があります）
+func (v *ImageValidator) ValidateAndCopy(file io.Reader, filename string) (*bytes.Buffer, string, error) {
 	// 拡張子の検証
 	ext := strings.ToLower(filepath.Ext(filename))
 	if !v.allowedExts[ext] {
-		return nil, fmt.Errorf("unsupported file format: %s. Allowed formats: jpg, jpeg, png", ext)
+		return nil, "", fmt.Errorf("unsupported file format: %s. Allowed formats: %s", ext, v.allowedExtList())
 	}
 
 	// ファイルサイズの制限付きで読み込み
 	limitedReader := io.LimitReader(file, v.maxFileSize)
 	buf := new(bytes.Buffer)
 	if _, err := io.Copy(buf, limitedReader); err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// ファイルサイズのチェック
 	if int64(buf.Len()) >= v.maxFileSize {
-		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d bytes", v.maxFileSize)
+		return nil, "", fmt.Errorf("file size exceeds maximum allowed size of %d bytes", v.maxFileSize)
 	}
 
 	// MIMEタイプの検証
-	mimeType := http.DetectContentType(buf.Bytes())
+	mimeType := imaging.DetectType(buf.Bytes())
 	if !v.allowedMimeTypes[mimeType] {
-		return nil, fmt.Errorf("invalid file type: %s. Only jpeg and png are allowed", mimeType)
+		return nil, "", fmt.Errorf("invalid file type: %s. Allowed formats: %s", mimeType, v.allowedExtList())
+	}
+
+	// 画素数の大きい画像は、デコードで大量のメモリを確保する前に拒否する
+	if err := imaging.CheckDimensions(buf.Bytes(), mimeType); err != nil {
+		return nil, "", fmt.Errorf("invalid image: %w", err)
 	}
 
-	return buf, nil
+	return buf, mimeType, nil
+}
+
+// AllowedTypes は受け付ける画像の MIME タイプを返します
+func (v *ImageValidator) AllowedTypes() []string {
+	types := make([]string, 0, len(v.allowedMimeTypes))
+	for mimeType := range v.allowedMimeTypes {
+		types = append(types, mimeType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// allowedExtList は受け付ける拡張子をエラーメッセージ用に並べた文字列を返します
+func (v *ImageValidator) allowedExtList() string {
+	exts := make([]string, 0, len(v.allowedExts))
+	for ext := range v.allowedExts {
+		exts = append(exts, strings.TrimPrefix(ext, "."))
+	}
+	sort.Strings(exts)
+	return strings.Join(exts, ", ")
 }
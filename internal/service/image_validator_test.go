@@ -3,18 +3,33 @@ package service
 import (
 	"bytes"
 	"io"
+	"os"
 	"strings"
 	"testing"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/imaging"
 )
 
 func TestImageValidator_ValidateAndCopy(t *testing.T) {
-	validator := NewImageValidator(5 * 1024 * 1024) // 5MB
+	validator, err := NewImageValidator(5*1024*1024, nil) // 5MB
+	if err != nil {
+		t.Fatalf("NewImageValidator failed: %v", err)
+	}
+	pngData, err := os.ReadFile("../../fixture/generated/fake0.png")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+	webpData, err := os.ReadFile("../../fixture/formats/opaque.webp")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
 
 	tests := []struct {
-		name     string
-		file     io.Reader
-		filename string
-		wantErr  bool
+		name         string
+		file         io.Reader
+		filename     string
+		wantMimeType string
+		wantErr      bool
 	}{
 		{
 			name:     "invalid extension",
@@ -28,15 +43,99 @@ func TestImageValidator_ValidateAndCopy(t *testing.T) {
 			filename: "test.jpg",
 			wantErr:  true,
 		},
-		// 実際の画像ファイルを使用したテストケースは別途追加することをお勧めします
+		{
+			name:         "png",
+			file:         bytes.NewReader(pngData),
+			filename:     "fake0.png",
+			wantMimeType: "image/png",
+		},
+		{
+			name:         "png content with jpg extension",
+			file:         bytes.NewReader(pngData),
+			filename:     "fake0.jpg",
+			wantMimeType: "image/png",
+		},
+		{
+			name:         "gif",
+			file:         bytes.NewReader([]byte("GIF89a\x01\x00\x01\x00\x00\x00\x00")),
+			filename:     "anim.GIF",
+			wantMimeType: "image/gif",
+		},
+		{
+			name:         "webp",
+			file:         bytes.NewReader(webpData),
+			filename:     "photo.webp",
+			wantMimeType: "image/webp",
+		},
+		{
+			// 65535x65535 の画面を宣言するだけのヘッダー（デコードすると十数GBを確保する）
+			name:     "gif with huge dimensions",
+			file:     bytes.NewReader([]byte("GIF89a\xff\xff\xff\xff\x00\x00\x00")),
+			filename: "bomb.gif",
+			wantErr:  true,
+		},
+		{
+			name:     "text content with png extension",
+			file:     strings.NewReader("dummy data"),
+			filename: "test.png",
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := validator.ValidateAndCopy(tt.file, tt.filename)
+			_, mimeType, err := validator.ValidateAndCopy(tt.file, tt.filename)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateAndCopy() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if mimeType != tt.wantMimeType {
+				t.Errorf("ValidateAndCopy() mimeType = %q, want %q", mimeType, tt.wantMimeType)
+			}
 		})
 	}
 }
+
+func TestNewImageValidator(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedTypes []string
+		wantErr      bool
+	}{
+		{name: "default", allowedTypes: nil},
+		{name: "jpeg only", allowedTypes: []string{"image/jpeg"}},
+		{name: "jpeg and webp", allowedTypes: []string{"image/jpeg", "image/webp"}},
+		{name: "no decoder for avif", allowedTypes: []string{"image/jpeg", "image/avif"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewImageValidator(1024, tt.allowedTypes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewImageValidator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 既定の形式のうち、HEIC はデコーダーが登録されている場合のみ受け付ける
+	validator, err := NewImageValidator(1024, nil)
+	if err != nil {
+		t.Fatalf("NewImageValidator failed: %v", err)
+	}
+	_, heicRegistered := imaging.LookupFormat(imaging.MimeTypeHEIC)
+	want := "image/gif,image/jpeg,image/png,image/webp"
+	if heicRegistered {
+		want = "image/gif,image/heic,image/jpeg,image/png,image/webp"
+	}
+	if got := strings.Join(validator.AllowedTypes(), ","); got != want {
+		t.Errorf("AllowedTypes() = %s, want %s", got, want)
+	}
+
+	// 許可していない形式は拡張子で拒否する
+	validator, err = NewImageValidator(1024, []string{"image/jpeg"})
+	if err != nil {
+		t.Fatalf("NewImageValidator failed: %v", err)
+	}
+	if _, _, err := validator.ValidateAndCopy(strings.NewReader("\x89PNG\r\n\x1a\n"), "test.png"); err == nil {
+		t.Error("expected png to be rejected, got nil")
+	}
+}
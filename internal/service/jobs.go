@@ -0,0 +1,453 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+const (
+	// DefaultJobWorkers はクイズ作成ジョブを同時に実行する既定の数です
+	DefaultJobWorkers = 2
+	// DefaultJobQueueSize は実行を待てるジョブの既定の数です
+	DefaultJobQueueSize = 100
+	// DefaultJobLease はインスタンスが実行中のジョブを占有する既定の期間です
+	// 実行中はこの3分の1ごとに延長し、延長されないまま期間を過ぎたジョブは他のインスタンスが再開します
+	DefaultJobLease = time.Minute
+	// maxJobAttempts はサーバーの停止で中断したジョブを実行し直す最大回数です
+	maxJobAttempts = 3
+	// jobRetention は終了したジョブの状態を残しておく期間です
+	jobRetention = 24 * time.Hour
+)
+
+// ErrJobQueueFull は実行を待っているジョブが多すぎて受け付けられないことを表します
+var ErrJobQueueFull = errors.New("ジョブが混み合っています")
+
+var (
+	// errJobNotPending は実行を待っていないジョブを実行しようとしたことを表します
+	errJobNotPending = errors.New("job is not pending")
+	// errJobLeaseHeld は他のインスタンスが占有しているジョブを再開しようとしたことを表します
+	errJobLeaseHeld = errors.New("job lease is held by another runner")
+	// errJobLeaseLost は実行中のジョブの占有を他のインスタンスに奪われたことを表します
+	errJobLeaseLost = errors.New("job lease was lost")
+)
+
+// JobQueue はクイズを非同期に作成するジョブの操作を提供するインターフェース
+type JobQueue interface {
+	// Enqueue はクイズ作成ジョブを登録し、実行を待つ状態のジョブを返します
+	Enqueue(ctx context.Context, input *CreateQuizInput) (*models.Job, error)
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+}
+
+// JobRunner はクイズ作成ジョブをワーカーで実行します
+// ジョブはストレージに保存するため、実行中にサーバーが停止しても占有の期限が切れた後に再開します
+// 複数のインスタンスで同じストレージを使う場合も、ジョブの開始と再開は世代番号を条件にした更新で行うため、同じジョブを同時に実行しません
+type JobRunner struct {
+	quizService QuizService
+	store       storage.JobStore
+	workers     int
+	queue       chan string
+	// id はジョブの占有者として記録する、このインスタンスのIDです
+	id    string
+	lease time.Duration
+	now   func() time.Time
+	wg    sync.WaitGroup
+}
+
+// JobOption はJobRunnerの設定を変更する関数です
+type JobOption func(*JobRunner)
+
+// WithJobWorkers はジョブを同時に実行する数を設定します（0 以下の場合は DefaultJobWorkers）
+func WithJobWorkers(n int) JobOption {
+	return func(r *JobRunner) {
+		if n > 0 {
+			r.workers = n
+		}
+	}
+}
+
+// WithJobQueueSize は実行を待てるジョブの数を設定します（0 以下の場合は DefaultJobQueueSize）
+func WithJobQueueSize(n int) JobOption {
+	return func(r *JobRunner) {
+		if n > 0 {
+			r.queue = make(chan string, n)
+		}
+	}
+}
+
+// WithJobLease は実行中のジョブを占有する期間を設定します（0 以下の場合は DefaultJobLease）
+// インスタンスが停止してからこの期間が過ぎると、他のインスタンスがジョブを再開します
+func WithJobLease(d time.Duration) JobOption {
+	return func(r *JobRunner) {
+		if d > 0 {
+			r.lease = d
+		}
+	}
+}
+
+// NewJobRunner は新しいJobRunnerを作成します
+// ジョブを実行するには Start を呼び出す必要があります
+func NewJobRunner(quizService QuizService, store storage.JobStore, opts ...JobOption) *JobRunner {
+	r := &JobRunner{
+		quizService: quizService,
+		store:       store,
+		workers:     DefaultJobWorkers,
+		queue:       make(chan string, DefaultJobQueueSize),
+		id:          "runner_" + hex.EncodeToString(randomBytes(8)),
+		lease:       DefaultJobLease,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Start はワーカーを起動し、前回の起動時に終了しなかったジョブを再開します
+// 起動後も占有の期間ごとにジョブを見直し、停止した他のインスタンスのジョブを再開します
+// ワーカーは ctx がキャンセルされると停止します（実行中のジョブは占有の期限が切れた後に再開します）
+func (r *JobRunner) Start(ctx context.Context) error {
+	resume, err := r.recoverJobs(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go r.work(ctx)
+	}
+	logging.Info("JobRunner: ワーカーを起動しました: id=%s, workers=%d, 再開するジョブ=%d", r.id, r.workers, len(resume))
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.resume(ctx, resume)
+
+		ticker := time.NewTicker(r.lease)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			ids, err := r.recoverJobs(ctx, false)
+			if err != nil {
+				logging.Warn("JobRunner: ジョブの見直しに失敗: err=%v", err)
+				continue
+			}
+			r.resume(ctx, ids)
+		}
+	}()
+	return nil
+}
+
+// Wait は Start で起動したワーカーがすべて停止するまで待ちます
+func (r *JobRunner) Wait() {
+	r.wg.Wait()
+}
+
+// resume は再開するジョブをキューに登録します
+// 再開するジョブがキューに収まらない場合に備え、ワーカーの処理を待ちながら登録します
+func (r *JobRunner) resume(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		select {
+		case r.queue <- id:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recoverJobs は保存されているジョブを整理し、再開するジョブのIDを返します
+// startup が false の場合、実行を待っているジョブは他のインスタンスのキューにある可能性があるため、占有の期間を過ぎても開始されていないものだけを再開します
+func (r *JobRunner) recoverJobs(ctx context.Context, startup bool) ([]string, error) {
+	jobs, err := r.store.ListJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ジョブの一覧の取得に失敗: %w", err)
+	}
+	var ids []string
+	for _, job := range jobs {
+		if job.Status == models.JobPending && !startup && r.now().Sub(job.UpdatedAt) < r.lease {
+			continue
+		}
+		if id, ok := r.recoverJob(ctx, job); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// recoverJob はジョブを整理し、再開するジョブのIDを返します
+// 終了してから jobRetention を過ぎたジョブは削除し、占有の期限が切れた実行中のジョブは実行を待つ状態に戻します
+// 期限が切れていない実行中のジョブは、他のインスタンスが実行しているため何もしません
+func (r *JobRunner) recoverJob(ctx context.Context, job *models.Job) (string, bool) {
+	switch {
+	case job.Status.Done():
+		if r.now().Sub(job.UpdatedAt) > jobRetention {
+			if err := r.store.DeleteJob(ctx, job.ID); err != nil {
+				logging.Warn("JobRunner: 古いジョブの削除に失敗: id=%s, err=%v", job.ID, err)
+			}
+		}
+		return "", false
+	case job.Status == models.JobRunning:
+		if !job.LeaseExpired(r.now()) {
+			return "", false
+		}
+		updated, err := r.store.UpdateJob(ctx, job.ID, func(job *models.Job) error {
+			// 一覧を読んでから占有が延長された場合や、他のインスタンスが先に再開した場合は何もしない
+			if job.Status != models.JobRunning || !job.LeaseExpired(r.now()) {
+				return errJobLeaseHeld
+			}
+			job.UpdatedAt = r.now()
+			job.Owner = ""
+			job.LeaseExpiresAt = time.Time{}
+			if job.Attempts >= maxJobAttempts {
+				job.Status = models.JobFailed
+				job.Error = "処理中にサーバーが停止しました"
+				return nil
+			}
+			job.Status = models.JobPending
+			return nil
+		})
+		if errors.Is(err, errJobLeaseHeld) {
+			return "", false
+		}
+		if err != nil {
+			logging.Error("JobRunner: 中断したジョブの再開に失敗: id=%s, err=%v", job.ID, err)
+			return "", false
+		}
+		if updated.Status == models.JobFailed {
+			r.deleteImage(ctx, updated.ID)
+			return "", false
+		}
+		logging.Info("JobRunner: 中断したジョブを再開します: id=%s, attempts=%d, 前の実行者=%s", job.ID, updated.Attempts, job.Owner)
+		return job.ID, true
+	default:
+		return job.ID, true
+	}
+}
+
+// Enqueue はクイズ作成ジョブを保存し、ワーカーのキューに登録します
+// 解釈・タイトル・タグはここで検証するため、不正な場合はジョブを作成せずに ErrInvalidInterpretation / ErrInvalidTitle / ErrInvalidTag を返します
+func (r *JobRunner) Enqueue(ctx context.Context, input *CreateQuizInput) (*models.Job, error) {
+	if len(input.ImageData) == 0 {
+		return nil, fmt.Errorf("画像データが必要です")
+	}
+	if err := validateInterpretation(input.AuthorInterpretation); err != nil {
+		return nil, err
+	}
+	if _, err := normalizeTitle(input.Title); err != nil {
+		return nil, err
+	}
+	if _, err := normalizeTags(input.Tags); err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	job := &models.Job{
+		ID:       generateJobID(),
+		Status:   models.JobPending,
+		AuthorID: input.AuthorID,
+		Input: models.JobInput{
+			ImageMimeType:        input.ImageMimeType,
+			AuthorInterpretation: input.AuthorInterpretation,
+			Title:                input.Title,
+			DecoyCount:           input.DecoyCount,
+			AuthorName:           input.AuthorName,
+			Tags:                 input.Tags,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := r.store.SaveJob(ctx, job, input.ImageData); err != nil {
+		return nil, fmt.Errorf("ジョブの保存に失敗: %w", err)
+	}
+
+	select {
+	case r.queue <- job.ID:
+	default:
+		// 登録できなかったジョブは実行されないため、失敗として記録する
+		logging.Warn("Enqueue: キューが満杯のためジョブを受け付けません: id=%s", job.ID)
+		r.finish(ctx, job.ID, func(job *models.Job) {
+			job.Status = models.JobFailed
+			job.Error = ErrJobQueueFull.Error()
+		})
+		return nil, ErrJobQueueFull
+	}
+	logging.Info("Enqueue: ジョブを登録しました: id=%s, author=%s", job.ID, job.AuthorID)
+	return job, nil
+}
+
+// GetJob はジョブを取得します
+func (r *JobRunner) GetJob(ctx context.Context, jobID string) (*models.Job, error) {
+	return r.store.GetJob(ctx, jobID)
+}
+
+// work はキューからジョブを取り出して実行するワーカーです
+func (r *JobRunner) work(ctx context.Context) {
+	defer r.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-r.queue:
+			r.run(ctx, id)
+		}
+	}
+}
+
+// run はジョブを1つ実行します
+// ジョブの開始は世代番号を条件にした更新で行うため、同じジョブを複数のインスタンスが開始することはありません
+func (r *JobRunner) run(ctx context.Context, jobID string) {
+	job, err := r.store.UpdateJob(ctx, jobID, func(job *models.Job) error {
+		if job.Status != models.JobPending {
+			return errJobNotPending
+		}
+		job.Status = models.JobRunning
+		job.Attempts++
+		job.Owner = r.id
+		job.LeaseExpiresAt = r.now().Add(r.lease)
+		job.UpdatedAt = r.now()
+		return nil
+	})
+	if errors.Is(err, errJobNotPending) {
+		logging.Debug("JobRunner: 実行を待っていないジョブを読み飛ばします: id=%s", jobID)
+		return
+	}
+	if err != nil {
+		logging.Error("JobRunner: ジョブの開始に失敗: id=%s, err=%v", jobID, err)
+		return
+	}
+	logging.Info("JobRunner: ジョブを開始します: id=%s, attempts=%d", jobID, job.Attempts)
+
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	defer cancelJob()
+	stopHeartbeat := r.heartbeat(jobCtx, jobID, cancelJob)
+	quiz, err := r.createQuiz(jobCtx, job)
+	stopHeartbeat()
+	if err != nil && ctx.Err() != nil {
+		// サーバーの停止で中断したジョブは実行中のまま残し、占有の期限が切れた後に再開する
+		logging.Warn("JobRunner: サーバーの停止によりジョブを中断しました: id=%s", jobID)
+		return
+	}
+	if err != nil && jobCtx.Err() != nil {
+		logging.Warn("JobRunner: 占有を失ったためジョブを中断しました: id=%s", jobID)
+		return
+	}
+	if err != nil {
+		logging.Error("JobRunner: クイズの作成に失敗: id=%s, err=%v", jobID, err)
+		r.finish(ctx, jobID, func(job *models.Job) {
+			job.Status = models.JobFailed
+			job.Error = err.Error()
+		})
+		return
+	}
+	// 作成したクイズを記録し損ねると次の起動時に作り直してしまうため、停止中でも保存する
+	r.finish(context.WithoutCancel(ctx), jobID, func(job *models.Job) {
+		job.Status = models.JobSucceeded
+		job.QuizID = quiz.ID
+	})
+	logging.Info("JobRunner: ジョブが完了しました: id=%s, quiz=%s", jobID, quiz.ID)
+}
+
+// createQuiz はジョブの入力でクイズを作成します
+func (r *JobRunner) createQuiz(ctx context.Context, job *models.Job) (*models.Quiz, error) {
+	imageData, err := r.store.GetJobImage(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	return r.quizService.CreateQuiz(ctx, &CreateQuizInput{
+		ImageData:            imageData,
+		ImageMimeType:        job.Input.ImageMimeType,
+		AuthorInterpretation: job.Input.AuthorInterpretation,
+		Title:                job.Input.Title,
+		DecoyCount:           job.Input.DecoyCount,
+		AuthorID:             job.AuthorID,
+		AuthorName:           job.Input.AuthorName,
+		Tags:                 job.Input.Tags,
+	})
+}
+
+// heartbeat は実行中のジョブの占有を定期的に延長します
+// 占有を他のインスタンスに奪われた場合は lost を呼び出してジョブの実行を打ち切ります
+// 返した関数を呼び出すと延長をやめ、延長の処理が終わるまで待ちます
+func (r *JobRunner) heartbeat(ctx context.Context, jobID string, lost context.CancelFunc) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(r.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			_, err := r.store.UpdateJob(ctx, jobID, func(job *models.Job) error {
+				if job.Status != models.JobRunning || job.Owner != r.id {
+					return errJobLeaseLost
+				}
+				job.LeaseExpiresAt = r.now().Add(r.lease)
+				return nil
+			})
+			if errors.Is(err, errJobLeaseLost) {
+				logging.Warn("JobRunner: ジョブの占有を失いました: id=%s", jobID)
+				lost()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				// 延長できないまま期限が切れた場合は、他のインスタンスが再開する
+				logging.Warn("JobRunner: ジョブの占有の延長に失敗: id=%s, err=%v", jobID, err)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// finish はジョブを終了した状態にして、ジョブの画像を削除します
+// 他のインスタンスが占有している実行中のジョブは変更しません
+func (r *JobRunner) finish(ctx context.Context, jobID string, mutate func(job *models.Job)) {
+	_, err := r.store.UpdateJob(ctx, jobID, func(job *models.Job) error {
+		if job.Status == models.JobRunning && job.Owner != r.id {
+			return errJobLeaseLost
+		}
+		mutate(job)
+		job.Owner = ""
+		job.LeaseExpiresAt = time.Time{}
+		job.UpdatedAt = r.now()
+		return nil
+	})
+	if errors.Is(err, errJobLeaseLost) {
+		logging.Warn("JobRunner: 占有を失ったジョブの結果を破棄します: id=%s", jobID)
+		return
+	}
+	if err != nil {
+		logging.Error("JobRunner: ジョブの状態の保存に失敗: id=%s, err=%v", jobID, err)
+		return
+	}
+	r.deleteImage(ctx, jobID)
+}
+
+// deleteImage は終了したジョブの画像を削除します（状態は残します）
+func (r *JobRunner) deleteImage(ctx context.Context, jobID string) {
+	if err := r.store.DeleteJobImage(ctx, jobID); err != nil {
+		logging.Warn("JobRunner: ジョブの画像の削除に失敗: id=%s, err=%v", jobID, err)
+	}
+}
+
+// generateJobID は推測できないジョブIDを生成します
+func generateJobID() string {
+	return "job_" + hex.EncodeToString(randomBytes(16))
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// fakeQuizCreator は CreateQuiz だけを差し替えた QuizService です
+type fakeQuizCreator struct {
+	QuizService
+	create func(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error)
+}
+
+func (f *fakeQuizCreator) CreateQuiz(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error) {
+	return f.create(ctx, input)
+}
+
+func newTestJobStore(t *testing.T) *storage.LocalClient {
+	t.Helper()
+	store, err := storage.NewLocalClient(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	return store
+}
+
+// waitJob はジョブが want の状態になるまで待ちます
+func waitJob(t *testing.T, store storage.JobStore, jobID string, want models.JobStatus) *models.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := store.GetJob(context.Background(), jobID)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		if job.Status == want {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s: expected status %s, got %s", jobID, want, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitJobImageDeleted はジョブの画像が削除されるまで待ちます
+// 画像はジョブの状態を保存した後に削除するため、状態の変化だけでは削除を確認できません
+func waitJobImageDeleted(t *testing.T, store storage.JobStore, jobID string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := store.GetJobImage(context.Background(), jobID)
+		if errors.Is(err, storage.ErrJobNotFound) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s: expected job image to be deleted, got %v", jobID, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobRunner(t *testing.T) {
+	store := newTestJobStore(t)
+	creator := &fakeQuizCreator{create: func(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error) {
+		if input.AuthorInterpretation == "失敗" {
+			return nil, errors.New("AI error")
+		}
+		if string(input.ImageData) != "image" || input.ImageMimeType != "image/png" || input.AuthorID != "author-1" || input.DecoyCount != 2 {
+			return nil, fmt.Errorf("unexpected input: %+v", input)
+		}
+		return &models.Quiz{ID: "quiz_1"}, nil
+	}}
+	runner := NewJobRunner(creator, store, WithJobWorkers(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		runner.Wait()
+	}()
+	if err := runner.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	job, err := runner.Enqueue(ctx, &CreateQuizInput{
+		ImageData:            []byte("image"),
+		ImageMimeType:        "image/png",
+		AuthorInterpretation: "解釈",
+		AuthorID:             "author-1",
+		DecoyCount:           2,
+	})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if job.Status != models.JobPending {
+		t.Errorf("expected pending, got %s", job.Status)
+	}
+	done := waitJob(t, store, job.ID, models.JobSucceeded)
+	if done.QuizID != "quiz_1" || done.Attempts != 1 {
+		t.Errorf("unexpected job: %+v", done)
+	}
+	// 終了したジョブの画像は削除する
+	waitJobImageDeleted(t, store, job.ID)
+
+	job, err = runner.Enqueue(ctx, &CreateQuizInput{ImageData: []byte("image"), AuthorInterpretation: "失敗", AuthorID: "author-1"})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	failed := waitJob(t, store, job.ID, models.JobFailed)
+	if failed.Error != "AI error" || failed.QuizID != "" {
+		t.Errorf("unexpected job: %+v", failed)
+	}
+
+	// 解釈・タイトル・タグは登録時に検証する
+	tags := make([]string, MaxTags+1)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag%d", i)
+	}
+	if _, err := runner.Enqueue(ctx, &CreateQuizInput{ImageData: []byte("image"), AuthorInterpretation: "解釈", Tags: tags}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+	tooLong := strings.Repeat("長", MaxInterpretationLength+1)
+	if _, err := runner.Enqueue(ctx, &CreateQuizInput{ImageData: []byte("image"), AuthorInterpretation: tooLong}); !errors.Is(err, ErrInvalidInterpretation) {
+		t.Errorf("expected ErrInvalidInterpretation, got %v", err)
+	}
+}
+
+func TestJobRunner_ResumesAfterRestart(t *testing.T) {
+	store := newTestJobStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	save := func(id string, status models.JobStatus, attempts int, updatedAt time.Time) {
+		job := &models.Job{ID: id, Status: status, AuthorID: "author-1", Attempts: attempts, CreatedAt: updatedAt, UpdatedAt: updatedAt}
+		if err := store.SaveJob(ctx, job, []byte("image")); err != nil {
+			t.Fatalf("SaveJob failed: %v", err)
+		}
+	}
+	// 前回の起動時に残ったジョブ
+	save("job_pending", models.JobPending, 0, now)
+	save("job_running", models.JobRunning, 1, now)
+	save("job_exhausted", models.JobRunning, maxJobAttempts, now)
+	save("job_recent", models.JobSucceeded, 1, now)
+	save("job_old", models.JobSucceeded, 1, now.Add(-2*jobRetention))
+	// 他のインスタンスが実行中で、占有の期限が切れていないジョブ
+	leased := &models.Job{ID: "job_leased", Status: models.JobRunning, AuthorID: "author-1", Attempts: 1, Owner: "runner_other", LeaseExpiresAt: now.Add(time.Hour), CreatedAt: now, UpdatedAt: now}
+	if err := store.SaveJob(ctx, leased, []byte("image")); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+
+	creator := &fakeQuizCreator{create: func(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error) {
+		return &models.Quiz{ID: "quiz_resumed"}, nil
+	}}
+	runner := NewJobRunner(creator, store, WithJobWorkers(2))
+	runCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		runner.Wait()
+	}()
+	if err := runner.Start(runCtx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	waitJob(t, store, "job_pending", models.JobSucceeded)
+	if job := waitJob(t, store, "job_running", models.JobSucceeded); job.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", job.Attempts)
+	}
+	if job := waitJob(t, store, "job_exhausted", models.JobFailed); job.Error == "" {
+		t.Error("expected error message for exhausted job")
+	}
+	waitJob(t, store, "job_recent", models.JobSucceeded)
+	if _, err := store.GetJob(ctx, "job_old"); !errors.Is(err, storage.ErrJobNotFound) {
+		t.Errorf("expected old job to be deleted, got %v", err)
+	}
+	if job := waitJob(t, store, "job_leased", models.JobRunning); job.Owner != "runner_other" || job.Attempts != 1 {
+		t.Errorf("expected leased job to be left to its owner, got %+v", job)
+	}
+}
+
+func TestJobRunner_RecoversExpiredLease(t *testing.T) {
+	store := newTestJobStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// 起動した時点では他のインスタンスが占有しているが、その後延長されずに期限が切れるジョブ
+	job := &models.Job{ID: "job_orphaned", Status: models.JobRunning, AuthorID: "author-1", Attempts: 1, Owner: "runner_stopped", LeaseExpiresAt: now.Add(50 * time.Millisecond), CreatedAt: now, UpdatedAt: now}
+	if err := store.SaveJob(ctx, job, []byte("image")); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+
+	creator := &fakeQuizCreator{create: func(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error) {
+		return &models.Quiz{ID: "quiz_recovered"}, nil
+	}}
+	runner := NewJobRunner(creator, store, WithJobWorkers(1), WithJobLease(30*time.Millisecond))
+	runCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		runner.Wait()
+	}()
+	if err := runner.Start(runCtx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	done := waitJob(t, store, job.ID, models.JobSucceeded)
+	if done.Attempts != 2 || done.QuizID != "quiz_recovered" || done.Owner != "" {
+		t.Errorf("unexpected job: %+v", done)
+	}
+}
+
+func TestJobRunner_LeaseLost(t *testing.T) {
+	store := newTestJobStore(t)
+	started := make(chan string)
+	stopped := make(chan error, 1)
+	creator := &fakeQuizCreator{create: func(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error) {
+		started <- input.AuthorInterpretation
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	}}
+	runner := NewJobRunner(creator, store, WithJobWorkers(1), WithJobLease(30*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		runner.Wait()
+	}()
+	if err := runner.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	job, err := runner.Enqueue(ctx, &CreateQuizInput{ImageData: []byte("image"), AuthorInterpretation: "解釈", AuthorID: "author-1"})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	<-started
+
+	// 延長が遅れた間に他のインスタンスがジョブを再開した
+	if _, err := store.UpdateJob(context.Background(), job.ID, func(job *models.Job) error {
+		job.Owner = "runner_other"
+		job.LeaseExpiresAt = time.Now().Add(time.Hour)
+		return nil
+	}); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+
+	// 占有を失ったインスタンスは実行を打ち切り、他のインスタンスの実行を上書きしない
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the job to be canceled after losing the lease")
+	}
+	got := waitJob(t, store, job.ID, models.JobRunning)
+	if got.Owner != "runner_other" || got.Error != "" {
+		t.Errorf("expected job to be left to the new owner, got %+v", got)
+	}
+}
+
+func TestJobRunner_Shutdown(t *testing.T) {
+	store := newTestJobStore(t)
+	started := make(chan struct{})
+	creator := &fakeQuizCreator{create: func(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	runner := NewJobRunner(creator, store, WithJobWorkers(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := runner.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	job, err := runner.Enqueue(ctx, &CreateQuizInput{ImageData: []byte("image"), AuthorInterpretation: "解釈", AuthorID: "author-1"})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	<-started
+	cancel()
+	runner.Wait()
+
+	// 停止で中断したジョブは失敗にせず、次の起動時に再開できるよう画像とともに残す
+	got, err := store.GetJob(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if got.Status != models.JobRunning {
+		t.Errorf("expected running, got %s", got.Status)
+	}
+	if got.Owner == "" || !got.LeaseExpiresAt.After(got.UpdatedAt) {
+		t.Errorf("expected the job to record its owner and lease, got %+v", got)
+	}
+	if _, err := store.GetJobImage(context.Background(), job.ID); err != nil {
+		t.Errorf("expected job image to remain, got %v", err)
+	}
+}
+
+func TestJobRunner_QueueFull(t *testing.T) {
+	store := newTestJobStore(t)
+	// ワーカーを起動せずに、キューを埋める
+	runner := NewJobRunner(&fakeQuizCreator{}, store, WithJobQueueSize(1))
+	ctx := context.Background()
+
+	if _, err := runner.Enqueue(ctx, &CreateQuizInput{ImageData: []byte("image"), AuthorInterpretation: "解釈", AuthorID: "author-1"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, err := runner.Enqueue(ctx, &CreateQuizInput{ImageData: []byte("image"), AuthorInterpretation: "解釈", AuthorID: "author-1"}); !errors.Is(err, ErrJobQueueFull) {
+		t.Errorf("expected ErrJobQueueFull, got %v", err)
+	}
+
+	jobs, err := store.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	counts := make(map[models.JobStatus]int)
+	for _, job := range jobs {
+		counts[job.Status]++
+	}
+	if counts[models.JobPending] != 1 || counts[models.JobFailed] != 1 {
+		t.Errorf("expected one pending and one failed job, got %v", counts)
+	}
+}
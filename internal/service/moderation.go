@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/moderation"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+const (
+	// MaxReportReasonLength は報告の理由の最大文字数です
+	MaxReportReasonLength = 500
+	// ReportFlagThreshold は公開中のクイズの公開を自動で保留にする、ログインした利用者からの未確認の報告の数です
+	// 匿名の報告は同じプレイヤーが何件でも送れるため数えません
+	ReportFlagThreshold = 3
+	// MaxPendingReports は1つのクイズに記録する未確認の報告の上限です
+	MaxPendingReports = 50
+	// MaxAnonymousPendingReports は1つのクイズに記録する、匿名のプレイヤーからの未確認の報告の上限です
+	// 匿名の報告だけで上限が埋まり、ログインした利用者が報告できなくならないよう MaxPendingReports より小さくします
+	MaxAnonymousPendingReports = 10
+)
+
+var (
+	// ErrContentRejected は投稿の内容が利用規約に違反しているため受け付けないことを表します
+	ErrContentRejected = errors.New("投稿の内容が利用規約に違反しています")
+	// ErrAuthorBanned は投稿者が利用停止されているためクイズを作成できないことを表します
+	ErrAuthorBanned = errors.New("投稿者は利用停止されています")
+	// ErrInvalidReview は管理者による審査の内容が不正であることを表します
+	ErrInvalidReview = errors.New("審査の内容が不正です")
+	// ErrInvalidReport はクイズの報告の内容が不正であることを表します
+	ErrInvalidReport = errors.New("報告の内容が不正です")
+	// ErrAlreadyReported は同じ利用者がすでにクイズを報告していることを表します
+	ErrAlreadyReported = errors.New("このクイズはすでに報告済みです")
+	// ErrReportLimitReached はクイズの未確認の報告が上限に達しているため、報告を記録できないことを表します
+	ErrReportLimitReached = errors.New("このクイズへの報告は上限に達しています")
+)
+
+// WithModerator はクイズの作成・編集時に内容を審査する Classifier を設定します
+// 設定しない場合は審査せずに公開します
+func WithModerator(classifier moderation.Classifier) Option {
+	return func(s *QuizServiceImpl) {
+		s.moderator = classifier
+	}
+}
+
+// WithBanStore は利用停止された投稿者のクイズの作成を拒否するため、利用停止の記録の保存先を設定します
+// 設定しない場合は利用停止を確認しません
+func WithBanStore(bans storage.BanStore) Option {
+	return func(s *QuizServiceImpl) {
+		s.bans = bans
+	}
+}
+
+// checkBanned は投稿者が利用停止されている場合に ErrAuthorBanned を返します
+func (s *QuizServiceImpl) checkBanned(ctx context.Context, authorID string) error {
+	if s.bans == nil || authorID == "" {
+		return nil
+	}
+	ban, err := s.bans.GetAuthorBan(ctx, authorID)
+	if errors.Is(err, storage.ErrBanNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("利用停止の確認に失敗: %w", err)
+	}
+	logging.Warn("checkBanned: 利用停止された投稿者です: author=%s, since=%s", authorID, ban.CreatedAt.Format(time.RFC3339))
+	return ErrAuthorBanned
+}
+
+// moderate は投稿の内容を審査し、クイズの審査の状態と理由を返します
+// Reject と判定された場合は ErrContentRejected を返します
+// 審査自体に失敗した場合は投稿を止めず、管理者が確認するまで公開を保留します
+func (s *QuizServiceImpl) moderate(ctx context.Context, content *moderation.Content) (models.QuizStatus, []string, error) {
+	if s.moderator == nil {
+		return models.QuizApproved, nil, nil
+	}
+
+	result, err := s.moderator.Classify(ctx, content)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", nil, err
+		}
+		logging.Error("moderate: 審査に失敗したため公開を保留します: %v", err)
+		return models.QuizFlagged, []string{"審査に失敗しました"}, nil
+	}
+
+	switch result.Decision {
+	case moderation.Reject:
+		logging.Warn("moderate: 投稿を拒否しました: reasons=%v", result.Reasons)
+		return "", nil, fmt.Errorf("%w: %s", ErrContentRejected, strings.Join(result.Reasons, "、"))
+	case moderation.Flag:
+		logging.Info("moderate: 投稿の公開を保留しました: reasons=%v", result.Reasons)
+		return models.QuizFlagged, result.Reasons, nil
+	}
+	return models.QuizApproved, nil, nil
+}
+
+// moderateDecoys はAIが生成したおとりの解釈を審査し、投稿者の内容の審査結果とまとめます
+// おとりの解釈もプレイヤーに表示されるため、投稿者の内容と同じ基準で拒否・保留します
+func (s *QuizServiceImpl) moderateDecoys(ctx context.Context, decoys []string, status models.QuizStatus, reasons []string) (models.QuizStatus, []string, error) {
+	decoyStatus, decoyReasons, err := s.moderate(ctx, &moderation.Content{Texts: decoys})
+	if err != nil {
+		return "", nil, err
+	}
+	if decoyStatus != models.QuizFlagged {
+		return status, reasons, nil
+	}
+	for _, reason := range decoyReasons {
+		if !slices.Contains(reasons, reason) {
+			reasons = append(reasons, reason)
+		}
+	}
+	return models.QuizFlagged, reasons, nil
+}
+
+// ModerationQueue は管理者の確認を待っているクイズの1ページ分です
+type ModerationQueue struct {
+	Quizzes []*models.Quiz
+	// NextCursor は次のページを取得するためのカーソルです（最後のページでは空）
+	NextCursor string
+}
+
+// ReviewAction は管理者による審査の操作です
+type ReviewAction string
+
+const (
+	// ReviewApprove はクイズを公開します
+	ReviewApprove ReviewAction = "approve"
+	// ReviewReject はクイズの公開を拒否します
+	ReviewReject ReviewAction = "reject"
+	// ReviewBanAuthor はクイズの投稿者を利用停止にし、投稿者のすべてのクイズの公開を拒否します
+	ReviewBanAuthor ReviewAction = "ban-author"
+)
+
+// ReviewInput は管理者による審査の入力を表します
+type ReviewInput struct {
+	Action ReviewAction
+	// ReviewerID は審査した管理者のIDです
+	ReviewerID string
+	// Reason は拒否や利用停止の理由です（省略可）
+	Reason string
+}
+
+// ReviewResult は管理者による審査の結果を表します
+type ReviewResult struct {
+	// Quiz は審査したクイズの審査後の内容です
+	Quiz *models.Quiz
+	// Ban は ReviewBanAuthor の場合の利用停止の記録です
+	Ban *models.AuthorBan
+	// RejectedQuizzes は ReviewBanAuthor で公開を拒否した投稿者のクイズの数です
+	RejectedQuizzes int
+}
+
+// ReportInput はクイズの報告の入力を表します
+type ReportInput struct {
+	// ReporterID は報告した利用者のIDです（未ログインの場合は models.AnonymousReporterPrefix を付けた匿名のプレイヤーID）
+	ReporterID string
+	Reason     string
+}
+
+// ModerationService は管理者によるクイズの審査と、プレイヤーからの報告の操作を提供するインターフェース
+type ModerationService interface {
+	// ListPending は管理者の確認を待っているクイズを古い順に1ページ分返します
+	ListPending(ctx context.Context, limit int, cursor string) (*ModerationQueue, error)
+	Review(ctx context.Context, quizID string, input *ReviewInput) (*ReviewResult, error)
+	ReportQuiz(ctx context.Context, quizID string, input *ReportInput) (*models.Quiz, error)
+}
+
+// ModerationServiceImpl は審査と報告の操作を実装します
+type ModerationServiceImpl struct {
+	storageClient storage.StorageClient
+	bans          storage.BanStore
+	now           func() time.Time
+}
+
+// NewModerationService は新しいModerationServiceインスタンスを作成します
+func NewModerationService(storageClient storage.StorageClient, bans storage.BanStore) ModerationService {
+	return &ModerationServiceImpl{
+		storageClient: storageClient,
+		bans:          bans,
+		now:           time.Now,
+	}
+}
+
+// ListPending は審査で公開が保留されたクイズと、未確認の報告があるクイズを古い順に返します
+func (s *ModerationServiceImpl) ListPending(ctx context.Context, limit int, cursor string) (*ModerationQueue, error) {
+	switch {
+	case limit <= 0:
+		limit = DefaultPageSize
+	case limit > MaxPageSize:
+		limit = MaxPageSize
+	}
+	page, err := s.storageClient.ListQuizzes(ctx, &models.QuizListQuery{
+		IncludeHidden: true,
+		NeedsReview:   true,
+		Order:         models.SortOldestFirst,
+		Limit:         limit,
+		Cursor:        cursor,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("審査待ちのクイズの取得に失敗: %w", err)
+	}
+
+	// 理由や報告はインデックスに含まれないため、クイズ本体を読み込む
+	queue := &ModerationQueue{Quizzes: make([]*models.Quiz, 0, len(page.Quizzes)), NextCursor: page.NextCursor}
+	for _, summary := range page.Quizzes {
+		quiz, err := s.storageClient.GetQuiz(ctx, summary.ID)
+		if errors.Is(err, storage.ErrQuizNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("クイズの取得に失敗: %w", err)
+		}
+		queue.Quizzes = append(queue.Quizzes, quiz)
+	}
+	return queue, nil
+}
+
+// Review は管理者の判断でクイズを公開、または公開を拒否します
+// 審査したクイズの報告は確認済みになり、審査待ちの一覧から外れます
+func (s *ModerationServiceImpl) Review(ctx context.Context, quizID string, input *ReviewInput) (*ReviewResult, error) {
+	if input == nil || input.ReviewerID == "" {
+		return nil, fmt.Errorf("%w: 審査した管理者が必要です", ErrInvalidReview)
+	}
+	reason := strings.TrimSpace(input.Reason)
+
+	switch input.Action {
+	case ReviewApprove:
+		quiz, err := s.review(ctx, quizID, input.ReviewerID, func(quiz *models.Quiz) {
+			quiz.Status = models.QuizApproved
+			quiz.ModerationReasons = nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		logging.Info("Review: クイズを公開しました: id=%s, reviewer=%s", quizID, input.ReviewerID)
+		return &ReviewResult{Quiz: quiz}, nil
+	case ReviewReject:
+		quiz, err := s.review(ctx, quizID, input.ReviewerID, func(quiz *models.Quiz) {
+			quiz.Status = models.QuizRejected
+			if reason != "" {
+				quiz.ModerationReasons = append(quiz.ModerationReasons, reason)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		logging.Info("Review: クイズの公開を拒否しました: id=%s, reviewer=%s", quizID, input.ReviewerID)
+		return &ReviewResult{Quiz: quiz}, nil
+	case ReviewBanAuthor:
+		return s.banAuthor(ctx, quizID, input.ReviewerID, reason)
+	}
+	return nil, fmt.Errorf("%w: 不明な操作です: %q", ErrInvalidReview, input.Action)
+}
+
+// banAuthor はクイズの投稿者を利用停止にし、投稿者のすべてのクイズの公開を拒否します
+func (s *ModerationServiceImpl) banAuthor(ctx context.Context, quizID, reviewerID, reason string) (*ReviewResult, error) {
+	quiz, err := s.storageClient.GetQuiz(ctx, quizID)
+	if err != nil {
+		return nil, fmt.Errorf("クイズの取得に失敗: %w", err)
+	}
+	if quiz.AuthorID == "" {
+		return nil, fmt.Errorf("%w: 作成者が記録されていないクイズです", ErrInvalidReview)
+	}
+
+	// 先に利用停止を保存し、公開の拒否の途中で失敗しても新しい投稿を受け付けないようにする
+	ban := &models.AuthorBan{
+		AuthorID:  quiz.AuthorID,
+		Reason:    reason,
+		BannedBy:  reviewerID,
+		CreatedAt: s.now(),
+	}
+	if err := s.bans.SaveAuthorBan(ctx, ban); err != nil {
+		return nil, err
+	}
+
+	banReason := "投稿者の利用停止"
+	if reason != "" {
+		banReason += ": " + reason
+	}
+	result := &ReviewResult{Ban: ban}
+	query := &models.QuizListQuery{AuthorID: quiz.AuthorID, IncludeHidden: true, Order: models.SortOldestFirst, Limit: MaxPageSize}
+	for {
+		page, err := s.storageClient.ListQuizzes(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("投稿者のクイズの取得に失敗: %w", err)
+		}
+		for _, summary := range page.Quizzes {
+			rejected, err := s.review(ctx, summary.ID, reviewerID, func(quiz *models.Quiz) {
+				quiz.Status = models.QuizRejected
+				quiz.ModerationReasons = append(quiz.ModerationReasons, banReason)
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.RejectedQuizzes++
+			if rejected.ID == quizID {
+				result.Quiz = rejected
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	logging.Warn("Review: 投稿者を利用停止にしました: author=%s, reviewer=%s, 公開を拒否したクイズ=%d", ban.AuthorID, reviewerID, result.RejectedQuizzes)
+	return result, nil
+}
+
+// review はクイズの審査の状態を変更し、審査した管理者と日時を記録します
+func (s *ModerationServiceImpl) review(ctx context.Context, quizID, reviewerID string, mutate func(quiz *models.Quiz)) (*models.Quiz, error) {
+	quiz, err := s.storageClient.UpdateQuiz(ctx, quizID, func(quiz *models.Quiz) error {
+		mutate(quiz)
+		now := s.now()
+		quiz.ReviewedBy = reviewerID
+		quiz.ReviewedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("クイズの審査に失敗: %w", err)
+	}
+	return quiz, nil
+}
+
+// ReportQuiz はプレイヤーからのクイズの報告を記録します
+// ログインした利用者からの未確認の報告が ReportFlagThreshold 件に達したクイズは、管理者が確認するまで公開を保留します
+// 未確認の報告が上限（匿名の報告は MaxAnonymousPendingReports 件、全体で MaxPendingReports 件）に達している場合は ErrReportLimitReached を返します
+// 作成者が自分のクイズを報告した場合は ErrInvalidReport を返します
+func (s *ModerationServiceImpl) ReportQuiz(ctx context.Context, quizID string, input *ReportInput) (*models.Quiz, error) {
+	if input == nil || input.ReporterID == "" {
+		return nil, fmt.Errorf("%w: 報告者が必要です", ErrInvalidReport)
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: 理由が必要です", ErrInvalidReport)
+	}
+	if utf8.RuneCountInString(reason) > MaxReportReasonLength {
+		return nil, fmt.Errorf("%w: 理由は%d文字以内で指定してください", ErrInvalidReport, MaxReportReasonLength)
+	}
+	report := models.QuizReport{ReporterID: input.ReporterID, Reason: reason}
+
+	quiz, err := s.storageClient.UpdateQuiz(ctx, quizID, func(quiz *models.Quiz) error {
+		// 公開されていないクイズはプレイヤーから見えないため、存在しないものとして扱う
+		if !quiz.Status.Public() {
+			return fmt.Errorf("%w: %s", storage.ErrQuizNotFound, quizID)
+		}
+		// 作成者が自分のクイズを報告して保留にできないようにする
+		if quiz.AuthorID != "" && quiz.AuthorID == input.ReporterID {
+			return fmt.Errorf("%w: 自分のクイズは報告できません", ErrInvalidReport)
+		}
+		pending := quiz.PendingReports()
+		anonymous := 0
+		for _, r := range pending {
+			if r.ReporterID == input.ReporterID {
+				return ErrAlreadyReported
+			}
+			if r.Anonymous() {
+				anonymous++
+			}
+		}
+		if len(pending) >= MaxPendingReports || (report.Anonymous() && anonymous >= MaxAnonymousPendingReports) {
+			return ErrReportLimitReached
+		}
+
+		// 確認済みの報告は保留の判定にも審査の一覧にも使わないため、クイズが大きくなり続けないよう取り除く
+		report.CreatedAt = s.now()
+		quiz.Reports = append(pending, report)
+		authenticated := 0
+		for _, r := range quiz.Reports {
+			if !r.Anonymous() {
+				authenticated++
+			}
+		}
+		if authenticated >= ReportFlagThreshold {
+			quiz.Status = models.QuizFlagged
+			quiz.ModerationReasons = append(quiz.ModerationReasons, fmt.Sprintf("ログインした利用者からの報告が%d件あります", authenticated))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logging.Info("ReportQuiz: クイズの報告を受け付けました: id=%s, 未確認の報告=%d, status=%s", quizID, len(quiz.PendingReports()), quiz.Status)
+	return quiz, nil
+}
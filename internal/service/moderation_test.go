@@ -0,0 +1,623 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/moderation"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// testClassifier はテスト用の語句で判定する Classifier を返します
+func testClassifier() moderation.Classifier {
+	return moderation.NewRuleClassifier([]moderation.Rule{
+		{Pattern: regexp.MustCompile(`禁止`), Decision: moderation.Reject, Reason: "禁止語"},
+		{Pattern: regexp.MustCompile(`要確認`), Decision: moderation.Flag, Reason: "要確認の語"},
+	})
+}
+
+// failingClassifier は常に審査に失敗する Classifier です
+type failingClassifier struct{}
+
+func (failingClassifier) Classify(ctx context.Context, content *moderation.Content) (*moderation.Result, error) {
+	return nil, errors.New("classifier unavailable")
+}
+
+func TestCreateQuiz_Moderation(t *testing.T) {
+	tests := []struct {
+		name        string
+		classifier  moderation.Classifier
+		input       *CreateQuizInput
+		wantStatus  models.QuizStatus
+		wantReasons []string
+		wantErr     error
+	}{
+		{
+			name:       "審査なし",
+			input:      &CreateQuizInput{AuthorInterpretation: "禁止"},
+			wantStatus: models.QuizApproved,
+		},
+		{
+			name:       "問題のない投稿は公開",
+			classifier: testClassifier(),
+			input:      &CreateQuizInput{AuthorInterpretation: "海辺の犬"},
+			wantStatus: models.QuizApproved,
+		},
+		{
+			name:        "タイトルが要確認の場合は保留",
+			classifier:  testClassifier(),
+			input:       &CreateQuizInput{AuthorInterpretation: "海辺の犬", Title: "要確認"},
+			wantStatus:  models.QuizFlagged,
+			wantReasons: []string{"要確認の語"},
+		},
+		{
+			name:       "タグが禁止語の場合は拒否",
+			classifier: testClassifier(),
+			input:      &CreateQuizInput{AuthorInterpretation: "海辺の犬", Tags: []string{"禁止"}},
+			wantErr:    ErrContentRejected,
+		},
+		{
+			name:        "審査に失敗した場合は保留",
+			classifier:  failingClassifier{},
+			input:       &CreateQuizInput{AuthorInterpretation: "海辺の犬"},
+			wantStatus:  models.QuizFlagged,
+			wantReasons: []string{"審査に失敗しました"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAI := &MockAIClient{}
+			mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("AIの解釈", nil)
+
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("SaveImage", mock.Anything, mock.Anything, mock.Anything).Return("images/test.png", nil)
+			mockStorage.On("SaveImageVariant", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", nil)
+			mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(nil)
+
+			var opts []Option
+			if tt.classifier != nil {
+				opts = append(opts, WithModerator(tt.classifier))
+			}
+			tt.input.ImageData = testPNG(t)
+			quiz, err := NewQuizService(mockAI, mockStorage, opts...).CreateQuiz(context.Background(), tt.input)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+				mockAI.AssertNotCalled(t, "GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStatus, quiz.Status)
+			assert.Equal(t, tt.wantReasons, quiz.ModerationReasons)
+		})
+	}
+}
+
+func TestUpdateQuiz_Moderation(t *testing.T) {
+	flagged := "要確認"
+	rejected := "禁止"
+	clean := "海辺の犬"
+
+	tests := []struct {
+		name       string
+		stored     models.QuizStatus
+		input      *UpdateQuizInput
+		wantStatus models.QuizStatus
+		wantErr    error
+	}{
+		{
+			name:       "問題のない編集では状態を変えない",
+			stored:     models.QuizApproved,
+			input:      &UpdateQuizInput{AuthorInterpretation: &clean},
+			wantStatus: models.QuizApproved,
+		},
+		{
+			name:       "公開中のクイズを要確認の内容に編集すると保留",
+			stored:     models.QuizApproved,
+			input:      &UpdateQuizInput{Title: &flagged},
+			wantStatus: models.QuizFlagged,
+		},
+		{
+			name:       "拒否済みのクイズは編集しても公開されない",
+			stored:     models.QuizRejected,
+			input:      &UpdateQuizInput{AuthorInterpretation: &clean},
+			wantStatus: models.QuizRejected,
+		},
+		{
+			name:    "禁止語への編集は拒否",
+			stored:  models.QuizApproved,
+			input:   &UpdateQuizInput{AuthorInterpretation: &rejected},
+			wantErr: ErrContentRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &models.Quiz{
+				ID:                   "quiz-1",
+				AuthorInterpretation: "元の解釈",
+				AIInterpretations:    []string{"おとり"},
+				Status:               tt.stored,
+			}
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("GetQuiz", mock.Anything, "quiz-1").Return(stored, nil)
+			mockStorage.On("UpdateQuiz", mock.Anything, "quiz-1", mock.Anything).Return(stored, nil)
+
+			service := NewQuizService(&MockAIClient{}, mockStorage, WithModerator(testClassifier()))
+			quiz, err := service.UpdateQuiz(context.Background(), "quiz-1", tt.input)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockStorage.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStatus, quiz.Status)
+		})
+	}
+}
+
+func TestCreateQuiz_ModeratesDecoys(t *testing.T) {
+	tests := []struct {
+		name        string
+		decoy       string
+		title       string
+		wantStatus  models.QuizStatus
+		wantReasons []string
+		wantErr     error
+	}{
+		{
+			name:       "問題のないおとりは公開",
+			decoy:      "夕暮れの港",
+			wantStatus: models.QuizApproved,
+		},
+		{
+			name:        "おとりが要確認の場合は保留",
+			decoy:       "要確認の夕暮れ",
+			wantStatus:  models.QuizFlagged,
+			wantReasons: []string{"要確認の語"},
+		},
+		{
+			name:        "投稿者の内容とおとりの理由は重複させない",
+			decoy:       "要確認の夕暮れ",
+			title:       "要確認",
+			wantStatus:  models.QuizFlagged,
+			wantReasons: []string{"要確認の語"},
+		},
+		{
+			name:    "おとりが禁止語の場合は拒否",
+			decoy:   "禁止された夕暮れ",
+			wantErr: ErrContentRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAI := &MockAIClient{}
+			mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.decoy, nil)
+
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("SaveImage", mock.Anything, mock.Anything, mock.Anything).Return("images/test.png", nil)
+			mockStorage.On("SaveImageVariant", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", nil)
+			mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(nil)
+
+			service := NewQuizService(mockAI, mockStorage, WithModerator(testClassifier()))
+			quiz, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+				ImageData:            testPNG(t),
+				AuthorInterpretation: "海辺の犬",
+				Title:                tt.title,
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				// 拒否したおとりは画像とともに保存しない
+				mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+				mockStorage.AssertNotCalled(t, "SaveQuiz", mock.Anything, mock.Anything)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStatus, quiz.Status)
+			assert.Equal(t, tt.wantReasons, quiz.ModerationReasons)
+		})
+	}
+}
+
+func TestUpdateQuiz_ModeratesRegeneratedDecoys(t *testing.T) {
+	tests := []struct {
+		name        string
+		decoy       string
+		wantStatus  models.QuizStatus
+		wantReasons []string
+		wantErr     error
+	}{
+		{
+			name:       "問題のないおとりでは状態を変えない",
+			decoy:      "夕暮れの港",
+			wantStatus: models.QuizApproved,
+		},
+		{
+			name:        "生成し直したおとりが要確認の場合は保留",
+			decoy:       "要確認の夕暮れ",
+			wantStatus:  models.QuizFlagged,
+			wantReasons: []string{"要確認の語"},
+		},
+		{
+			name:    "生成し直したおとりが禁止語の場合は拒否",
+			decoy:   "禁止された夕暮れ",
+			wantErr: ErrContentRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &models.Quiz{
+				ID:                   "quiz-1",
+				ImagePath:            "images/test.png",
+				ImageMimeType:        "image/png",
+				AuthorInterpretation: "元の解釈",
+				AIInterpretations:    []string{"おとり1", "おとり2", "おとり3"},
+				Status:               models.QuizApproved,
+			}
+			mockAI := &MockAIClient{}
+			mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.decoy, nil)
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("GetQuiz", mock.Anything, "quiz-1").Return(stored, nil)
+			mockStorage.On("GetImage", mock.Anything, "images/test.png").Return(testPNG(t), nil)
+			mockStorage.On("UpdateQuiz", mock.Anything, "quiz-1", mock.Anything).Return(stored, nil)
+
+			service := NewQuizService(mockAI, mockStorage, WithModerator(testClassifier()))
+			quiz, err := service.UpdateQuiz(context.Background(), "quiz-1", &UpdateQuizInput{RegenerateDecoys: true})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockStorage.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStatus, quiz.Status)
+			assert.Equal(t, tt.wantReasons, quiz.ModerationReasons)
+		})
+	}
+}
+
+// fakeBanStore は利用停止の記録をメモリに保持する BanStore です
+type fakeBanStore struct {
+	bans map[string]*models.AuthorBan
+}
+
+func newFakeBanStore(authorIDs ...string) *fakeBanStore {
+	store := &fakeBanStore{bans: make(map[string]*models.AuthorBan)}
+	for _, id := range authorIDs {
+		store.bans[id] = &models.AuthorBan{AuthorID: id}
+	}
+	return store
+}
+
+func (s *fakeBanStore) SaveAuthorBan(ctx context.Context, ban *models.AuthorBan) error {
+	s.bans[ban.AuthorID] = ban
+	return nil
+}
+
+func (s *fakeBanStore) GetAuthorBan(ctx context.Context, authorID string) (*models.AuthorBan, error) {
+	ban, ok := s.bans[authorID]
+	if !ok {
+		return nil, storage.ErrBanNotFound
+	}
+	return ban, nil
+}
+
+func TestCreateQuiz_BannedAuthor(t *testing.T) {
+	mockAI := &MockAIClient{}
+	mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("AIの解釈", nil)
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("SaveImage", mock.Anything, mock.Anything, mock.Anything).Return("images/test.png", nil)
+	mockStorage.On("SaveImageVariant", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", nil)
+	mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(nil)
+
+	service := NewQuizService(mockAI, mockStorage, WithBanStore(newFakeBanStore("banned")))
+
+	_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{ImageData: testPNG(t), AuthorInterpretation: "解釈", AuthorID: "banned"})
+	assert.ErrorIs(t, err, ErrAuthorBanned)
+	mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = service.CreateQuiz(context.Background(), &CreateQuizInput{ImageData: testPNG(t), AuthorInterpretation: "解釈", AuthorID: "author-1"})
+	assert.NoError(t, err)
+}
+
+func TestListPending(t *testing.T) {
+	flagged := &models.Quiz{ID: "quiz-1", Status: models.QuizFlagged, ModerationReasons: []string{"要確認の語"}}
+	reported := &models.Quiz{ID: "quiz-3", Reports: []models.QuizReport{{ReporterID: "player-1", Reason: "不適切"}}}
+
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("ListQuizzes", mock.Anything, &models.QuizListQuery{
+		IncludeHidden: true,
+		NeedsReview:   true,
+		Order:         models.SortOldestFirst,
+		Limit:         DefaultPageSize,
+		Cursor:        "cursor-1",
+	}).Return(&models.QuizPage{
+		Quizzes: []*models.QuizSummary{
+			{QuizIndexEntry: models.QuizIndexEntry{ID: "quiz-1"}},
+			{QuizIndexEntry: models.QuizIndexEntry{ID: "quiz-2"}},
+			{QuizIndexEntry: models.QuizIndexEntry{ID: "quiz-3"}},
+		},
+		NextCursor: "cursor-2",
+	}, nil)
+	mockStorage.On("GetQuiz", mock.Anything, "quiz-1").Return(flagged, nil)
+	mockStorage.On("GetQuiz", mock.Anything, "quiz-2").Return(nil, storage.ErrQuizNotFound)
+	mockStorage.On("GetQuiz", mock.Anything, "quiz-3").Return(reported, nil)
+
+	queue, err := NewModerationService(mockStorage, newFakeBanStore()).ListPending(context.Background(), 0, "cursor-1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// 一覧の取得後に削除されたクイズは読み飛ばす
+	assert.Equal(t, []*models.Quiz{flagged, reported}, queue.Quizzes)
+	assert.Equal(t, "cursor-2", queue.NextCursor)
+}
+
+func TestReview(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       *ReviewInput
+		wantStatus  models.QuizStatus
+		wantReasons []string
+		wantErr     error
+	}{
+		{
+			name:       "公開",
+			input:      &ReviewInput{Action: ReviewApprove, ReviewerID: "admin"},
+			wantStatus: models.QuizApproved,
+		},
+		{
+			name:        "拒否",
+			input:       &ReviewInput{Action: ReviewReject, ReviewerID: "admin", Reason: " 規約違反 "},
+			wantStatus:  models.QuizRejected,
+			wantReasons: []string{"要確認の語", "規約違反"},
+		},
+		{
+			name:    "不明な操作",
+			input:   &ReviewInput{Action: "delete", ReviewerID: "admin"},
+			wantErr: ErrInvalidReview,
+		},
+		{
+			name:    "審査した管理者なし",
+			input:   &ReviewInput{Action: ReviewApprove},
+			wantErr: ErrInvalidReview,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &models.Quiz{
+				ID:                "quiz-1",
+				Status:            models.QuizFlagged,
+				ModerationReasons: []string{"要確認の語"},
+				Reports:           []models.QuizReport{{ReporterID: "player-1", Reason: "不適切", CreatedAt: time.Now().Add(-time.Minute)}},
+			}
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("UpdateQuiz", mock.Anything, "quiz-1", mock.Anything).Return(stored, nil)
+
+			result, err := NewModerationService(mockStorage, newFakeBanStore()).Review(context.Background(), "quiz-1", tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockStorage.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStatus, result.Quiz.Status)
+			assert.Equal(t, tt.wantReasons, result.Quiz.ModerationReasons)
+			assert.Equal(t, "admin", result.Quiz.ReviewedBy)
+			// 審査より前の報告は確認済みになる
+			assert.Empty(t, result.Quiz.PendingReports())
+			assert.False(t, result.Quiz.NeedsReview())
+		})
+	}
+}
+
+func TestReview_BanAuthor(t *testing.T) {
+	target := &models.Quiz{ID: "quiz-1", AuthorID: "author-1", Status: models.QuizFlagged}
+	other := &models.Quiz{ID: "quiz-2", AuthorID: "author-1", Status: models.QuizApproved}
+
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("GetQuiz", mock.Anything, "quiz-1").Return(target, nil)
+	mockStorage.On("ListQuizzes", mock.Anything, mock.MatchedBy(func(q *models.QuizListQuery) bool {
+		return q.AuthorID == "author-1" && q.IncludeHidden
+	})).Return(&models.QuizPage{Quizzes: []*models.QuizSummary{
+		{QuizIndexEntry: models.QuizIndexEntry{ID: "quiz-1"}},
+		{QuizIndexEntry: models.QuizIndexEntry{ID: "quiz-2"}},
+	}}, nil)
+	mockStorage.On("UpdateQuiz", mock.Anything, "quiz-1", mock.Anything).Return(target, nil)
+	mockStorage.On("UpdateQuiz", mock.Anything, "quiz-2", mock.Anything).Return(other, nil)
+
+	bans := newFakeBanStore()
+	result, err := NewModerationService(mockStorage, bans).Review(context.Background(), "quiz-1", &ReviewInput{
+		Action:     ReviewBanAuthor,
+		ReviewerID: "admin",
+		Reason:     "スパム",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, result.RejectedQuizzes)
+	assert.Equal(t, models.QuizRejected, result.Quiz.Status)
+	assert.Equal(t, []string{"投稿者の利用停止: スパム"}, result.Quiz.ModerationReasons)
+	if assert.Contains(t, bans.bans, "author-1") {
+		assert.Equal(t, "admin", bans.bans["author-1"].BannedBy)
+		assert.Equal(t, "スパム", bans.bans["author-1"].Reason)
+	}
+	mockStorage.AssertCalled(t, "UpdateQuiz", mock.Anything, "quiz-2", mock.Anything)
+
+	// 作成者が記録されていないクイズでは利用停止にできない
+	mockStorage.On("GetQuiz", mock.Anything, "legacy").Return(&models.Quiz{ID: "legacy"}, nil)
+	_, err = NewModerationService(mockStorage, bans).Review(context.Background(), "legacy", &ReviewInput{Action: ReviewBanAuthor, ReviewerID: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidReview)
+}
+
+func TestReportQuiz(t *testing.T) {
+	now := time.Now()
+	reviewedAt := now.Add(-time.Hour)
+	reportsFrom := func(ids ...string) []models.QuizReport {
+		var reports []models.QuizReport
+		for _, id := range ids {
+			reports = append(reports, models.QuizReport{ReporterID: id, Reason: "不適切", CreatedAt: now.Add(-time.Minute)})
+		}
+		return reports
+	}
+
+	// anonymousReports は n 人の匿名のプレイヤーからの報告を返します
+	anonymousReports := func(n int) []models.QuizReport {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("%sanon-%d", models.AnonymousReporterPrefix, i)
+		}
+		return reportsFrom(ids...)
+	}
+	userReports := func(n int) []models.QuizReport {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("user-%d", i)
+		}
+		return reportsFrom(ids...)
+	}
+
+	tests := []struct {
+		name        string
+		stored      *models.Quiz
+		input       *ReportInput
+		wantStatus  models.QuizStatus
+		wantPending int
+		wantStored  int
+		wantErr     error
+	}{
+		{
+			name:        "報告を記録",
+			stored:      &models.Quiz{ID: "quiz-1"},
+			input:       &ReportInput{ReporterID: "player-1", Reason: "不適切な画像"},
+			wantPending: 1,
+		},
+		{
+			name:        "未確認の報告がしきい値に達すると保留",
+			stored:      &models.Quiz{ID: "quiz-1", Reports: reportsFrom("player-2", "player-3")},
+			input:       &ReportInput{ReporterID: "player-1", Reason: "不適切な画像"},
+			wantStatus:  models.QuizFlagged,
+			wantPending: ReportFlagThreshold,
+		},
+		{
+			name:        "確認済みの報告は数えない",
+			stored:      &models.Quiz{ID: "quiz-1", Status: models.QuizApproved, Reports: reportsFrom("player-1", "player-2", "player-3"), ReviewedAt: &now},
+			input:       &ReportInput{ReporterID: "player-1", Reason: "不適切な画像"},
+			wantStatus:  models.QuizApproved,
+			wantPending: 1,
+		},
+		{
+			name:        "匿名の報告はしきい値に数えない",
+			stored:      &models.Quiz{ID: "quiz-1", Reports: anonymousReports(ReportFlagThreshold - 1)},
+			input:       &ReportInput{ReporterID: models.AnonymousReporterPrefix + "player-1", Reason: "不適切な画像"},
+			wantPending: ReportFlagThreshold,
+		},
+		{
+			name:        "ログインした利用者の報告だけでしきい値に達すると保留",
+			stored:      &models.Quiz{ID: "quiz-1", Reports: append(anonymousReports(5), userReports(ReportFlagThreshold-1)...)},
+			input:       &ReportInput{ReporterID: "user-x", Reason: "不適切な画像"},
+			wantStatus:  models.QuizFlagged,
+			wantPending: 5 + ReportFlagThreshold,
+		},
+		{
+			name:        "確認済みの報告は取り除く",
+			stored:      &models.Quiz{ID: "quiz-1", Reports: reportsFrom("player-1", "player-2"), ReviewedAt: &now},
+			input:       &ReportInput{ReporterID: "player-3", Reason: "不適切な画像"},
+			wantPending: 1,
+			wantStored:  1,
+		},
+		{
+			name:    "匿名の報告が上限に達している",
+			stored:  &models.Quiz{ID: "quiz-1", Reports: anonymousReports(MaxAnonymousPendingReports)},
+			input:   &ReportInput{ReporterID: models.AnonymousReporterPrefix + "player-1", Reason: "不適切な画像"},
+			wantErr: ErrReportLimitReached,
+		},
+		{
+			name:        "匿名の報告が上限に達していてもログインした利用者は報告できる",
+			stored:      &models.Quiz{ID: "quiz-1", Reports: anonymousReports(MaxAnonymousPendingReports)},
+			input:       &ReportInput{ReporterID: "user-x", Reason: "不適切な画像"},
+			wantPending: MaxAnonymousPendingReports + 1,
+		},
+		{
+			name:    "未確認の報告が上限に達している",
+			stored:  &models.Quiz{ID: "quiz-1", Status: models.QuizApproved, Reports: userReports(MaxPendingReports), ReviewedAt: &reviewedAt},
+			input:   &ReportInput{ReporterID: "user-x", Reason: "不適切な画像"},
+			wantErr: ErrReportLimitReached,
+		},
+		{
+			name:    "同じ利用者からの未確認の報告がある",
+			stored:  &models.Quiz{ID: "quiz-1", Reports: reportsFrom("player-1"), ReviewedAt: &reviewedAt},
+			input:   &ReportInput{ReporterID: "player-1", Reason: "不適切な画像"},
+			wantErr: ErrAlreadyReported,
+		},
+		{
+			name:    "作成者による報告",
+			stored:  &models.Quiz{ID: "quiz-1", AuthorID: "user-1"},
+			input:   &ReportInput{ReporterID: "user-1", Reason: "不適切な画像"},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name:    "公開されていないクイズ",
+			stored:  &models.Quiz{ID: "quiz-1", Status: models.QuizFlagged},
+			input:   &ReportInput{ReporterID: "player-1", Reason: "不適切な画像"},
+			wantErr: storage.ErrQuizNotFound,
+		},
+		{
+			name:    "理由なし",
+			stored:  &models.Quiz{ID: "quiz-1"},
+			input:   &ReportInput{ReporterID: "player-1", Reason: "  "},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name:    "理由が長すぎる",
+			stored:  &models.Quiz{ID: "quiz-1"},
+			input:   &ReportInput{ReporterID: "player-1", Reason: strings.Repeat("あ", MaxReportReasonLength+1)},
+			wantErr: ErrInvalidReport,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("UpdateQuiz", mock.Anything, "quiz-1", mock.Anything).Return(tt.stored, nil)
+
+			quiz, err := NewModerationService(mockStorage, newFakeBanStore()).ReportQuiz(context.Background(), "quiz-1", tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStatus, quiz.Status)
+			assert.Len(t, quiz.PendingReports(), tt.wantPending)
+			if tt.wantStored > 0 {
+				assert.Len(t, quiz.Reports, tt.wantStored)
+			}
+		})
+	}
+}
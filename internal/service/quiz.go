@@ -2,24 +2,109 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"io"
+	"path"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/ai"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/imaging"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/moderation"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
 )
 
+const (
+	// MinDecoyCount は1つのクイズに含めるおとりの解釈の最小数です
+	MinDecoyCount = 1
+	// MaxDecoyCount は1つのクイズに含めるおとりの解釈の最大数です
+	MaxDecoyCount = 5
+	// DefaultDecoyCount はおとりの数が指定されなかった場合の数です
+	DefaultDecoyCount = 1
+
+	// DefaultPageSize はクイズ一覧の取得件数が指定されなかった場合の件数です
+	DefaultPageSize = 20
+	// MaxPageSize はクイズ一覧で1度に取得できる最大件数です
+	MaxPageSize = 100
+
+	// MaxTags は1つのクイズに付けられるタグの最大数です
+	MaxTags = 10
+	// MaxTagLength はタグ1つの最大文字数です
+	MaxTagLength = 30
+
+	// MaxTitleLength はタイトルの最大文字数です
+	MaxTitleLength = 100
+	// MaxInterpretationLength は投稿者の解釈の最大文字数です
+	MaxInterpretationLength = 1000
+
+	// DefaultGenerateTimeout はクイズ1件分のおとりの解釈の生成にかける既定の時間の上限です
+	DefaultGenerateTimeout = 2 * time.Minute
+
+	// ImageProxyPrefix は署名付きURLを発行できない場合に、サーバーが画像を配信するURLパスの接頭辞です
+	// クイズ <ID> の画像 images/<名前> は ImageProxyPrefix + <ID> + "/" + <名前> で配信します
+	ImageProxyPrefix = "/images/"
+)
+
+var (
+	// ErrInvalidTag はタグの数または長さが制限を超えていることを表します
+	ErrInvalidTag = errors.New("タグが不正です")
+	// ErrInvalidTitle はタイトルの長さが制限を超えていることを表します
+	ErrInvalidTitle = errors.New("タイトルが不正です")
+	// ErrInvalidInterpretation は投稿者の解釈が空であるか、長さが制限を超えていることを表します
+	ErrInvalidInterpretation = errors.New("投稿者の解釈が不正です")
+	// ErrInvalidImage は画像が対応していない形式であるか、デコードできないことを表します
+	ErrInvalidImage = errors.New("画像を読み込めません")
+)
+
+// CreateQuizInput はクイズ作成の入力を表します
+type CreateQuizInput struct {
+	ImageData []byte
+	// ImageMimeType は検証時に判定した画像の MIME タイプです（空の場合は画像データから判定します）
+	ImageMimeType        string
+	AuthorInterpretation string
+	// Title は一覧に表示するタイトルです（省略可）
+	Title string
+	// DecoyCount はAIに生成させるおとりの解釈の数です（0 の場合は DefaultDecoyCount）
+	DecoyCount int
+	// AuthorID はクイズを作成した利用者のIDです
+	AuthorID string
+	// AuthorName は一覧に表示する投稿者の表示名です
+	AuthorName string
+	// Tags はクイズに付けるタグです
+	Tags []string
+}
+
+// UpdateQuizInput はクイズ編集の入力を表します
+type UpdateQuizInput struct {
+	// AuthorInterpretation は新しい投稿者の解釈です（nil の場合は変更しない）
+	AuthorInterpretation *string
+	// Title は新しいタイトルです（nil の場合は変更しない）
+	Title *string
+	// RegenerateDecoys が true の場合、おとりの解釈を同じ数だけ生成し直します
+	RegenerateDecoys bool
+}
+
 // QuizService はクイズ関連の操作を提供するインターフェース
 type QuizService interface {
-	CreateQuiz(ctx context.Context, imageData []byte, authorInterpretation string) (*models.Quiz, error)
+	CreateQuiz(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error)
 	GetQuiz(ctx context.Context, quizID string) (*models.Quiz, error)
-	GetRandomizedInterpretations(quiz *models.Quiz) []string
-	VerifyAnswer(quiz *models.Quiz, selectedInterpretation string) bool
-	GetSignedImageURL(ctx context.Context, imagePath string) (string, error)
-	GetQuizList(ctx context.Context) ([]*models.Quiz, error)
+	UpdateQuiz(ctx context.Context, quizID string, input *UpdateQuizInput) (*models.Quiz, error)
+	DeleteQuiz(ctx context.Context, quizID string) error
+	GetOptions(quiz *models.Quiz, sessionID string) []models.QuizOption
+	VerifyAnswer(quiz *models.Quiz, sessionID, optionID string) (bool, error)
+	CorrectOptionID(quiz *models.Quiz, sessionID string) string
+	SubmitAnswer(ctx context.Context, quiz *models.Quiz, input *SubmitAnswerInput) (*models.Answer, error)
+	GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error)
+	GetSignedImageURL(ctx context.Context, image models.QuizImage, variant models.ImageVariant) (string, error)
+	GetImageQuiz(ctx context.Context, quizID, imagePath string) (*models.Quiz, error)
+	StatImage(ctx context.Context, imagePath string) (*models.ImageInfo, error)
+	OpenImage(ctx context.Context, imagePath string, offset, length int64) (io.ReadCloser, error)
+	GetQuizList(ctx context.Context, query *models.QuizListQuery) (*models.QuizPage, error)
 	DeleteAllQuizzes(ctx context.Context) error
 }
 
@@ -27,55 +112,234 @@ type QuizService interface {
 type QuizServiceImpl struct {
 	aiClient      ai.AIClient
 	storageClient storage.StorageClient
+	answerSecret  []byte
+	moderator     moderation.Classifier
+	bans          storage.BanStore
+	// generateTimeout はおとりの解釈の生成全体（やり直しと生成し直しを含む）にかける時間の上限です
+	generateTimeout time.Duration
+	// imageBaseURL は画像プロキシのURLに付けるサーバーの公開URLです（空の場合はルート相対URL）
+	imageBaseURL string
 }
 
 // NewQuizService は新しいQuizServiceインスタンスを作成します
-func NewQuizService(aiClient ai.AIClient, storageClient storage.StorageClient) QuizService {
-	return &QuizServiceImpl{
-		aiClient:      aiClient,
-		storageClient: storageClient,
+func NewQuizService(aiClient ai.AIClient, storageClient storage.StorageClient, opts ...Option) QuizService {
+	s := &QuizServiceImpl{
+		aiClient:        aiClient,
+		storageClient:   storageClient,
+		generateTimeout: DefaultGenerateTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if len(s.answerSecret) == 0 {
+		// 秘密鍵が未設定の場合、選択肢IDは再起動すると変わります
+		s.answerSecret = randomBytes(32)
+	}
+	return s
+}
+
+// WithGenerateTimeout はおとりの解釈の生成全体にかける時間の上限を設定します（0 以下の場合は上限を設けません）
+// AIクライアントのやり直しと生成し直しが重なっても、リクエストがこの時間を超えて待たされないようにします
+func WithGenerateTimeout(d time.Duration) Option {
+	return func(s *QuizServiceImpl) {
+		s.generateTimeout = d
+	}
+}
+
+// WithImageBaseURL は画像プロキシのURLに付けるサーバーの公開URL（例: https://api.example.com）を設定します
+// 未設定の場合は /images/... のルート相対URLを返します
+func WithImageBaseURL(baseURL string) Option {
+	return func(s *QuizServiceImpl) {
+		s.imageBaseURL = strings.TrimSuffix(baseURL, "/")
 	}
 }
 
 // CreateQuiz は新しいクイズを作成します
-func (s *QuizServiceImpl) CreateQuiz(ctx context.Context, imageData []byte, authorInterpretation string) (*models.Quiz, error) {
+func (s *QuizServiceImpl) CreateQuiz(ctx context.Context, input *CreateQuizInput) (*models.Quiz, error) {
 	// 入力値の検証
-	if len(imageData) == 0 {
+	if input == nil || len(input.ImageData) == 0 {
 		return nil, fmt.Errorf("画像データが必要です")
 	}
-	if authorInterpretation == "" {
-		return nil, fmt.Errorf("投稿者の解釈が必要です")
+	if err := validateInterpretation(input.AuthorInterpretation); err != nil {
+		return nil, err
+	}
+	decoyCount := input.DecoyCount
+	if decoyCount == 0 {
+		decoyCount = DefaultDecoyCount
+	}
+	if decoyCount < MinDecoyCount || decoyCount > MaxDecoyCount {
+		return nil, fmt.Errorf("おとりの数は%dから%dの範囲で指定してください: %d", MinDecoyCount, MaxDecoyCount, decoyCount)
+	}
+	title, err := normalizeTitle(input.Title)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBanned(ctx, input.AuthorID); err != nil {
+		return nil, err
 	}
 
-	// 画像の保存
-	imagePath, err := s.storageClient.SaveImage(ctx, imageData)
+	// WebP などの形式は、保存やAIに渡す前に JPEG または PNG に変換する
+	mimeType := input.ImageMimeType
+	if mimeType == "" {
+		mimeType = imaging.DetectType(input.ImageData)
+	}
+	// 変換や派生画像の生成で画像全体をデコードする前に、ヘッダーの大きさを確かめる
+	if err := imaging.CheckDimensions(input.ImageData, mimeType); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	imageData, mimeType, err := imaging.Normalize(input.ImageData, mimeType)
 	if err != nil {
-		return nil, fmt.Errorf("画像の保存に失敗: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	// 画像は公開されるため、撮影位置などのメタデータを取り除き、EXIF の向きを画素に反映しておく
+	imageData, err = imaging.StripMetadata(imageData, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
 	}
 
-	// AIによる代替解釈の生成
-	aiInterpretation, err := s.aiClient.GenerateInterpretation(ctx, imageData, authorInterpretation)
+	// 内容の審査（拒否する投稿は画像の保存やAIの呼び出しの前に止める）
+	status, reasons, err := s.moderate(ctx, &moderation.Content{
+		ImageData:     imageData,
+		ImageMimeType: mimeType,
+		Texts:         append([]string{input.AuthorInterpretation, title}, tags...),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 一覧や画面の大きさに合わせた派生画像の生成
+	variants, err := imaging.GenerateVariants(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	// AIによる代替解釈の生成（AIサービスの障害で失敗した場合に画像が残らないよう、保存より前に行う）
+	decoys, err := s.generateDecoys(ctx, imageData, mimeType, input.AuthorInterpretation, decoyCount)
 	if err != nil {
 		return nil, fmt.Errorf("AIによる解釈の生成に失敗: %w", err)
 	}
+	status, reasons, err = s.moderateDecoys(ctx, decoys, status, reasons)
+	if err != nil {
+		return nil, err
+	}
+
+	// 画像の保存
+	imagePath, err := s.storageClient.SaveImage(ctx, imageData, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("画像の保存に失敗: %w", err)
+	}
+	imageVariants, err := s.saveImageVariants(ctx, imagePath, variants)
+	if err != nil {
+		// 途中まで保存した派生画像も含めて取り除く
+		s.discardImage(ctx, models.QuizImage{Path: imagePath, Variants: models.ImageVariants})
+		return nil, err
+	}
 
 	// クイズの作成
 	quiz := &models.Quiz{
 		ID:                   generateID(),
 		ImagePath:            imagePath,
-		AuthorInterpretation: authorInterpretation,
-		AIInterpretation:     aiInterpretation,
+		ImageMimeType:        mimeType,
+		ImageVariants:        imageVariants,
+		Title:                title,
+		AuthorID:             input.AuthorID,
+		AuthorName:           input.AuthorName,
+		AuthorInterpretation: input.AuthorInterpretation,
+		AIInterpretation:     decoys[0],
+		AIInterpretations:    decoys,
+		Tags:                 tags,
+		Status:               status,
+		ModerationReasons:    reasons,
 		CreatedAt:            time.Now(),
 	}
 
 	// クイズの保存
 	if err := s.storageClient.SaveQuiz(ctx, quiz); err != nil {
+		s.discardImage(ctx, quiz.Image())
 		return nil, fmt.Errorf("クイズの保存に失敗: %w", err)
 	}
 
 	return quiz, nil
 }
 
+// discardImage はクイズの作成に失敗した場合に、保存済みの画像と派生画像を削除します
+// 削除に失敗しても作成の失敗として返すエラーは変えず、ログに残します
+func (s *QuizServiceImpl) discardImage(ctx context.Context, image models.QuizImage) {
+	// 呼び出し元がキャンセルされていても、取り残された画像を削除する
+	if err := s.storageClient.DeleteImage(context.WithoutCancel(ctx), image); err != nil {
+		logging.Warn("保存済みの画像の削除に失敗: path=%s, err=%v", image.Path, err)
+	}
+}
+
+// saveImageVariants は派生画像を元の画像と同じ場所に保存し、保存した種類を小さい順に返します
+func (s *QuizServiceImpl) saveImageVariants(ctx context.Context, imagePath string, variants map[models.ImageVariant][]byte) ([]models.ImageVariant, error) {
+	var saved []models.ImageVariant
+	for _, variant := range models.ImageVariants {
+		data, ok := variants[variant]
+		if !ok {
+			continue
+		}
+		if _, err := s.storageClient.SaveImageVariant(ctx, imagePath, variant, data); err != nil {
+			return nil, fmt.Errorf("派生画像の保存に失敗: %w", err)
+		}
+		saved = append(saved, variant)
+	}
+	return saved, nil
+}
+
+// generateDecoys はペルソナを変えながら count 個のおとりの解釈を並行して生成します
+// 生成全体に generateTimeout の上限を設け、1つでも失敗した場合は残りの生成を打ち切ります
+func (s *QuizServiceImpl) generateDecoys(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, count int) ([]string, error) {
+	genCtx := ctx
+	if s.generateTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		genCtx, cancelTimeout = context.WithTimeout(ctx, s.generateTimeout)
+		defer cancelTimeout()
+	}
+	genCtx, cancel := context.WithCancel(genCtx)
+	defer cancel()
+
+	decoys := make([]string, count)
+	errs := make([]error, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			decoys[i], errs[i] = s.aiClient.GenerateInterpretation(genCtx, imageData, mimeType, authorInterpretation, ai.PersonaAt(i))
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 呼び出し元のキャンセルではなく上限に達した場合は、AIサービスを利用できなかったものとして扱う
+	if ctx.Err() == nil && errors.Is(genCtx.Err(), context.DeadlineExceeded) {
+		logging.Error("おとりの解釈の生成が時間の上限に達しました: 上限=%s", s.generateTimeout)
+		return nil, fmt.Errorf("%w: おとりの解釈の生成が%s以内に終わりませんでした", ai.ErrUnavailable, s.generateTimeout)
+	}
+	// 打ち切られた生成のキャンセルのエラーより、最初に失敗した生成のエラーを返す
+	var firstErr error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if firstErr == nil || (errors.Is(firstErr, context.Canceled) && !errors.Is(err, context.Canceled)) {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return decoys, nil
+}
+
 // GetQuiz は指定されたIDのクイズを取得します
 func (s *QuizServiceImpl) GetQuiz(ctx context.Context, quizID string) (*models.Quiz, error) {
 	if quizID == "" {
@@ -90,19 +354,108 @@ func (s *QuizServiceImpl) GetQuiz(ctx context.Context, quizID string) (*models.Q
 	return quiz, nil
 }
 
-// GetRandomizedInterpretations は解釈をランダムな順序で返します
-func (s *QuizServiceImpl) GetRandomizedInterpretations(quiz *models.Quiz) []string {
-	interpretations := []string{
-		quiz.AuthorInterpretation,
-		quiz.AIInterpretation,
+// UpdateQuiz は投稿者の解釈を変更し、必要に応じておとりの解釈を生成し直します
+func (s *QuizServiceImpl) UpdateQuiz(ctx context.Context, quizID string, input *UpdateQuizInput) (*models.Quiz, error) {
+	// 入力値の検証
+	if input == nil || (input.AuthorInterpretation == nil && input.Title == nil && !input.RegenerateDecoys) {
+		return nil, fmt.Errorf("変更内容が必要です")
+	}
+	if input.AuthorInterpretation != nil {
+		if err := validateInterpretation(*input.AuthorInterpretation); err != nil {
+			return nil, err
+		}
+	}
+	var title *string
+	if input.Title != nil {
+		t, err := normalizeTitle(*input.Title)
+		if err != nil {
+			return nil, err
+		}
+		title = &t
+	}
+
+	current, err := s.GetQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	authorInterpretation := current.AuthorInterpretation
+	if input.AuthorInterpretation != nil {
+		authorInterpretation = *input.AuthorInterpretation
+	}
+
+	// 文章を変更する場合やおとりの解釈を生成し直す場合は審査し直す（公開後に問題のある内容へ書き換えられないようにする）
+	var status models.QuizStatus
+	var reasons []string
+	if input.AuthorInterpretation != nil || title != nil {
+		newTitle := current.Title
+		if title != nil {
+			newTitle = *title
+		}
+		status, reasons, err = s.moderate(ctx, &moderation.Content{
+			Texts: append([]string{authorInterpretation, newTitle}, current.Tags...),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// AIによる代替解釈の再生成（保存済みの画像と新しい投稿者の解釈を使用）
+	var decoys []string
+	if input.RegenerateDecoys {
+		imageData, err := s.storageClient.GetImage(ctx, current.ImagePath)
+		if err != nil {
+			return nil, fmt.Errorf("画像の取得に失敗: %w", err)
+		}
+		count := len(current.Decoys())
+		if count < MinDecoyCount {
+			count = DefaultDecoyCount
+		}
+		// 形式を記録する前に作成したクイズでは、画像データから判定する
+		mimeType := current.ImageMimeType
+		if mimeType == "" {
+			mimeType = imaging.DetectType(imageData)
+		}
+		decoys, err = s.generateDecoys(ctx, imageData, mimeType, authorInterpretation, count)
+		if err != nil {
+			return nil, fmt.Errorf("AIによる解釈の生成に失敗: %w", err)
+		}
+		status, reasons, err = s.moderateDecoys(ctx, decoys, status, reasons)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	quiz, err := s.storageClient.UpdateQuiz(ctx, quizID, func(quiz *models.Quiz) error {
+		quiz.AuthorInterpretation = authorInterpretation
+		if title != nil {
+			quiz.Title = *title
+		}
+		if decoys != nil {
+			quiz.AIInterpretation = decoys[0]
+			quiz.AIInterpretations = decoys
+		}
+		// 審査で保留された場合のみ状態を変える（拒否済みや保留中のクイズが編集で公開されないようにする）
+		if status == models.QuizFlagged && quiz.Status.Public() {
+			quiz.Status = status
+			quiz.ModerationReasons = reasons
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("クイズの更新に失敗: %w", err)
 	}
-	shuffle(interpretations)
-	return interpretations
+	return quiz, nil
 }
 
-// VerifyAnswer は回答が正しいかを検証します
-func (s *QuizServiceImpl) VerifyAnswer(quiz *models.Quiz, selectedInterpretation string) bool {
-	return selectedInterpretation == quiz.AuthorInterpretation
+// DeleteQuiz はクイズを画像や回答の記録とともに削除します
+func (s *QuizServiceImpl) DeleteQuiz(ctx context.Context, quizID string) error {
+	if quizID == "" {
+		return fmt.Errorf("クイズIDが必要です")
+	}
+	if err := s.storageClient.DeleteQuiz(ctx, quizID); err != nil {
+		return fmt.Errorf("クイズの削除に失敗: %w", err)
+	}
+	return nil
 }
 
 // generateID は一意のIDを生成します
@@ -110,24 +463,22 @@ func generateID() string {
 	return fmt.Sprintf("quiz_%d", time.Now().UnixNano())
 }
 
-// shuffle はスライスの要素をランダムに並び替えます
-func shuffle(slice []string) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for i := len(slice) - 1; i > 0; i-- {
-		j := r.Intn(i + 1)
-		slice[i], slice[j] = slice[j], slice[i]
-	}
-}
-
-// GetSignedImageURL は画像の署名付きURLを生成します
-func (s *QuizServiceImpl) GetSignedImageURL(ctx context.Context, imagePath string) (string, error) {
-	logging.Debug("GetSignedImageURL: imagePath=%s の署名付きURL生成を開始", imagePath)
-	if imagePath == "" {
+// GetSignedImageURL は指定された種類の画像の署名付きURLを生成します
+// 派生画像が生成されていないクイズでは、どの種類を指定しても元の画像のURLを返します
+// 署名付きURLを発行できない場合は、サーバーの画像プロキシのURLを返します
+func (s *QuizServiceImpl) GetSignedImageURL(ctx context.Context, image models.QuizImage, variant models.ImageVariant) (string, error) {
+	logging.Debug("GetSignedImageURL: imagePath=%s, variant=%s の署名付きURL生成を開始", image.Path, variant)
+	if image.Path == "" {
 		logging.Error("GetSignedImageURL: 画像パスが空です")
 		return "", fmt.Errorf("画像パスが必要です")
 	}
 
-	signedURL, err := s.storageClient.GenerateSignedURL(ctx, imagePath)
+	objectPath := image.PathFor(variant)
+	signedURL, err := s.storageClient.GenerateSignedURL(ctx, objectPath)
+	if errors.Is(err, storage.ErrSigningUnavailable) && image.QuizID != "" {
+		// 画像プロキシはパスのクイズIDで閲覧できるかを確かめる
+		return s.imageBaseURL + ImageProxyPrefix + image.QuizID + "/" + path.Base(objectPath), nil
+	}
 	if err != nil {
 		logging.Error("GetSignedImageURL: 署名付きURLの生成に失敗: %v", err)
 		return "", fmt.Errorf("署名付きURLの生成に失敗: %w", err)
@@ -137,13 +488,115 @@ func (s *QuizServiceImpl) GetSignedImageURL(ctx context.Context, imagePath strin
 	return signedURL, nil
 }
 
-// GetQuizList はすべてのクイズを取得します
-func (s *QuizServiceImpl) GetQuizList(ctx context.Context) ([]*models.Quiz, error) {
-	quizzes, err := s.storageClient.GetQuizzes(ctx)
+// GetImageQuiz は画像プロキシで配信する画像と、その画像を使っているクイズを取得します
+// クイズが存在しない場合や、画像（派生画像を含む）がそのクイズのものでない場合は storage.ErrImageNotFound を返します
+func (s *QuizServiceImpl) GetImageQuiz(ctx context.Context, quizID, imagePath string) (*models.Quiz, error) {
+	quiz, err := s.storageClient.GetQuiz(ctx, quizID)
+	if errors.Is(err, storage.ErrQuizNotFound) {
+		return nil, fmt.Errorf("%w: %s", storage.ErrImageNotFound, imagePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("画像のクイズの取得に失敗: %w", err)
+	}
+	image := quiz.Image()
+	for _, variant := range models.ImageVariants {
+		if image.Path != "" && image.PathFor(variant) == imagePath {
+			return quiz, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s はクイズ %s の画像ではありません", storage.ErrImageNotFound, imagePath, quizID)
+}
+
+// StatImage は画像プロキシで配信する画像の属性を取得します
+// 存在しない画像の場合は storage.ErrImageNotFound を返します
+func (s *QuizServiceImpl) StatImage(ctx context.Context, imagePath string) (*models.ImageInfo, error) {
+	info, err := s.storageClient.StatImage(ctx, imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("画像の取得に失敗: %w", err)
+	}
+	return info, nil
+}
+
+// OpenImage は画像プロキシで配信する画像の offset バイト目から length バイトを読み出す Reader を返します
+// length が負の場合は最後まで読み出します
+func (s *QuizServiceImpl) OpenImage(ctx context.Context, imagePath string, offset, length int64) (io.ReadCloser, error) {
+	reader, err := s.storageClient.OpenImage(ctx, imagePath, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("画像の読み込みに失敗: %w", err)
+	}
+	return reader, nil
+}
+
+// GetQuizList は条件に一致するクイズを1ページ分取得します
+// 取得件数が未指定の場合は DefaultPageSize 件、MaxPageSize を超える場合は MaxPageSize 件になります
+func (s *QuizServiceImpl) GetQuizList(ctx context.Context, query *models.QuizListQuery) (*models.QuizPage, error) {
+	q := models.QuizListQuery{}
+	if query != nil {
+		q = *query
+	}
+	switch {
+	case q.Limit <= 0:
+		q.Limit = DefaultPageSize
+	case q.Limit > MaxPageSize:
+		q.Limit = MaxPageSize
+	}
+	if q.Order == "" {
+		q.Order = models.SortNewestFirst
+	}
+	q.Tag = normalizeTag(q.Tag)
+
+	page, err := s.storageClient.ListQuizzes(ctx, &q)
 	if err != nil {
 		return nil, fmt.Errorf("クイズ一覧の取得に失敗: %w", err)
 	}
-	return quizzes, nil
+	return page, nil
+}
+
+// validateInterpretation は投稿者の解釈が空でないことと、長さを検証します
+// 長さはバイト数ではなく文字数で数えます
+func validateInterpretation(interpretation string) error {
+	if interpretation == "" {
+		return fmt.Errorf("%w: 投稿者の解釈が必要です", ErrInvalidInterpretation)
+	}
+	if utf8.RuneCountInString(interpretation) > MaxInterpretationLength {
+		return fmt.Errorf("%w: 投稿者の解釈は%d文字以内で指定してください", ErrInvalidInterpretation, MaxInterpretationLength)
+	}
+	return nil
+}
+
+// normalizeTitle はタイトルの前後の空白を除き、長さを検証します
+func normalizeTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if utf8.RuneCountInString(title) > MaxTitleLength {
+		return "", fmt.Errorf("%w: タイトルは%d文字以内で指定してください", ErrInvalidTitle, MaxTitleLength)
+	}
+	return title, nil
+}
+
+// normalizeTag は表記の揺れを吸収するため、前後の空白を除き英字を小文字にします
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags はタグを正規化し、空のタグと重複を取り除きます
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("%w: タグは%d文字以内で指定してください: %q", ErrInvalidTag, MaxTagLength, tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("%w: タグは%d個まで指定できます: %d", ErrInvalidTag, MaxTags, len(normalized))
+	}
+	return normalized, nil
 }
 
 // DeleteAllQuizzes は全てのクイズを削除します
@@ -1,13 +1,27 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/ai"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/imaging"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
 )
 
 // MockStorageClient はStorageClientのモック
@@ -15,11 +29,21 @@ type MockStorageClient struct {
 	mock.Mock
 }
 
-func (m *MockStorageClient) SaveImage(ctx context.Context, imageData []byte) (string, error) {
-	args := m.Called(ctx, imageData)
+func (m *MockStorageClient) SaveImage(ctx context.Context, imageData []byte, contentType string) (string, error) {
+	args := m.Called(ctx, imageData, contentType)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockStorageClient) SaveImageVariant(ctx context.Context, imagePath string, variant models.ImageVariant, imageData []byte) (string, error) {
+	args := m.Called(ctx, imagePath, variant, imageData)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorageClient) DeleteImage(ctx context.Context, image models.QuizImage) error {
+	args := m.Called(ctx, image)
+	return args.Error(0)
+}
+
 func (m *MockStorageClient) SaveQuiz(ctx context.Context, quiz *models.Quiz) error {
 	args := m.Called(ctx, quiz)
 	return args.Error(0)
@@ -43,22 +67,96 @@ func (m *MockStorageClient) GetQuizzes(ctx context.Context) ([]*models.Quiz, err
 	return args.Get(0).([]*models.Quiz), args.Error(1)
 }
 
+func (m *MockStorageClient) ListQuizzes(ctx context.Context, query *models.QuizListQuery) (*models.QuizPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuizPage), args.Error(1)
+}
+
 func (m *MockStorageClient) DeleteAllQuizzes(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
 
+func (m *MockStorageClient) SaveAnswer(ctx context.Context, answer *models.Answer) error {
+	args := m.Called(ctx, answer)
+	return args.Error(0)
+}
+
+func (m *MockStorageClient) GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error) {
+	args := m.Called(ctx, quizID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuizStats), args.Error(1)
+}
+
+func (m *MockStorageClient) GetImage(ctx context.Context, imagePath string) ([]byte, error) {
+	args := m.Called(ctx, imagePath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockStorageClient) StatImage(ctx context.Context, imagePath string) (*models.ImageInfo, error) {
+	args := m.Called(ctx, imagePath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImageInfo), args.Error(1)
+}
+
+func (m *MockStorageClient) OpenImage(ctx context.Context, imagePath string, offset, length int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, imagePath, offset, length)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorageClient) UpdateQuiz(ctx context.Context, quizID string, mutate func(quiz *models.Quiz) error) (*models.Quiz, error) {
+	args := m.Called(ctx, quizID, mutate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	// 保存済みのクイズに変更を適用して返す
+	quiz := *args.Get(0).(*models.Quiz)
+	if err := mutate(&quiz); err != nil {
+		return nil, err
+	}
+	return &quiz, args.Error(1)
+}
+
+func (m *MockStorageClient) DeleteQuiz(ctx context.Context, quizID string) error {
+	args := m.Called(ctx, quizID)
+	return args.Error(0)
+}
+
 // MockAIClient はAIClientのモック
 type MockAIClient struct {
 	mock.Mock
 }
 
-func (m *MockAIClient) GenerateInterpretation(ctx context.Context, imageData []byte, authorInterpretation string) (string, error) {
-	args := m.Called(ctx, imageData, authorInterpretation)
+func (m *MockAIClient) GenerateInterpretation(ctx context.Context, imageData []byte, mimeType, authorInterpretation string, persona ai.Persona) (string, error) {
+	args := m.Called(ctx, imageData, mimeType, authorInterpretation, persona)
 	return args.String(0), args.Error(1)
 }
 
+// testPNG はデコードできる小さな PNG 画像を返します
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("png.Encode failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
 func TestCreateQuiz(t *testing.T) {
+	imageData := testPNG(t)
 	tests := []struct {
 		name                 string
 		imageData            []byte
@@ -72,7 +170,7 @@ func TestCreateQuiz(t *testing.T) {
 	}{
 		{
 			name:                 "正常系：すべての処理が成功",
-			imageData:            []byte("test image"),
+			imageData:            imageData,
 			authorInterpretation: "投稿者の解釈",
 			mockAIResponse:       "AIの解釈",
 			mockImagePath:        "/images/test.jpg",
@@ -86,13 +184,19 @@ func TestCreateQuiz(t *testing.T) {
 		},
 		{
 			name:                 "異常系：解釈なし",
-			imageData:            []byte("test image"),
+			imageData:            imageData,
 			authorInterpretation: "",
 			wantError:            true,
 		},
+		{
+			name:                 "異常系：画像として読めない",
+			imageData:            []byte("not an image"),
+			authorInterpretation: "投稿者の解釈",
+			wantError:            true,
+		},
 		{
 			name:                 "異常系：画像保存エラー",
-			imageData:            []byte("test image"),
+			imageData:            imageData,
 			authorInterpretation: "投稿者の解釈",
 			mockImageError:       fmt.Errorf("storage error"),
 			wantError:            true,
@@ -103,17 +207,21 @@ func TestCreateQuiz(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// モックの設定
 			mockAI := &MockAIClient{}
-			mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything).Return(tt.mockAIResponse, tt.mockAIError)
+			mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.mockAIResponse, tt.mockAIError)
 
 			mockStorage := &MockStorageClient{}
-			mockStorage.On("SaveImage", mock.Anything, mock.Anything).Return(tt.mockImagePath, tt.mockImageError)
+			mockStorage.On("SaveImage", mock.Anything, mock.Anything, mock.Anything).Return(tt.mockImagePath, tt.mockImageError)
+			mockStorage.On("SaveImageVariant", mock.Anything, tt.mockImagePath, mock.Anything, mock.Anything).Return("", nil)
 			mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(tt.mockSaveQuizError)
 			mockStorage.On("GetQuizzes", mock.Anything).Return([]*models.Quiz{}, nil)
 
 			service := NewQuizService(mockAI, mockStorage)
 
 			// テストの実行
-			quiz, err := service.CreateQuiz(context.Background(), tt.imageData, tt.authorInterpretation)
+			quiz, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+				ImageData:            tt.imageData,
+				AuthorInterpretation: tt.authorInterpretation,
+			})
 
 			// 結果の検証
 			if tt.wantError {
@@ -130,78 +238,244 @@ func TestCreateQuiz(t *testing.T) {
 			if quiz.ImagePath != tt.mockImagePath {
 				t.Errorf("expected image path %q, got %q", tt.mockImagePath, quiz.ImagePath)
 			}
+			assert.Equal(t, []models.ImageVariant{models.ImageThumbnail, models.ImageMedium}, quiz.ImageVariants)
+			mockStorage.AssertCalled(t, "SaveImageVariant", mock.Anything, tt.mockImagePath, models.ImageThumbnail, mock.Anything)
+			mockStorage.AssertCalled(t, "SaveImageVariant", mock.Anything, tt.mockImagePath, models.ImageMedium, mock.Anything)
 			if quiz.AuthorInterpretation != tt.authorInterpretation {
 				t.Errorf("expected author interpretation %q, got %q", tt.authorInterpretation, quiz.AuthorInterpretation)
 			}
 			if quiz.AIInterpretation != tt.mockAIResponse {
 				t.Errorf("expected AI interpretation %q, got %q", tt.mockAIResponse, quiz.AIInterpretation)
 			}
+			if len(quiz.AIInterpretations) != DefaultDecoyCount {
+				t.Errorf("expected %d AI interpretations, got %d", DefaultDecoyCount, len(quiz.AIInterpretations))
+			}
 		})
 	}
 }
 
-func TestGetRandomizedInterpretations(t *testing.T) {
-	service := NewQuizService(nil, nil) // 依存関係不要
+func TestCreateQuiz_ImageMimeType(t *testing.T) {
+	imageData := testPNG(t)
+	tests := []struct {
+		name     string
+		mimeType string
+	}{
+		{name: "検証時に判定した形式", mimeType: "image/png"},
+		{name: "未指定の場合は画像データから判定", mimeType: ""},
+	}
 
-	quiz := &models.Quiz{
-		AuthorInterpretation: "投稿者の解釈",
-		AIInterpretation:     "AIの解釈",
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAI := &MockAIClient{}
+			mockAI.On("GenerateInterpretation", mock.Anything, imageData, "image/png", mock.Anything, mock.Anything).Return("AIの解釈", nil)
+
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("SaveImage", mock.Anything, imageData, "image/png").Return("images/test.png", nil)
+			mockStorage.On("SaveImageVariant", mock.Anything, "images/test.png", mock.Anything, mock.Anything).Return("", nil)
+			mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(nil)
+
+			quiz, err := NewQuizService(mockAI, mockStorage).CreateQuiz(context.Background(), &CreateQuizInput{
+				ImageData:            imageData,
+				ImageMimeType:        tt.mimeType,
+				AuthorInterpretation: "投稿者の解釈",
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "images/test.png", quiz.ImagePath)
+			assert.Equal(t, "image/png", quiz.ImageMimeType)
+			mockStorage.AssertExpectations(t)
+			mockAI.AssertExpectations(t)
+		})
 	}
+}
 
-	// 複数回実行して、順序がランダムになることを確認
-	seen := make(map[string]bool)
-	for i := 0; i < 10; i++ {
-		interpretations := service.GetRandomizedInterpretations(quiz)
-		if len(interpretations) != 2 {
-			t.Errorf("expected 2 interpretations, got %d", len(interpretations))
-		}
-		order := interpretations[0] + "|" + interpretations[1]
-		seen[order] = true
+func TestCreateQuiz_NormalizesImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.White}), nil); err != nil {
+		t.Fatalf("gif.Encode failed: %v", err)
+	}
+
+	mockAI := &MockAIClient{}
+	mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, "image/jpeg", mock.Anything, mock.Anything).Return("AIの解釈", nil)
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("SaveImage", mock.Anything, mock.MatchedBy(func(data []byte) bool {
+		_, err := jpeg.Decode(bytes.NewReader(data))
+		return err == nil
+	}), "image/jpeg").Return("images/test.jpg", nil)
+	mockStorage.On("SaveImageVariant", mock.Anything, "images/test.jpg", mock.Anything, mock.Anything).Return("", nil)
+	mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(nil)
+	service := NewQuizService(mockAI, mockStorage)
+
+	_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+		ImageData:            buf.Bytes(),
+		ImageMimeType:        "image/gif",
+		AuthorInterpretation: "投稿者の解釈",
+	})
+	if assert.NoError(t, err) {
+		mockStorage.AssertExpectations(t)
+		mockAI.AssertExpectations(t)
 	}
 
-	// 少なくとも2つの異なる順序が観察されることを期待
-	if len(seen) < 2 {
-		t.Error("randomization doesn't seem to be working")
+	// デコーダーのない形式や壊れた画像は ErrInvalidImage
+	for _, input := range []*CreateQuizInput{
+		{ImageData: []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), ImageMimeType: "image/webp", AuthorInterpretation: "投稿者の解釈"},
+		{ImageData: []byte("not an image"), AuthorInterpretation: "投稿者の解釈"},
+	} {
+		_, err := service.CreateQuiz(context.Background(), input)
+		assert.ErrorIs(t, err, ErrInvalidImage)
 	}
 }
 
-func TestVerifyAnswer(t *testing.T) {
-	service := NewQuizService(nil, nil) // 依存関係不要
+func TestCreateQuiz_StripsMetadata(t *testing.T) {
+	// GPS を含む EXIF と向き 6（時計回りに90度）を持つ 64x32 の JPEG
+	imageData, err := os.ReadFile("../../fixture/exif/gps_rotated.jpg")
+	if err != nil {
+		t.Fatalf("フィクスチャの読み込みに失敗: %v", err)
+	}
+	stripped := mock.MatchedBy(func(data []byte) bool {
+		if bytes.Contains(data, []byte("Exif")) || bytes.Contains(data, []byte("GPSLatitude")) {
+			return false
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		return err == nil && img.Bounds().Dx() == 32 && img.Bounds().Dy() == 64
+	})
 
-	quiz := &models.Quiz{
+	mockAI := &MockAIClient{}
+	mockAI.On("GenerateInterpretation", mock.Anything, stripped, "image/jpeg", mock.Anything, mock.Anything).Return("AIの解釈", nil)
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("SaveImage", mock.Anything, stripped, "image/jpeg").Return("images/test.jpg", nil)
+	mockStorage.On("SaveImageVariant", mock.Anything, "images/test.jpg", mock.Anything, mock.Anything).Return("", nil)
+	mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(nil)
+	service := NewQuizService(mockAI, mockStorage)
+
+	_, err = service.CreateQuiz(context.Background(), &CreateQuizInput{
+		ImageData:            imageData,
+		ImageMimeType:        "image/jpeg",
 		AuthorInterpretation: "投稿者の解釈",
-		AIInterpretation:     "AIの解釈",
+	})
+	if assert.NoError(t, err) {
+		mockStorage.AssertExpectations(t)
+		mockAI.AssertExpectations(t)
+	}
+}
+
+func TestCreateQuiz_MultipleDecoys(t *testing.T) {
+	imageData := testPNG(t)
+	tests := []struct {
+		name       string
+		decoyCount int
+		wantError  bool
+	}{
+		{name: "正常系：おとり3つ", decoyCount: 3},
+		{name: "正常系：おとり最大数", decoyCount: MaxDecoyCount},
+		{name: "異常系：おとりが多すぎる", decoyCount: MaxDecoyCount + 1, wantError: true},
+		{name: "異常系：おとりが負の数", decoyCount: -1, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAI := &MockAIClient{}
+			for i := 0; i < MaxDecoyCount; i++ {
+				persona := ai.PersonaAt(i)
+				mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, persona).Return("AIの解釈："+persona.Name, nil)
+			}
+
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("SaveImage", mock.Anything, mock.Anything, mock.Anything).Return("images/test.jpg", nil)
+			mockStorage.On("SaveImageVariant", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", nil)
+			mockStorage.On("SaveQuiz", mock.Anything, mock.Anything).Return(nil)
+
+			service := NewQuizService(mockAI, mockStorage)
+			quiz, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+				ImageData:            imageData,
+				AuthorInterpretation: "投稿者の解釈",
+				DecoyCount:           tt.decoyCount,
+				AuthorID:             "author-1",
+			})
+
+			if tt.wantError {
+				assert.Error(t, err)
+				mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, "author-1", quiz.AuthorID)
+			assert.Len(t, quiz.AIInterpretations, tt.decoyCount)
+			assert.Equal(t, quiz.AIInterpretations[0], quiz.AIInterpretation)
+			seen := make(map[string]bool)
+			for i, decoy := range quiz.AIInterpretations {
+				assert.Equal(t, "AIの解釈："+ai.PersonaAt(i).Name, decoy)
+				seen[decoy] = true
+			}
+			assert.Len(t, seen, tt.decoyCount, "each decoy should use a different persona")
+		})
 	}
+}
 
+func TestGetSignedImageURL(t *testing.T) {
 	tests := []struct {
-		name                   string
-		selectedInterpretation string
-		want                   bool
+		name     string
+		image    models.QuizImage
+		variant  models.ImageVariant
+		wantPath string
 	}{
 		{
-			name:                   "正解の場合",
-			selectedInterpretation: "投稿者の解釈",
-			want:                   true,
+			name:     "サムネイル",
+			image:    models.QuizImage{Path: "images/quiz_1.png", Variants: []models.ImageVariant{models.ImageThumbnail, models.ImageMedium}},
+			variant:  models.ImageThumbnail,
+			wantPath: "images/quiz_1_thumbnail.jpg",
 		},
 		{
-			name:                   "不正解の場合",
-			selectedInterpretation: "AIの解釈",
-			want:                   false,
+			name:     "元の画像",
+			image:    models.QuizImage{Path: "images/quiz_1.png", Variants: []models.ImageVariant{models.ImageThumbnail, models.ImageMedium}},
+			variant:  models.ImageFull,
+			wantPath: "images/quiz_1.png",
 		},
 		{
-			name:                   "存在しない解釈の場合",
-			selectedInterpretation: "別の解釈",
-			want:                   false,
+			name:     "派生画像のない古いクイズ",
+			image:    models.QuizImage{Path: "images/quiz_1.jpg"},
+			variant:  models.ImageMedium,
+			wantPath: "images/quiz_1.jpg",
 		},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := service.VerifyAnswer(quiz, tt.selectedInterpretation); got != tt.want {
-				t.Errorf("VerifyAnswer() = %v, want %v", got, tt.want)
-			}
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("GenerateSignedURL", mock.Anything, tt.wantPath).Return("https://example.com/"+tt.wantPath, nil)
+			service := NewQuizService(&MockAIClient{}, mockStorage)
+
+			got, err := service.GetSignedImageURL(context.Background(), tt.image, tt.variant)
+			assert.NoError(t, err)
+			assert.Equal(t, "https://example.com/"+tt.wantPath, got)
 		})
 	}
+
+	service := NewQuizService(&MockAIClient{}, &MockStorageClient{})
+	_, err := service.GetSignedImageURL(context.Background(), models.QuizImage{}, models.ImageFull)
+	assert.Error(t, err)
+}
+
+func TestGetSignedImageURL_ImageProxy(t *testing.T) {
+	image := models.QuizImage{QuizID: "quiz_1", Path: "images/quiz_1.png", Variants: []models.ImageVariant{models.ImageThumbnail}}
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("GenerateSignedURL", mock.Anything, mock.Anything).Return("", storage.ErrSigningUnavailable)
+
+	// 署名付きURLを発行できない場合は、クイズIDを含む画像プロキシのURLを返す
+	got, err := NewQuizService(&MockAIClient{}, mockStorage).GetSignedImageURL(context.Background(), image, models.ImageThumbnail)
+	assert.NoError(t, err)
+	assert.Equal(t, "/images/quiz_1/quiz_1_thumbnail.jpg", got)
+
+	got, err = NewQuizService(&MockAIClient{}, mockStorage, WithImageBaseURL("https://api.example.com/")).GetSignedImageURL(context.Background(), image, models.ImageFull)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/images/quiz_1/quiz_1.png", got)
+
+	// クイズが分からない画像はプロキシで配信できない
+	_, err = NewQuizService(&MockAIClient{}, mockStorage).GetSignedImageURL(context.Background(), models.QuizImage{Path: "images/quiz_1.png"}, models.ImageFull)
+	assert.ErrorIs(t, err, storage.ErrSigningUnavailable)
 }
 
 func TestDeleteAllQuizzes(t *testing.T) {
@@ -246,3 +520,344 @@ func TestDeleteAllQuizzes(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateQuiz(t *testing.T) {
+	stored := &models.Quiz{
+		ID:                   "quiz-1",
+		ImagePath:            "images/test.jpg",
+		ImageMimeType:        "image/jpeg",
+		AuthorInterpretation: "元の解釈",
+		AIInterpretation:     "古いおとり1",
+		AIInterpretations:    []string{"古いおとり1", "古いおとり2"},
+	}
+	newInterpretation := "新しい解釈"
+	empty := ""
+
+	tests := []struct {
+		name       string
+		input      *UpdateQuizInput
+		wantAuthor string
+		wantDecoys []string
+		wantError  bool
+	}{
+		{
+			name:       "正常系：投稿者の解釈のみ変更",
+			input:      &UpdateQuizInput{AuthorInterpretation: &newInterpretation},
+			wantAuthor: newInterpretation,
+			wantDecoys: stored.AIInterpretations,
+		},
+		{
+			name:       "正常系：おとりを同じ数だけ再生成",
+			input:      &UpdateQuizInput{AuthorInterpretation: &newInterpretation, RegenerateDecoys: true},
+			wantAuthor: newInterpretation,
+			wantDecoys: []string{"新しいおとり：" + ai.PersonaAt(0).Name, "新しいおとり：" + ai.PersonaAt(1).Name},
+		},
+		{
+			name:      "異常系：変更内容なし",
+			input:     &UpdateQuizInput{},
+			wantError: true,
+		},
+		{
+			name:      "異常系：空の解釈",
+			input:     &UpdateQuizInput{AuthorInterpretation: &empty},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAI := &MockAIClient{}
+			for i := 0; i < MaxDecoyCount; i++ {
+				persona := ai.PersonaAt(i)
+				// 画像データから判定せず、作成時に記録した形式を使う
+				mockAI.On("GenerateInterpretation", mock.Anything, []byte("image"), "image/jpeg", newInterpretation, persona).Return("新しいおとり："+persona.Name, nil)
+			}
+
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("GetQuiz", mock.Anything, "quiz-1").Return(stored, nil)
+			mockStorage.On("GetImage", mock.Anything, "images/test.jpg").Return([]byte("image"), nil)
+			mockStorage.On("UpdateQuiz", mock.Anything, "quiz-1", mock.Anything).Return(stored, nil)
+
+			service := NewQuizService(mockAI, mockStorage)
+			quiz, err := service.UpdateQuiz(context.Background(), "quiz-1", tt.input)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				mockStorage.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantAuthor, quiz.AuthorInterpretation)
+			assert.Equal(t, tt.wantDecoys, quiz.Decoys())
+			if !tt.input.RegenerateDecoys {
+				mockAI.AssertNotCalled(t, "GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUpdateQuiz_LegacyImageMimeType(t *testing.T) {
+	// 形式を記録する前に作成したクイズでは、保存済みの画像データから判定する
+	imageData := testPNG(t)
+	stored := &models.Quiz{ID: "quiz-1", ImagePath: "images/test.png", AuthorInterpretation: "元の解釈", AIInterpretation: "古いおとり"}
+
+	mockAI := &MockAIClient{}
+	mockAI.On("GenerateInterpretation", mock.Anything, imageData, "image/png", "元の解釈", mock.Anything).Return("新しいおとり", nil)
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("GetQuiz", mock.Anything, "quiz-1").Return(stored, nil)
+	mockStorage.On("GetImage", mock.Anything, "images/test.png").Return(imageData, nil)
+	mockStorage.On("UpdateQuiz", mock.Anything, "quiz-1", mock.Anything).Return(stored, nil)
+
+	_, err := NewQuizService(mockAI, mockStorage).UpdateQuiz(context.Background(), "quiz-1", &UpdateQuizInput{RegenerateDecoys: true})
+	assert.NoError(t, err)
+	mockAI.AssertExpectations(t)
+}
+
+func TestDeleteQuiz(t *testing.T) {
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("DeleteQuiz", mock.Anything, "quiz-1").Return(nil)
+	mockStorage.On("DeleteQuiz", mock.Anything, "missing").Return(fmt.Errorf("%w: missing", storage.ErrQuizNotFound))
+
+	service := NewQuizService(&MockAIClient{}, mockStorage)
+
+	assert.NoError(t, service.DeleteQuiz(context.Background(), "quiz-1"))
+	assert.ErrorIs(t, service.DeleteQuiz(context.Background(), "missing"), storage.ErrQuizNotFound)
+	assert.Error(t, service.DeleteQuiz(context.Background(), ""))
+	mockStorage.AssertExpectations(t)
+}
+
+func TestGetImageQuiz(t *testing.T) {
+	quiz := &models.Quiz{ID: "quiz-1", ImagePath: "images/quiz-1.png", ImageVariants: []models.ImageVariant{models.ImageThumbnail}}
+	mockStorage := &MockStorageClient{}
+	mockStorage.On("GetQuiz", mock.Anything, "quiz-1").Return(quiz, nil)
+	mockStorage.On("GetQuiz", mock.Anything, "deleted").Return(nil, fmt.Errorf("%w: deleted", storage.ErrQuizNotFound))
+
+	service := NewQuizService(&MockAIClient{}, mockStorage)
+
+	got, err := service.GetImageQuiz(context.Background(), "quiz-1", "images/quiz-1.png")
+	assert.NoError(t, err)
+	assert.Equal(t, quiz, got)
+	got, err = service.GetImageQuiz(context.Background(), "quiz-1", "images/quiz-1_thumbnail.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, quiz, got)
+	// 別のクイズの画像は配信しない
+	_, err = service.GetImageQuiz(context.Background(), "quiz-1", "images/quiz-2.png")
+	assert.ErrorIs(t, err, storage.ErrImageNotFound)
+	_, err = service.GetImageQuiz(context.Background(), "deleted", "images/deleted.png")
+	assert.ErrorIs(t, err, storage.ErrImageNotFound)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestGetQuizList(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *models.QuizListQuery
+		want  models.QuizListQuery
+	}{
+		{
+			name:  "正常系：未指定の場合はデフォルト",
+			query: nil,
+			want:  models.QuizListQuery{Order: models.SortNewestFirst, Limit: DefaultPageSize},
+		},
+		{
+			name:  "正常系：取得件数は最大件数まで",
+			query: &models.QuizListQuery{Order: models.SortOldestFirst, Limit: MaxPageSize + 1},
+			want:  models.QuizListQuery{Order: models.SortOldestFirst, Limit: MaxPageSize},
+		},
+		{
+			name:  "正常系：タグを正規化",
+			query: &models.QuizListQuery{Tag: " Night ", AuthorID: "author-1", Limit: 5, Cursor: "abc"},
+			want:  models.QuizListQuery{Tag: "night", AuthorID: "author-1", Order: models.SortNewestFirst, Limit: 5, Cursor: "abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &models.QuizPage{Quizzes: []*models.QuizSummary{{QuizIndexEntry: models.QuizIndexEntry{ID: "quiz-1"}}}, NextCursor: "next"}
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("ListQuizzes", mock.Anything, &tt.want).Return(page, nil)
+
+			service := NewQuizService(&MockAIClient{}, mockStorage)
+			got, err := service.GetQuizList(context.Background(), tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, page, got)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" 風景 ", "Night", "night", "", "夜"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"風景", "night", "夜"}, tags)
+
+	tooMany := make([]string, MaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+	_, err = normalizeTags(tooMany)
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	_, err = normalizeTags([]string{strings.Repeat("長", MaxTagLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	// 文字数はバイト数ではなく文字単位で数える
+	_, err = normalizeTags([]string{strings.Repeat("長", MaxTagLength)})
+	assert.NoError(t, err)
+}
+
+func TestValidateInterpretation(t *testing.T) {
+	// 日本語と英数字が混ざった解釈でも、バイト数ではなく文字数で数える
+	mixed := strings.Repeat("夕暮れのTokyo、", MaxInterpretationLength/10)
+	if n := utf8.RuneCountInString(mixed); n != MaxInterpretationLength {
+		t.Fatalf("test data must be %d characters, got %d", MaxInterpretationLength, n)
+	}
+	assert.NoError(t, validateInterpretation(mixed))
+	assert.NoError(t, validateInterpretation(strings.Repeat("長", MaxInterpretationLength)))
+
+	assert.ErrorIs(t, validateInterpretation(mixed+"a"), ErrInvalidInterpretation)
+	assert.ErrorIs(t, validateInterpretation(mixed+"長"), ErrInvalidInterpretation)
+	assert.ErrorIs(t, validateInterpretation(""), ErrInvalidInterpretation)
+}
+
+func TestCreateQuiz_InterpretationTooLong(t *testing.T) {
+	mockAI := &MockAIClient{}
+	mockStorage := &MockStorageClient{}
+	service := NewQuizService(mockAI, mockStorage)
+
+	_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+		ImageData:            testPNG(t),
+		AuthorInterpretation: strings.Repeat("長い解釈 with ASCII ", MaxInterpretationLength/10),
+	})
+	assert.ErrorIs(t, err, ErrInvalidInterpretation)
+	// 画像の保存やAIの呼び出しより前に検証する
+	mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+	mockAI.AssertNotCalled(t, "GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateQuiz_AIFailureDoesNotSaveImage(t *testing.T) {
+	mockAI := &MockAIClient{}
+	mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return("", fmt.Errorf("%w: 4回試行しました", ai.ErrUnavailable))
+	mockStorage := &MockStorageClient{}
+	service := NewQuizService(mockAI, mockStorage)
+
+	_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+		ImageData:            testPNG(t),
+		AuthorInterpretation: "投稿者の解釈",
+	})
+	assert.ErrorIs(t, err, ai.ErrUnavailable)
+	// AIサービスの障害で失敗した場合は、画像もクイズも保存しない
+	mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "SaveQuiz", mock.Anything, mock.Anything)
+}
+
+func TestCreateQuiz_RejectsHugeDimensions(t *testing.T) {
+	mockAI := &MockAIClient{}
+	mockStorage := &MockStorageClient{}
+	service := NewQuizService(mockAI, mockStorage)
+
+	// 65535x65535 の画面を宣言するだけの GIF のヘッダー（全体をデコードすると十数GBを確保する）
+	_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+		ImageData:            []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00"),
+		AuthorInterpretation: "テスト用の解釈",
+	})
+	assert.ErrorIs(t, err, ErrInvalidImage)
+	assert.ErrorContains(t, err, imaging.ErrImageTooLarge.Error())
+	mockAI.AssertNotCalled(t, "GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateQuiz_GenerateTimeout(t *testing.T) {
+	t.Run("生成全体が上限を超えた場合は ErrUnavailable", func(t *testing.T) {
+		mockAI := &MockAIClient{}
+		mockStorage := &MockStorageClient{}
+		service := NewQuizService(mockAI, mockStorage, WithGenerateTimeout(20*time.Millisecond))
+
+		// 期限が来るまで応答しないAIサービス
+		mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+			Return("", context.DeadlineExceeded)
+
+		_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+			ImageData:            testPNG(t),
+			AuthorInterpretation: "テスト用の解釈",
+			DecoyCount:           2,
+		})
+		assert.ErrorIs(t, err, ai.ErrUnavailable)
+		mockStorage.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("1つ失敗した場合は残りの生成を打ち切る", func(t *testing.T) {
+		mockAI := &MockAIClient{}
+		mockStorage := &MockStorageClient{}
+		service := NewQuizService(mockAI, mockStorage, WithGenerateTimeout(0))
+
+		mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, ai.PersonaAt(0)).
+			Return("", ai.ErrUnavailable)
+		// 打ち切られなければ終わらない生成
+		mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, ai.PersonaAt(1)).
+			Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+			Return("", context.Canceled)
+
+		_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+			ImageData:            testPNG(t),
+			AuthorInterpretation: "テスト用の解釈",
+			DecoyCount:           2,
+		})
+		assert.ErrorIs(t, err, ai.ErrUnavailable)
+		assert.NotErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCreateQuiz_DiscardsImageOnFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(m *MockStorageClient)
+		wantVariants []models.ImageVariant
+	}{
+		{
+			name: "派生画像の保存に失敗",
+			setup: func(m *MockStorageClient) {
+				m.On("SaveImageVariant", mock.Anything, "images/test.png", models.ImageThumbnail, mock.Anything).Return("images/test_thumb.jpg", nil)
+				m.On("SaveImageVariant", mock.Anything, "images/test.png", mock.Anything, mock.Anything).Return("", fmt.Errorf("write error"))
+			},
+			// どこまで保存できたかに関わらず、派生画像をすべて削除する
+			wantVariants: models.ImageVariants,
+		},
+		{
+			name: "クイズの保存に失敗",
+			setup: func(m *MockStorageClient) {
+				m.On("SaveImageVariant", mock.Anything, "images/test.png", mock.Anything, mock.Anything).Return("images/test_variant.jpg", nil)
+				m.On("SaveQuiz", mock.Anything, mock.Anything).Return(fmt.Errorf("write error"))
+			},
+			wantVariants: []models.ImageVariant{models.ImageThumbnail, models.ImageMedium},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAI := &MockAIClient{}
+			mockAI.On("GenerateInterpretation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("AIによる解釈", nil)
+			mockStorage := &MockStorageClient{}
+			mockStorage.On("SaveImage", mock.Anything, mock.Anything, "image/png").Return("images/test.png", nil)
+			tt.setup(mockStorage)
+			mockStorage.On("DeleteImage", mock.Anything, mock.MatchedBy(func(image models.QuizImage) bool {
+				return image.Path == "images/test.png" && assert.ObjectsAreEqual(tt.wantVariants, image.Variants)
+			})).Return(nil)
+			service := NewQuizService(mockAI, mockStorage)
+
+			_, err := service.CreateQuiz(context.Background(), &CreateQuizInput{
+				ImageData:            testPNG(t),
+				AuthorInterpretation: "投稿者の解釈",
+			})
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+// DefaultUploadSessionTTL はアップロードセッションの既定の有効期間です
+const DefaultUploadSessionTTL = 30 * time.Minute
+
+var (
+	// ErrInvalidUpload はアップロードセッションの作成内容が不正であることを表します
+	ErrInvalidUpload = errors.New("アップロードの内容が不正です")
+	// ErrUploadExpired はアップロードセッションの有効期限が切れていることを表します
+	ErrUploadExpired = errors.New("アップロードの有効期限が切れています")
+	// ErrUploadTooLarge はアップロードされたデータが画像の最大サイズを超えていることを表します
+	ErrUploadTooLarge = errors.New("アップロードされたデータが大きすぎます")
+)
+
+// CreateUploadInput はアップロードセッション作成の入力を表します
+type CreateUploadInput struct {
+	AuthorID    string
+	Filename    string
+	ContentType string
+	// Size はアップロードする画像の大きさ（バイト数）です
+	Size int64
+}
+
+// UploadTicket はアップロードセッションと、画像のアップロード先を表します
+type UploadTicket struct {
+	Session *models.UploadSession
+	// UploadURL は画像をバケットに直接 PUT する署名付きURLです
+	// 署名付きURLを発行できない場合は空で、クライアントはサーバーの分割アップロードのエンドポイントを使用します
+	UploadURL string
+	// Headers は UploadURL に PUT する際に送る必要のあるヘッダーです
+	Headers map[string]string
+}
+
+// UploadService はクイズの画像を2段階でアップロードする操作を提供するインターフェース
+// クライアントはセッションを作成し、バケットに直接（またはサーバーに分割して）画像をアップロードした後、
+// 画像を検証してクイズを作成します
+type UploadService interface {
+	CreateUploadSession(ctx context.Context, input *CreateUploadInput) (*UploadTicket, error)
+	GetUploadSession(ctx context.Context, uploadID string) (*models.UploadSession, error)
+	UploadedSize(ctx context.Context, uploadID string) (int64, error)
+	WriteUploadChunk(ctx context.Context, session *models.UploadSession, offset int64, data []byte) (int64, error)
+	OpenUpload(ctx context.Context, uploadID string) (io.ReadCloser, error)
+	DeleteUploadSession(ctx context.Context, uploadID string) error
+}
+
+// UploadServiceImpl はアップロードセッションの操作を実装します
+type UploadServiceImpl struct {
+	store   storage.UploadStore
+	maxSize int64
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// UploadOption はUploadServiceImplの設定を変更する関数です
+type UploadOption func(*UploadServiceImpl)
+
+// WithUploadSessionTTL はアップロードセッションの有効期間を設定します（0 以下の場合は DefaultUploadSessionTTL）
+func WithUploadSessionTTL(ttl time.Duration) UploadOption {
+	return func(s *UploadServiceImpl) {
+		if ttl > 0 {
+			s.ttl = ttl
+		}
+	}
+}
+
+// NewUploadService は新しいUploadServiceインスタンスを作成します
+// maxSize はアップロードできる画像の最大サイズで、画像の検証と同じく maxSize バイト以上は受け付けません
+func NewUploadService(store storage.UploadStore, maxSize int64, opts ...UploadOption) UploadService {
+	s := &UploadServiceImpl{
+		store:   store,
+		maxSize: maxSize,
+		ttl:     DefaultUploadSessionTTL,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateUploadSession はアップロードセッションを作成し、画像のアップロード先を返します
+func (s *UploadServiceImpl) CreateUploadSession(ctx context.Context, input *CreateUploadInput) (*UploadTicket, error) {
+	if input.AuthorID == "" {
+		return nil, fmt.Errorf("%w: 投稿者が必要です", ErrInvalidUpload)
+	}
+	if filepath.Ext(input.Filename) == "" {
+		return nil, fmt.Errorf("%w: 拡張子のあるファイル名が必要です", ErrInvalidUpload)
+	}
+	if input.Size <= 0 || input.Size >= s.maxSize {
+		return nil, fmt.Errorf("%w: size は1以上%d未満で指定してください", ErrInvalidUpload, s.maxSize)
+	}
+
+	now := s.now()
+	session := &models.UploadSession{
+		ID:          generateUploadID(),
+		AuthorID:    input.AuthorID,
+		Filename:    filepath.Base(input.Filename),
+		ContentType: input.ContentType,
+		Size:        input.Size,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+	if err := s.store.SaveUploadSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("アップロードセッションの作成に失敗: %w", err)
+	}
+
+	ticket := &UploadTicket{Session: session}
+	uploadURL, headers, err := s.store.GenerateUploadURL(ctx, session.ID, session.ContentType, s.maxSize-1, session.ExpiresAt)
+	switch {
+	case err == nil:
+		ticket.UploadURL = uploadURL
+		ticket.Headers = headers
+	case errors.Is(err, storage.ErrSigningUnavailable):
+		logging.Debug("CreateUploadSession: 署名付きURLを発行できないため分割アップロードを使用します: id=%s", session.ID)
+	default:
+		return nil, fmt.Errorf("アップロードURLの生成に失敗: %w", err)
+	}
+
+	logging.Info("CreateUploadSession: アップロードセッションを作成しました: id=%s, size=%d, direct=%t", session.ID, session.Size, ticket.UploadURL != "")
+	return ticket, nil
+}
+
+// GetUploadSession はアップロードセッションを取得します
+// 有効期限が切れている場合は ErrUploadExpired を返します
+func (s *UploadServiceImpl) GetUploadSession(ctx context.Context, uploadID string) (*models.UploadSession, error) {
+	session, err := s.store.GetUploadSession(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Expired(s.now()) {
+		return nil, fmt.Errorf("%w: %s", ErrUploadExpired, uploadID)
+	}
+	return session, nil
+}
+
+// UploadedSize はアップロードセッションの受信済みのバイト数を返します
+func (s *UploadServiceImpl) UploadedSize(ctx context.Context, uploadID string) (int64, error) {
+	return s.store.UploadedSize(ctx, uploadID)
+}
+
+// WriteUploadChunk は分割アップロードの1つ分のデータを offset の位置に書き込み、受信済みのバイト数を返します
+func (s *UploadServiceImpl) WriteUploadChunk(ctx context.Context, session *models.UploadSession, offset int64, data []byte) (int64, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("%w: 不正な開始位置です: %d", ErrInvalidUpload, offset)
+	}
+	if offset+int64(len(data)) >= s.maxSize {
+		return 0, fmt.Errorf("%w: 最大 %d バイト未満です", ErrUploadTooLarge, s.maxSize)
+	}
+	return s.store.AppendUpload(ctx, session.ID, offset, data)
+}
+
+// OpenUpload はアップロードされた画像を読み出す Reader を返します
+func (s *UploadServiceImpl) OpenUpload(ctx context.Context, uploadID string) (io.ReadCloser, error) {
+	return s.store.OpenUpload(ctx, uploadID)
+}
+
+// DeleteUploadSession はアップロードセッションとアップロードされた画像を削除します
+func (s *UploadServiceImpl) DeleteUploadSession(ctx context.Context, uploadID string) error {
+	return s.store.DeleteUploadSession(ctx, uploadID)
+}
+
+// generateUploadID は推測できないアップロードIDを生成します
+func generateUploadID() string {
+	return "upload_" + hex.EncodeToString(randomBytes(16))
+}
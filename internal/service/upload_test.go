@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/storage"
+)
+
+func newTestUploadService(t *testing.T) *UploadServiceImpl {
+	t.Helper()
+	store, err := storage.NewLocalClient(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	return NewUploadService(store, 100, WithUploadSessionTTL(time.Minute)).(*UploadServiceImpl)
+}
+
+func TestCreateUploadSession(t *testing.T) {
+	ctx := context.Background()
+	s := newTestUploadService(t)
+
+	ticket, err := s.CreateUploadSession(ctx, &CreateUploadInput{AuthorID: "author-1", Filename: "dir/photo.png", ContentType: "image/png", Size: 50})
+	if err != nil {
+		t.Fatalf("CreateUploadSession failed: %v", err)
+	}
+	// ローカルストレージでは署名付きURLを発行できないため、分割アップロードになる
+	if ticket.UploadURL != "" {
+		t.Errorf("expected no signed URL, got %q", ticket.UploadURL)
+	}
+	session := ticket.Session
+	if session.Filename != "photo.png" || session.AuthorID != "author-1" {
+		t.Errorf("unexpected session: %+v", session)
+	}
+	if got := session.ExpiresAt.Sub(session.CreatedAt); got != time.Minute {
+		t.Errorf("expected TTL 1m, got %s", got)
+	}
+	if _, err := s.GetUploadSession(ctx, session.ID); err != nil {
+		t.Errorf("GetUploadSession failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input *CreateUploadInput
+	}{
+		{name: "投稿者なし", input: &CreateUploadInput{Filename: "photo.png", Size: 50}},
+		{name: "拡張子なし", input: &CreateUploadInput{AuthorID: "author-1", Filename: "photo", Size: 50}},
+		{name: "大きさが 0", input: &CreateUploadInput{AuthorID: "author-1", Filename: "photo.png"}},
+		{name: "最大サイズ以上", input: &CreateUploadInput{AuthorID: "author-1", Filename: "photo.png", Size: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.CreateUploadSession(ctx, tt.input); !errors.Is(err, ErrInvalidUpload) {
+				t.Errorf("expected ErrInvalidUpload, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUploadSession_Expired(t *testing.T) {
+	ctx := context.Background()
+	s := newTestUploadService(t)
+
+	ticket, err := s.CreateUploadSession(ctx, &CreateUploadInput{AuthorID: "author-1", Filename: "photo.png", Size: 50})
+	if err != nil {
+		t.Fatalf("CreateUploadSession failed: %v", err)
+	}
+	s.now = func() time.Time { return ticket.Session.ExpiresAt }
+	if _, err := s.GetUploadSession(ctx, ticket.Session.ID); !errors.Is(err, ErrUploadExpired) {
+		t.Errorf("expected ErrUploadExpired, got %v", err)
+	}
+}
+
+func TestWriteUploadChunk(t *testing.T) {
+	ctx := context.Background()
+	s := newTestUploadService(t)
+
+	ticket, err := s.CreateUploadSession(ctx, &CreateUploadInput{AuthorID: "author-1", Filename: "photo.png", Size: 50})
+	if err != nil {
+		t.Fatalf("CreateUploadSession failed: %v", err)
+	}
+	session := ticket.Session
+
+	if received, err := s.WriteUploadChunk(ctx, session, 0, make([]byte, 60)); err != nil || received != 60 {
+		t.Fatalf("WriteUploadChunk failed: received=%d, err=%v", received, err)
+	}
+	// 合計が最大サイズに達する書き込みは受け付けない
+	if _, err := s.WriteUploadChunk(ctx, session, 60, make([]byte, 40)); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("expected ErrUploadTooLarge, got %v", err)
+	}
+	if _, err := s.WriteUploadChunk(ctx, session, -1, nil); !errors.Is(err, ErrInvalidUpload) {
+		t.Errorf("expected ErrInvalidUpload, got %v", err)
+	}
+	if received, err := s.WriteUploadChunk(ctx, session, 60, make([]byte, 39)); err != nil || received != 99 {
+		t.Fatalf("WriteUploadChunk failed: received=%d, err=%v", received, err)
+	}
+
+	reader, err := s.OpenUpload(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("OpenUpload failed: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if len(data) != 99 {
+		t.Errorf("expected 99 bytes, got %d", len(data))
+	}
+
+	if err := s.DeleteUploadSession(ctx, session.ID); err != nil {
+		t.Fatalf("DeleteUploadSession failed: %v", err)
+	}
+	if _, err := s.GetUploadSession(ctx, session.ID); !errors.Is(err, storage.ErrUploadNotFound) {
+		t.Errorf("expected ErrUploadNotFound, got %v", err)
+	}
+}
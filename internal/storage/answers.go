@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+const (
+	// answersPrefix は回答の記録を保存するオブジェクトの接頭辞です
+	answersPrefix = "metadata/answers/"
+	// statsPrefix はクイズごとの回答の集計を保存するオブジェクトの接頭辞です
+	statsPrefix = "metadata/stats/"
+	// playersPrefix はプレイヤーごとの最初の回答を記録するオブジェクトの接頭辞です
+	playersPrefix = "metadata/players/"
+	// playCountsPath は一覧に表示する回答数をまとめたオブジェクトのパスです
+	playCountsPath = "metadata/play_counts.json"
+)
+
+// playCounts はクイズごとの回答数を1つのオブジェクトにまとめたものです
+// 一覧の取得時に集計を1件ずつ読み込まずに済むよう、クイズごとの集計とは別に保持します
+type playCounts struct {
+	Counts map[string]int `json:"counts"`
+}
+
+// playerMarker はプレイヤーがクイズに最初に回答したことを表す記録です
+type playerMarker struct {
+	AnswerID   string    `json:"answer_id"`
+	AnsweredAt time.Time `json:"answered_at"`
+}
+
+// SaveAnswer は回答を記録し、クイズの集計を更新します
+// 回答は1件ずつ個別のオブジェクトとして保存するため、記録同士が競合することはありません
+// プレイヤーごとの最初の回答も別のオブジェクトに記録し、集計には件数だけを持たせます
+func (c *Client) SaveAnswer(ctx context.Context, answer *models.Answer) error {
+	if answer == nil {
+		return fmt.Errorf("回答データが必要です")
+	}
+	if err := validateQuizID(answer.QuizID); err != nil {
+		return err
+	}
+	logging.Info("回答の記録を開始: quizID=%s, id=%s", answer.QuizID, answer.ID)
+
+	if err := c.writeJSON(ctx, answerObjectPath(answer), answer); err != nil {
+		logging.Error("回答の書き込みに失敗: %v", err)
+		return fmt.Errorf("回答の保存に失敗: %w", err)
+	}
+
+	first, err := c.markFirstAnswer(ctx, answer)
+	if err != nil {
+		logging.Error("最初の回答の記録に失敗: %v", err)
+		return fmt.Errorf("集計の保存に失敗: %w", err)
+	}
+
+	_, err = updateJSON(ctx, c, statsObjectPath(answer.QuizID), func(stats *models.QuizStats) error {
+		stats.Record(answer, first)
+		return nil
+	})
+	if err != nil {
+		logging.Error("集計の更新に失敗: %v", err)
+		return fmt.Errorf("集計の保存に失敗: %w", err)
+	}
+
+	// 回答数は一覧表示用の複製のため、更新に失敗しても回答の記録は成功として扱う
+	// （ずれた場合は RebuildIndex で集計から作り直せます）
+	err = c.updatePlayCounts(ctx, func(counts map[string]int) { counts[answer.QuizID]++ })
+	if err != nil {
+		logging.Warn("回答数の更新に失敗: quizID=%s, err=%v", answer.QuizID, err)
+	}
+
+	logging.Info("回答の記録に成功: quizID=%s, id=%s", answer.QuizID, answer.ID)
+	return nil
+}
+
+// GetQuizStats はクイズの回答の集計を取得します
+// まだ回答がない場合は空の集計を返します
+func (c *Client) GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error) {
+	if err := validateQuizID(quizID); err != nil {
+		return nil, err
+	}
+
+	var stats models.QuizStats
+	if err := c.readJSON(ctx, statsObjectPath(quizID), &stats); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return &models.QuizStats{QuizID: quizID}, nil
+		}
+		logging.Error("集計の読み込みに失敗: %v", err)
+		return nil, fmt.Errorf("集計の読み込みに失敗: %w", err)
+	}
+	return &stats, nil
+}
+
+// markFirstAnswer はプレイヤーのクイズへの最初の回答を記録し、最初の回答だったかどうかを返します
+// まだ存在しない場合だけ書き込むため、同じプレイヤーの回答が同時に届いても最初の回答は1件に決まります
+func (c *Client) markFirstAnswer(ctx context.Context, answer *models.Answer) (bool, error) {
+	marker := playerMarker{AnswerID: answer.ID, AnsweredAt: answer.AnsweredAt}
+	err := c.writeJSONIf(ctx, playerMarkerPath(answer.QuizID, answer.PlayerID), marker, 0)
+	if errors.Is(err, ErrPreconditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updatePlayCounts はクイズごとの回答数を mutate で変更して保存します
+func (c *Client) updatePlayCounts(ctx context.Context, mutate func(counts map[string]int)) error {
+	_, err := updateJSON(ctx, c, playCountsPath, func(pc *playCounts) error {
+		if pc.Counts == nil {
+			pc.Counts = make(map[string]int)
+		}
+		mutate(pc.Counts)
+		return nil
+	})
+	return err
+}
+
+// loadPlayCounts はクイズごとの回答数を読み込みます
+func (c *Client) loadPlayCounts(ctx context.Context) (map[string]int, error) {
+	var pc playCounts
+	if err := c.readJSON(ctx, playCountsPath, &pc); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, err
+	}
+	return pc.Counts, nil
+}
+
+// answerObjectPath は回答を保存するオブジェクトのパスを返します
+func answerObjectPath(answer *models.Answer) string {
+	return fmt.Sprintf("%s%s/%s.json", answersPrefix, answer.QuizID, answer.ID)
+}
+
+// playerMarkerPath はプレイヤーの最初の回答を記録するオブジェクトのパスを返します
+// プレイヤーIDはパスに使える文字に限らないため、ハッシュ値をオブジェクト名にします
+func playerMarkerPath(quizID, playerID string) string {
+	sum := sha256.Sum256([]byte(playerID))
+	return fmt.Sprintf("%s%s/%s.json", playersPrefix, quizID, hex.EncodeToString(sum[:]))
+}
+
+// statsObjectPath はクイズの集計を保存するオブジェクトのパスを返します
+func statsObjectPath(quizID string) string {
+	return statsPrefix + quizID + ".json"
+}
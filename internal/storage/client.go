@@ -3,35 +3,87 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
 	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"google.golang.org/api/iterator"
 )
 
+// ErrQuizNotFound は指定されたクイズが存在しないことを表します
+var ErrQuizNotFound = errors.New("クイズが見つかりません")
+
+// ErrImageNotFound は指定された画像が存在しないことを表します
+var ErrImageNotFound = errors.New("画像が見つかりません")
+
+const (
+	// imagesPrefix は画像を保存するオブジェクトの接頭辞です
+	imagesPrefix = "images/"
+	// quizzesPrefix はクイズ本体を保存するオブジェクトの接頭辞です
+	quizzesPrefix = "metadata/quizzes/"
+	// quizIndexPath はクイズ一覧のインデックスを保存するオブジェクトです
+	quizIndexPath = "metadata/index.json"
+	// legacyQuizzesPath は全クイズを1ファイルに保存していた旧形式のオブジェクトです
+	legacyQuizzesPath = "metadata/quizzes.json"
+	// legacyQuizzesBackupPath は移行後の旧形式データの退避先です
+	legacyQuizzesBackupPath = "metadata/quizzes.migrated.json"
+)
+
+// imageExtensions は保存できる画像の MIME タイプと、オブジェクト名に付ける拡張子の対応です
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
 // StorageClient はストレージ操作のインターフェースを定義します
 type StorageClient interface {
-	SaveImage(ctx context.Context, imageData []byte) (string, error)
+	SaveImage(ctx context.Context, imageData []byte, contentType string) (string, error)
+	SaveImageVariant(ctx context.Context, imagePath string, variant models.ImageVariant, imageData []byte) (string, error)
+	DeleteImage(ctx context.Context, image models.QuizImage) error
 	SaveQuiz(ctx context.Context, quiz *models.Quiz) error
 	GetQuiz(ctx context.Context, quizID string) (*models.Quiz, error)
 	GenerateSignedURL(ctx context.Context, objectPath string) (string, error)
 	GetQuizzes(ctx context.Context) ([]*models.Quiz, error)
+	ListQuizzes(ctx context.Context, query *models.QuizListQuery) (*models.QuizPage, error)
 	DeleteAllQuizzes(ctx context.Context) error
+	GetImage(ctx context.Context, imagePath string) ([]byte, error)
+	StatImage(ctx context.Context, imagePath string) (*models.ImageInfo, error)
+	OpenImage(ctx context.Context, imagePath string, offset, length int64) (io.ReadCloser, error)
+	UpdateQuiz(ctx context.Context, quizID string, mutate func(quiz *models.Quiz) error) (*models.Quiz, error)
+	DeleteQuiz(ctx context.Context, quizID string) error
+	SaveAnswer(ctx context.Context, answer *models.Answer) error
+	GetQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error)
 }
 
 // BucketHandle はCloud Storage Bucketのインターフェース
 type BucketHandle interface {
 	Object(name string) ObjectHandle
 	SignedURL(name string, opts *storage.SignedURLOptions) (string, error)
+	// List は prefix で始まるオブジェクトの名前を返します
+	List(ctx context.Context, prefix string) ([]string, error)
 }
 
 // ObjectHandle はCloud Storage Objectのインターフェース
 type ObjectHandle interface {
 	NewWriter(ctx context.Context) io.WriteCloser
 	NewReader(ctx context.Context) (io.ReadCloser, error)
+	// NewRangeReader は offset バイト目から length バイトを読み出します（length が負の場合は最後まで）
+	NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context) error
+	Attrs(ctx context.Context) (*storage.ObjectAttrs, error)
+	If(conds storage.Conditions) ObjectHandle
+}
+
+// ContentTypeSetter は書き込むオブジェクトの Content-Type を指定できる Writer です
+// ObjectHandle.NewWriter が返す Writer が実装している場合、最初の書き込みの前に呼び出します
+type ContentTypeSetter interface {
+	SetContentType(contentType string)
 }
 
 // bucketHandleAdapter はCloud Storage BucketHandleのアダプター
@@ -52,22 +104,58 @@ func (b *bucketHandleAdapter) SignedURL(name string, opts *storage.SignedURLOpti
 	return b.bucket.SignedURL(name, opts)
 }
 
+func (b *bucketHandleAdapter) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	it := b.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+}
+
 func (o *objectHandleAdapter) NewWriter(ctx context.Context) io.WriteCloser {
-	return o.obj.NewWriter(ctx)
+	w := o.obj.NewWriter(ctx)
+	return &gcsWriter{w: w, attrs: &w.ObjectAttrs}
 }
 
 func (o *objectHandleAdapter) NewReader(ctx context.Context) (io.ReadCloser, error) {
 	return o.obj.NewReader(ctx)
 }
 
+func (o *objectHandleAdapter) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	return o.obj.NewRangeReader(ctx, offset, length)
+}
+
+func (o *objectHandleAdapter) Delete(ctx context.Context) error {
+	return translateError(o.obj.Delete(ctx))
+}
+
+func (o *objectHandleAdapter) Attrs(ctx context.Context) (*storage.ObjectAttrs, error) {
+	return o.obj.Attrs(ctx)
+}
+
+func (o *objectHandleAdapter) If(conds storage.Conditions) ObjectHandle {
+	return &objectHandleAdapter{obj: o.obj.If(conds)}
+}
+
 // Client はCloud Storageとの通信を担当します
 type Client struct {
 	bucket  BucketHandle
 	baseURL string
+	// signedURLTTL は署名付きURLの有効期間です（0 の場合は DefaultSignedURLTTL）
+	signedURLTTL time.Duration
+	// signingMethod は画像URLの発行方法です（空の場合は V4 署名）
+	signingMethod SigningMethod
 }
 
 // NewClient は新しいストレージクライアントを作成します
-func NewClient(ctx context.Context, bucketName string) (StorageClient, error) {
+func NewClient(ctx context.Context, bucketName string, opts ...ClientOption) (StorageClient, error) {
 	logging.Info("ストレージクライアントの初期化を開始: bucket=%s", bucketName)
 	client, err := storage.NewClient(ctx)
 	if err != nil {
@@ -79,33 +167,35 @@ func NewClient(ctx context.Context, bucketName string) (StorageClient, error) {
 	bucket := client.Bucket(bucketName)
 	baseURL := fmt.Sprintf("gs://%s", bucketName)
 
-	return &Client{
+	c := &Client{
 		bucket:  &bucketHandleAdapter{bucket: bucket},
 		baseURL: baseURL,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
 // SaveImage は画像をCloud Storageに保存します
-func (c *Client) SaveImage(ctx context.Context, imageData []byte) (string, error) {
-	logging.Info("画像の保存を開始: サイズ=%d bytes", len(imageData))
+// オブジェクト名の拡張子と Content-Type は contentType（image/jpeg または image/png）に合わせます
+func (c *Client) SaveImage(ctx context.Context, imageData []byte, contentType string) (string, error) {
+	logging.Info("画像の保存を開始: サイズ=%d bytes, 形式=%s", len(imageData), contentType)
 	if len(imageData) == 0 {
 		logging.Error("画像データが空です")
 		return "", fmt.Errorf("画像データが必要です")
 	}
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		logging.Error("対応していない画像形式です: %s", contentType)
+		return "", fmt.Errorf("対応していない画像形式です: %q", contentType)
+	}
 
-	imagePath := fmt.Sprintf("images/%s.jpg", generateID())
+	imagePath := imagesPrefix + generateID() + ext
 	logging.Debug("保存先パス: %s", imagePath)
 
-	obj := c.bucket.Object(imagePath)
-	writer := obj.NewWriter(ctx)
-
-	if _, err := writer.Write(imageData); err != nil {
-		logging.Error("画像データの書き込みに失敗: %v", err)
-		return "", fmt.Errorf("画像の書き込みに失敗: %w", err)
-	}
-
-	if err := writer.Close(); err != nil {
-		logging.Error("画像ファイルのクローズに失敗: %v", err)
+	if err := c.writeObject(ctx, imagePath, imageData, contentType); err != nil {
+		logging.Error("画像の保存に失敗: %v", err)
 		return "", fmt.Errorf("画像の保存に失敗: %w", err)
 	}
 
@@ -113,42 +203,70 @@ func (c *Client) SaveImage(ctx context.Context, imageData []byte) (string, error
 	return imagePath, nil
 }
 
+// SaveImageVariant は元の画像 imagePath から生成した派生画像を、元の画像と同じ場所に保存します
+func (c *Client) SaveImageVariant(ctx context.Context, imagePath string, variant models.ImageVariant, imageData []byte) (string, error) {
+	if err := validateImagePath(imagePath); err != nil {
+		return "", err
+	}
+	if variant == models.ImageFull || len(imageData) == 0 {
+		return "", fmt.Errorf("派生画像のデータが必要です: variant=%s", variant)
+	}
+
+	variantPath := models.VariantPath(imagePath, variant)
+	if err := c.writeObject(ctx, variantPath, imageData, "image/jpeg"); err != nil {
+		logging.Error("派生画像の保存に失敗: path=%s, err=%v", variantPath, err)
+		return "", fmt.Errorf("派生画像の保存に失敗: %w", err)
+	}
+
+	logging.Debug("派生画像の保存に成功: path=%s, サイズ=%d bytes", variantPath, len(imageData))
+	return variantPath, nil
+}
+
+// DeleteImage は元の画像と派生画像を削除します（存在しない画像は無視します）
+// クイズの保存に失敗した場合に、保存済みの画像を取り除くために使います
+func (c *Client) DeleteImage(ctx context.Context, image models.QuizImage) error {
+	if err := validateImagePath(image.Path); err != nil {
+		return err
+	}
+	logging.Info("画像の削除を開始: path=%s", image.Path)
+	return c.deleteObjects(ctx, imageObjectPaths(image))
+}
+
+// imageObjectPaths は元の画像と派生画像のオブジェクトのパスを返します
+// 元の画像を最後にすることで、途中で失敗しても再実行で派生画像のパスが分かるようにします
+func imageObjectPaths(image models.QuizImage) []string {
+	var paths []string
+	for _, variant := range image.Variants {
+		paths = append(paths, models.VariantPath(image.Path, variant))
+	}
+	return append(paths, image.Path)
+}
+
 // SaveQuiz はクイズデータをCloud Storageに保存します
 func (c *Client) SaveQuiz(ctx context.Context, quiz *models.Quiz) error {
-	logging.Info("クイズの保存を開始: id=%s", quiz.ID)
 	if quiz == nil {
 		logging.Error("クイズデータがnilです")
 		return fmt.Errorf("クイズデータが必要です")
 	}
-
-	quizzes, err := c.loadQuizzes(ctx)
-	if err != nil {
-		logging.Error("既存クイズデータの読み込みに失敗: %v", err)
-		return fmt.Errorf("クイズデータの読み込みに失敗: %w", err)
-	}
-	logging.Debug("既存クイズ数: %d", len(quizzes.Quizzes))
-
-	// 新しいクイズを追加
-	quizzes.Quizzes = append(quizzes.Quizzes, quiz)
-
-	// JSONに変換
-	data, err := json.Marshal(quizzes)
-	if err != nil {
-		logging.Error("クイズデータのJSON変換に失敗: %v", err)
-		return fmt.Errorf("JSONへの変換に失敗: %w", err)
+	logging.Info("クイズの保存を開始: id=%s", quiz.ID)
+	if err := validateQuizID(quiz.ID); err != nil {
+		return err
 	}
 
-	// ファイルに書き込み
-	obj := c.bucket.Object("metadata/quizzes.json")
-	writer := obj.NewWriter(ctx)
-	if _, err := writer.Write(data); err != nil {
+	// クイズ本体を個別のオブジェクトとして保存
+	if err := c.writeJSON(ctx, quizObjectPath(quiz.ID), quiz); err != nil {
 		logging.Error("クイズデータの書き込みに失敗: %v", err)
-		return fmt.Errorf("クイズデータの書き込みに失敗: %w", err)
+		return fmt.Errorf("クイズデータの保存に失敗: %w", err)
 	}
 
-	if err := writer.Close(); err != nil {
-		logging.Error("クイズファイルのクローズに失敗: %v", err)
-		return fmt.Errorf("クイズデータの保存に失敗: %w", err)
+	// インデックスに登録（同時アップロードで登録が失われないよう条件付きで更新）
+	_, err := updateJSON(ctx, c, quizIndexPath, func(index *models.QuizIndex) error {
+		upsertIndexEntry(index, quiz)
+		return nil
+	})
+	if err != nil {
+		logging.Error("インデックスの更新に失敗: %v", err)
+		return fmt.Errorf("インデックスの保存に失敗: %w", err)
 	}
 
 	logging.Info("クイズの保存に成功: id=%s", quiz.ID)
@@ -162,115 +280,410 @@ func (c *Client) GetQuiz(ctx context.Context, quizID string) (*models.Quiz, erro
 		logging.Error("クイズIDが空です")
 		return nil, fmt.Errorf("クイズIDが必要です")
 	}
+	if err := validateQuizID(quizID); err != nil {
+		return nil, err
+	}
 
-	quizzes, err := c.loadQuizzes(ctx)
-	if err != nil {
+	var quiz models.Quiz
+	if err := c.readJSON(ctx, quizObjectPath(quizID), &quiz); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			logging.Warn("クイズが見つかりません: id=%s", quizID)
+			return nil, fmt.Errorf("%w: %s", ErrQuizNotFound, quizID)
+		}
 		logging.Error("クイズデータの読み込みに失敗: %v", err)
 		return nil, fmt.Errorf("クイズデータの読み込みに失敗: %w", err)
 	}
 
-	for _, quiz := range quizzes.Quizzes {
-		if quiz.ID == quizID {
-			logging.Info("クイズの取得に成功: id=%s", quizID)
-			return quiz, nil
+	logging.Info("クイズの取得に成功: id=%s", quizID)
+	return &quiz, nil
+}
+
+// GetImage は保存された画像を読み込みます
+func (c *Client) GetImage(ctx context.Context, imagePath string) ([]byte, error) {
+	if err := validateImagePath(imagePath); err != nil {
+		return nil, err
+	}
+
+	reader, err := c.bucket.Object(imagePath).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrImageNotFound, imagePath)
+	}
+	if err != nil {
+		logging.Error("画像の読み込みに失敗: path=%s, err=%v", imagePath, err)
+		return nil, fmt.Errorf("画像の読み込みに失敗: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("画像の読み込みに失敗: %w", err)
+	}
+	return data, nil
+}
+
+// StatImage は画像オブジェクトの大きさや ETag を返します
+func (c *Client) StatImage(ctx context.Context, imagePath string) (*models.ImageInfo, error) {
+	if err := validateImagePath(imagePath); err != nil {
+		return nil, err
+	}
+
+	attrs, err := c.bucket.Object(imagePath).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrImageNotFound, imagePath)
+	}
+	if err != nil {
+		logging.Error("画像の属性の取得に失敗: path=%s, err=%v", imagePath, err)
+		return nil, fmt.Errorf("画像の属性の取得に失敗: %w", err)
+	}
+	return &models.ImageInfo{
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		// 画像は上書きしないが、同じ名前で保存し直された場合に備えて世代番号を含める
+		ETag:      fmt.Sprintf("%x-%x", attrs.Generation, attrs.Size),
+		UpdatedAt: attrs.Updated,
+	}, nil
+}
+
+// OpenImage は画像の offset バイト目から length バイトを読み出す Reader を返します（length が負の場合は最後まで）
+// 画像全体をメモリに読み込まずに配信するために使用します
+func (c *Client) OpenImage(ctx context.Context, imagePath string, offset, length int64) (io.ReadCloser, error) {
+	if err := validateImagePath(imagePath); err != nil {
+		return nil, err
+	}
+
+	reader, err := c.bucket.Object(imagePath).NewRangeReader(ctx, offset, length)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrImageNotFound, imagePath)
+	}
+	if err != nil {
+		logging.Error("画像の読み込みに失敗: path=%s, err=%v", imagePath, err)
+		return nil, fmt.Errorf("画像の読み込みに失敗: %w", err)
+	}
+	return reader, nil
+}
+
+// UpdateQuiz は保存済みのクイズを mutate で変更して保存します
+// 読み込みから書き込みまでの間に他の更新があった場合は、最新の内容で mutate をやり直します
+func (c *Client) UpdateQuiz(ctx context.Context, quizID string, mutate func(quiz *models.Quiz) error) (*models.Quiz, error) {
+	logging.Info("クイズの更新を開始: id=%s", quizID)
+	if err := validateQuizID(quizID); err != nil {
+		return nil, err
+	}
+
+	quiz, err := updateJSON(ctx, c, quizObjectPath(quizID), func(quiz *models.Quiz) error {
+		if quiz.ID == "" {
+			return fmt.Errorf("%w: %s", ErrQuizNotFound, quizID)
 		}
+		return mutate(quiz)
+	})
+	if err != nil {
+		logging.Error("クイズの更新に失敗: id=%s, err=%v", quizID, err)
+		return nil, err
+	}
+
+	// 一覧に表示する情報が変わった場合に備えてインデックスも更新
+	_, err = updateJSON(ctx, c, quizIndexPath, func(index *models.QuizIndex) error {
+		upsertIndexEntry(index, quiz)
+		return nil
+	})
+	if err != nil {
+		logging.Error("インデックスの更新に失敗: %v", err)
+		return nil, fmt.Errorf("インデックスの保存に失敗: %w", err)
 	}
 
-	logging.Warn("クイズが見つかりません: id=%s", quizID)
-	return nil, fmt.Errorf("クイズが見つかりません: %s", quizID)
+	logging.Info("クイズの更新に成功: id=%s", quizID)
+	return quiz, nil
 }
 
-// loadQuizzes はすべてのクイズデータを読み込みます
-func (c *Client) loadQuizzes(ctx context.Context) (*models.QuizList, error) {
-	logging.Debug("クイズデータの読み込みを開始")
-	obj := c.bucket.Object("metadata/quizzes.json")
-	reader, err := obj.NewReader(ctx)
+// DeleteQuiz はクイズと、その画像・回答の記録・集計を削除します
+func (c *Client) DeleteQuiz(ctx context.Context, quizID string) error {
+	logging.Info("クイズの削除を開始: id=%s", quizID)
+	quiz, err := c.GetQuiz(ctx, quizID)
+	if err != nil {
+		return err
+	}
+
+	// 先にインデックスから外して、一覧から見えなくする
+	_, err = updateJSON(ctx, c, quizIndexPath, func(index *models.QuizIndex) error {
+		removeIndexEntry(index, quizID)
+		return nil
+	})
 	if err != nil {
-		if err == storage.ErrObjectNotExist {
-			logging.Info("クイズデータファイルが存在しないため、新規作成します")
-			return &models.QuizList{}, nil
+		logging.Error("インデックスの更新に失敗: %v", err)
+		return fmt.Errorf("インデックスの保存に失敗: %w", err)
+	}
+
+	// 途中で失敗しても再実行で画像のパスが分かるよう、クイズ本体は最後に削除する
+	var paths []string
+	if quiz.ImagePath != "" {
+		paths = imageObjectPaths(quiz.Image())
+	}
+	for _, prefix := range []string{answersPrefix, playersPrefix} {
+		answers, err := c.bucket.List(ctx, prefix+quizID+"/")
+		if err != nil {
+			logging.Error("回答の一覧の取得に失敗: %v", err)
+			return fmt.Errorf("回答の一覧の取得に失敗: %w", err)
 		}
-		logging.Error("クイズデータファイルの読み込みに失敗: %v", err)
-		return nil, fmt.Errorf("クイズデータの読み込みに失敗: %w", err)
+		paths = append(paths, answers...)
+	}
+	paths = append(paths, statsObjectPath(quizID), quizObjectPath(quizID))
+	if err := c.deleteObjects(ctx, paths); err != nil {
+		return err
+	}
+	err = c.updatePlayCounts(ctx, func(counts map[string]int) { delete(counts, quizID) })
+	if err != nil {
+		logging.Warn("回答数の削除に失敗: id=%s, err=%v", quizID, err)
+	}
+
+	logging.Info("クイズの削除に成功: id=%s", quizID)
+	return nil
+}
+
+// deleteObjects はオブジェクトを順に削除します（存在しないオブジェクトは無視します）
+func (c *Client) deleteObjects(ctx context.Context, paths []string) error {
+	for _, path := range paths {
+		if err := c.bucket.Object(path).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			logging.Error("オブジェクトの削除に失敗: path=%s, err=%v", path, err)
+			return fmt.Errorf("オブジェクトの削除に失敗: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadIndex はクイズ一覧のインデックスを読み込みます
+func (c *Client) loadIndex(ctx context.Context) (*models.QuizIndex, error) {
+	logging.Debug("インデックスの読み込みを開始")
+	var index models.QuizIndex
+	if err := c.readJSON(ctx, quizIndexPath, &index); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			logging.Info("インデックスが存在しないため、新規作成します")
+			return &models.QuizIndex{}, nil
+		}
+		return nil, err
+	}
+	logging.Debug("インデックスの読み込みに成功: クイズ数=%d", len(index.Entries))
+	return &index, nil
+}
+
+// readJSON はオブジェクトを読み込み、JSONとしてデコードします
+func (c *Client) readJSON(ctx context.Context, objectPath string, v interface{}) error {
+	reader, err := c.bucket.Object(objectPath).NewReader(ctx)
+	if err != nil {
+		return err
 	}
 	defer reader.Close()
 
 	data, err := io.ReadAll(reader)
 	if err != nil {
-		logging.Error("クイズデータの読み取りに失敗: %v", err)
-		return nil, fmt.Errorf("データの読み込みに失敗: %w", err)
+		return fmt.Errorf("データの読み込みに失敗: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("JSONのパースに失敗: %w", err)
 	}
+	return nil
+}
 
-	var quizzes models.QuizList
-	if err := json.Unmarshal(data, &quizzes); err != nil {
-		logging.Error("クイズデータのJSONパースに失敗: %v", err)
-		return nil, fmt.Errorf("JSONのパースに失敗: %w", err)
+// writeObject はデータをそのままオブジェクトに書き込みます
+func (c *Client) writeObject(ctx context.Context, objectPath string, data []byte, contentType string) error {
+	writer := c.bucket.Object(objectPath).NewWriter(ctx)
+	if setter, ok := writer.(ContentTypeSetter); ok {
+		setter.SetContentType(contentType)
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("書き込みに失敗: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("保存に失敗: %w", err)
 	}
+	return nil
+}
 
-	logging.Debug("クイズデータの読み込みに成功: クイズ数=%d", len(quizzes.Quizzes))
-	return &quizzes, nil
+// writeJSON は値をJSONに変換してオブジェクトに書き込みます
+func (c *Client) writeJSON(ctx context.Context, objectPath string, v interface{}) error {
+	return writeJSONTo(ctx, c.bucket.Object(objectPath), v)
 }
 
-// generateID は一意のIDを生成します
-func generateID() string {
-	return fmt.Sprintf("quiz_%d", time.Now().UnixNano())
+// writeJSONTo は値をJSONに変換して指定のオブジェクトに書き込みます
+func writeJSONTo(ctx context.Context, obj ObjectHandle, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("JSONへの変換に失敗: %w", err)
+	}
+
+	writer := obj.NewWriter(ctx)
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("書き込みに失敗: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("保存に失敗: %w", err)
+	}
+	return nil
+}
+
+// upsertIndexEntry はインデックスにクイズを登録します（既存の場合は更新します）
+func upsertIndexEntry(index *models.QuizIndex, quiz *models.Quiz) {
+	entry := models.NewQuizIndexEntry(quiz)
+	for i, e := range index.Entries {
+		if e.ID == quiz.ID {
+			index.Entries[i] = entry
+			return
+		}
+	}
+	index.Entries = append(index.Entries, entry)
+}
+
+// removeIndexEntry はインデックスからクイズを取り除きます
+func removeIndexEntry(index *models.QuizIndex, quizID string) {
+	for i, e := range index.Entries {
+		if e.ID == quizID {
+			index.Entries = append(index.Entries[:i], index.Entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// quizObjectPath はクイズ本体を保存するオブジェクトのパスを返します
+func quizObjectPath(quizID string) string {
+	return quizzesPrefix + quizID + ".json"
+}
+
+// validateQuizID はオブジェクトパスに使用できるクイズIDかを検証します
+func validateQuizID(quizID string) error {
+	if quizID == "" || strings.ContainsAny(quizID, "/\\") || strings.Contains(quizID, "..") {
+		return fmt.Errorf("不正なクイズIDです: %q", quizID)
+	}
+	return nil
 }
 
-// GenerateSignedURL は、指定されたオブジェクトの公開URLを生成します。
-func (c *Client) GenerateSignedURL(ctx context.Context, objectPath string) (string, error) {
-	if objectPath == "" {
-		return "", fmt.Errorf("object path is empty")
+// validateImagePath は画像を保存する場所の中を指すパスかを検証します
+func validateImagePath(imagePath string) error {
+	if !strings.HasPrefix(imagePath, imagesPrefix) || strings.Contains(imagePath, "..") {
+		return fmt.Errorf("不正な画像パスです: %q", imagePath)
 	}
+	return nil
+}
 
-	// パブリックアクセス用のURLを生成
-	publicURL := fmt.Sprintf("https://storage.googleapis.com/zenn-ai-hackathon-2501-bucket/%s", objectPath)
-	logging.Info("Generated public URL: %s", publicURL)
-	return publicURL, nil
+// generateID は一意のIDを生成します
+func generateID() string {
+	return fmt.Sprintf("quiz_%d", time.Now().UnixNano())
 }
 
 // GetQuizzes はすべてのクイズを取得します
 func (c *Client) GetQuizzes(ctx context.Context) ([]*models.Quiz, error) {
 	logging.Info("クイズ一覧の取得を開始")
-	quizzes, err := c.loadQuizzes(ctx)
+	index, err := c.loadIndex(ctx)
 	if err != nil {
-		logging.Error("クイズデータの読み込みに失敗: %v", err)
+		logging.Error("インデックスの読み込みに失敗: %v", err)
 		return nil, fmt.Errorf("クイズデータの読み込みに失敗: %w", err)
 	}
-	logging.Info("クイズ一覧の取得に成功: クイズ数=%d", len(quizzes.Quizzes))
-	return quizzes.Quizzes, nil
+
+	quizzes := make([]*models.Quiz, 0, len(index.Entries))
+	for _, entry := range index.Entries {
+		quiz, err := c.GetQuiz(ctx, entry.ID)
+		if err != nil {
+			// インデックスと本体の不整合は一覧全体を失敗させない
+			logging.Warn("インデックスに登録されたクイズを読み込めません: id=%s, err=%v", entry.ID, err)
+			continue
+		}
+		quizzes = append(quizzes, quiz)
+	}
+
+	logging.Info("クイズ一覧の取得に成功: クイズ数=%d", len(quizzes))
+	return quizzes, nil
 }
 
 // DeleteAllQuizzes は全てのクイズを削除します
+// 作成中のジョブとアップロード中の画像も削除します（残すと、削除の後にジョブからクイズが作成されるため）
+// 削除したジョブを実行中のワーカーは、ジョブや画像が見つからずに失敗します
 func (c *Client) DeleteAllQuizzes(ctx context.Context) error {
 	logging.Info("全クイズの削除を開始")
 
-	// 空のクイズリストを作成
-	emptyQuizzes := struct {
-		Quizzes []*models.Quiz `json:"quizzes"`
-	}{
-		Quizzes: []*models.Quiz{},
+	// 先にインデックスを空にして、一覧から見えなくする
+	var deleted []*models.QuizIndexEntry
+	_, err := updateJSON(ctx, c, quizIndexPath, func(index *models.QuizIndex) error {
+		deleted = index.Entries
+		index.Entries = []*models.QuizIndexEntry{}
+		return nil
+	})
+	if err != nil {
+		logging.Error("インデックスの更新に失敗: %v", err)
+		return fmt.Errorf("インデックスの保存に失敗: %w", err)
 	}
 
-	// JSONに変換
-	data, err := json.Marshal(emptyQuizzes)
-	if err != nil {
-		logging.Error("空のクイズリストのJSON変換に失敗: %v", err)
-		return fmt.Errorf("JSONへの変換に失敗: %w", err)
+	// インデックスに載っていない取り残されたオブジェクトも含めて削除する
+	prefixes := []string{
+		imagesPrefix, answersPrefix, playersPrefix, statsPrefix, quizzesPrefix,
+		jobsPrefix, jobMetadataPrefix, uploadsPrefix, uploadSessionsPrefix,
+	}
+	for _, prefix := range prefixes {
+		paths, err := c.bucket.List(ctx, prefix)
+		if err != nil {
+			logging.Error("オブジェクトの一覧の取得に失敗: prefix=%s, err=%v", prefix, err)
+			return fmt.Errorf("オブジェクトの一覧の取得に失敗: %w", err)
+		}
+		if err := c.deleteObjects(ctx, paths); err != nil {
+			return err
+		}
+	}
+	if err := c.deleteObjects(ctx, []string{playCountsPath}); err != nil {
+		return err
 	}
 
-	// ファイルに書き込み
-	obj := c.bucket.Object("metadata/quizzes.json")
-	writer := obj.NewWriter(ctx)
-	if _, err := writer.Write(data); err != nil {
-		logging.Error("クイズデータの書き込みに失敗: %v", err)
-		return fmt.Errorf("クイズデータの書き込みに失敗: %w", err)
+	logging.Info("全クイズの削除に成功: 削除数=%d", len(deleted))
+	return nil
+}
+
+// MigrateLegacyQuizzes は単一ファイル形式の metadata/quizzes.json をクイズごとのオブジェクトに分割します
+// 移行済み、または旧形式のファイルが存在しない場合は何もしません
+func (c *Client) MigrateLegacyQuizzes(ctx context.Context) (int, error) {
+	logging.Info("旧形式クイズデータの移行を開始")
+
+	var legacy models.QuizList
+	if err := c.readJSON(ctx, legacyQuizzesPath, &legacy); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			logging.Info("旧形式のクイズデータは存在しません")
+			return 0, nil
+		}
+		logging.Error("旧形式クイズデータの読み込みに失敗: %v", err)
+		return 0, fmt.Errorf("旧形式クイズデータの読み込みに失敗: %w", err)
 	}
 
-	if err := writer.Close(); err != nil {
-		logging.Error("クイズファイルのクローズに失敗: %v", err)
-		return fmt.Errorf("クイズデータの保存に失敗: %w", err)
+	var quizzes []*models.Quiz
+	for _, quiz := range legacy.Quizzes {
+		if quiz == nil || validateQuizID(quiz.ID) != nil {
+			logging.Warn("移行できないクイズをスキップします: %+v", quiz)
+			continue
+		}
+		if err := c.writeJSON(ctx, quizObjectPath(quiz.ID), quiz); err != nil {
+			return 0, fmt.Errorf("クイズの移行に失敗: id=%s: %w", quiz.ID, err)
+		}
+		quizzes = append(quizzes, quiz)
 	}
 
-	logging.Info("全クイズの削除に成功")
-	return nil
+	_, err := updateJSON(ctx, c, quizIndexPath, func(index *models.QuizIndex) error {
+		for _, quiz := range quizzes {
+			upsertIndexEntry(index, quiz)
+		}
+		sort.SliceStable(index.Entries, func(i, j int) bool {
+			return index.Entries[i].CreatedAt.Before(index.Entries[j].CreatedAt)
+		})
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("インデックスの保存に失敗: %w", err)
+	}
+	migrated := len(quizzes)
+
+	// 旧形式のファイルは退避してから削除し、再実行時に二重移行しないようにする
+	if err := c.writeJSON(ctx, legacyQuizzesBackupPath, &legacy); err != nil {
+		return migrated, fmt.Errorf("旧形式クイズデータの退避に失敗: %w", err)
+	}
+	if err := c.bucket.Object(legacyQuizzesPath).Delete(ctx); err != nil {
+		return migrated, fmt.Errorf("旧形式クイズデータの削除に失敗: %w", err)
+	}
+
+	logging.Info("旧形式クイズデータの移行に成功: 移行数=%d", migrated)
+	return migrated, nil
 }
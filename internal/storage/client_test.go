@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"cloud.google.com/go/storage"
@@ -40,6 +41,14 @@ func (m *MockBucketHandle) SignedURL(name string, opts *storage.SignedURLOptions
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockBucketHandle) List(ctx context.Context, prefix string) ([]string, error) {
+	args := m.Called(ctx, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 type MockObjectHandle struct {
 	mock.Mock
 }
@@ -51,37 +60,153 @@ func (m *MockObjectHandle) NewWriter(ctx context.Context) io.WriteCloser {
 
 func (m *MockObjectHandle) NewReader(ctx context.Context) (io.ReadCloser, error) {
 	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockObjectHandle) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, offset, length)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(io.ReadCloser), args.Error(1)
 }
 
+func (m *MockObjectHandle) Delete(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockObjectHandle) Attrs(ctx context.Context) (*storage.ObjectAttrs, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.ObjectAttrs), args.Error(1)
+}
+
+func (m *MockObjectHandle) If(conds storage.Conditions) ObjectHandle {
+	args := m.Called(conds)
+	return args.Get(0).(ObjectHandle)
+}
+
 func TestDeleteAllQuizzes(t *testing.T) {
+	indexJSON := `{"entries":[{"id":"quiz-1","created_at":"2024-03-20T10:00:00Z"}]}`
+
 	// テストケースの定義
 	tests := []struct {
 		name    string
-		setup   func(*MockBucketHandle, *MockObjectHandle)
+		setup   func(*MockBucketHandle)
 		wantErr bool
 	}{
 		{
 			name: "正常系：全クイズの削除に成功",
-			setup: func(mb *MockBucketHandle, mo *MockObjectHandle) {
+			setup: func(mb *MockBucketHandle) {
 				writer := &MockWriteCloser{}
 				writer.On("Write", mock.Anything).Return(10, nil)
 				writer.On("Close").Return(nil)
 
-				mo.On("NewWriter", mock.Anything).Return(writer)
-				mb.On("Object", "metadata/quizzes.json").Return(mo)
+				index := &MockObjectHandle{}
+				index.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Generation: 7}, nil)
+				index.On("NewReader", mock.Anything).Return(io.NopCloser(strings.NewReader(indexJSON)), nil)
+				index.On("If", storage.Conditions{GenerationMatch: 7}).Return(index)
+				index.On("NewWriter", mock.Anything).Return(writer)
+				mb.On("Object", "metadata/index.json").Return(index)
+
+				mb.On("List", mock.Anything, "images/").Return([]string{"images/quiz_1.jpg"}, nil)
+				mb.On("List", mock.Anything, "metadata/answers/").Return([]string{}, nil)
+				mb.On("List", mock.Anything, "metadata/players/").Return([]string{"metadata/players/quiz-1/p1.json"}, nil)
+				mb.On("List", mock.Anything, "metadata/stats/").Return([]string{"metadata/stats/quiz-1.json"}, nil)
+				mb.On("List", mock.Anything, "metadata/quizzes/").Return([]string{"metadata/quizzes/quiz-1.json"}, nil)
+				mb.On("List", mock.Anything, "jobs/").Return([]string{}, nil)
+				mb.On("List", mock.Anything, "metadata/jobs/").Return([]string{"metadata/jobs/job_1.json"}, nil)
+				mb.On("List", mock.Anything, "uploads/").Return([]string{}, nil)
+				mb.On("List", mock.Anything, "metadata/uploads/").Return([]string{}, nil)
+
+				job := &MockObjectHandle{}
+				job.On("Delete", mock.Anything).Return(nil)
+				mb.On("Object", "metadata/jobs/job_1.json").Return(job)
+
+				image := &MockObjectHandle{}
+				image.On("Delete", mock.Anything).Return(nil)
+				mb.On("Object", "images/quiz_1.jpg").Return(image)
+
+				quiz := &MockObjectHandle{}
+				quiz.On("Delete", mock.Anything).Return(nil)
+				mb.On("Object", "metadata/quizzes/quiz-1.json").Return(quiz)
+
+				stats := &MockObjectHandle{}
+				stats.On("Delete", mock.Anything).Return(storage.ErrObjectNotExist)
+				mb.On("Object", "metadata/stats/quiz-1.json").Return(stats)
+
+				player := &MockObjectHandle{}
+				player.On("Delete", mock.Anything).Return(nil)
+				mb.On("Object", "metadata/players/quiz-1/p1.json").Return(player)
+
+				playCounts := &MockObjectHandle{}
+				playCounts.On("Delete", mock.Anything).Return(nil)
+				mb.On("Object", "metadata/play_counts.json").Return(playCounts)
+			},
+			wantErr: false,
+		},
+		{
+			name: "正常系：インデックスが存在しない",
+			setup: func(mb *MockBucketHandle) {
+				writer := &MockWriteCloser{}
+				writer.On("Write", mock.Anything).Return(10, nil)
+				writer.On("Close").Return(nil)
+
+				index := &MockObjectHandle{}
+				index.On("Attrs", mock.Anything).Return(nil, storage.ErrObjectNotExist)
+				index.On("If", storage.Conditions{DoesNotExist: true}).Return(index)
+				index.On("NewWriter", mock.Anything).Return(writer)
+				mb.On("Object", "metadata/index.json").Return(index)
+				mb.On("List", mock.Anything, mock.Anything).Return([]string{}, nil)
+
+				playCounts := &MockObjectHandle{}
+				playCounts.On("Delete", mock.Anything).Return(storage.ErrObjectNotExist)
+				mb.On("Object", "metadata/play_counts.json").Return(playCounts)
 			},
 			wantErr: false,
 		},
 		{
 			name: "異常系：書き込みエラー",
-			setup: func(mb *MockBucketHandle, mo *MockObjectHandle) {
+			setup: func(mb *MockBucketHandle) {
 				writer := &MockWriteCloser{}
 				writer.On("Write", mock.Anything).Return(0, fmt.Errorf("write error"))
 				writer.On("Close").Return(nil)
 
-				mo.On("NewWriter", mock.Anything).Return(writer)
-				mb.On("Object", "metadata/quizzes.json").Return(mo)
+				index := &MockObjectHandle{}
+				index.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Generation: 7}, nil)
+				index.On("NewReader", mock.Anything).Return(io.NopCloser(strings.NewReader(indexJSON)), nil)
+				index.On("If", storage.Conditions{GenerationMatch: 7}).Return(index)
+				index.On("NewWriter", mock.Anything).Return(writer)
+				mb.On("Object", "metadata/index.json").Return(index)
+			},
+			wantErr: true,
+		},
+		{
+			name: "異常系：クイズ本体の削除エラー",
+			setup: func(mb *MockBucketHandle) {
+				writer := &MockWriteCloser{}
+				writer.On("Write", mock.Anything).Return(10, nil)
+				writer.On("Close").Return(nil)
+
+				index := &MockObjectHandle{}
+				index.On("Attrs", mock.Anything).Return(&storage.ObjectAttrs{Generation: 7}, nil)
+				index.On("NewReader", mock.Anything).Return(io.NopCloser(strings.NewReader(indexJSON)), nil)
+				index.On("If", storage.Conditions{GenerationMatch: 7}).Return(index)
+				index.On("NewWriter", mock.Anything).Return(writer)
+				mb.On("Object", "metadata/index.json").Return(index)
+
+				mb.On("List", mock.Anything, "metadata/quizzes/").Return([]string{"metadata/quizzes/quiz-1.json"}, nil)
+				mb.On("List", mock.Anything, mock.Anything).Return([]string{}, nil)
+
+				quiz := &MockObjectHandle{}
+				quiz.On("Delete", mock.Anything).Return(fmt.Errorf("delete error"))
+				mb.On("Object", "metadata/quizzes/quiz-1.json").Return(quiz)
 			},
 			wantErr: true,
 		},
@@ -91,8 +216,7 @@ func TestDeleteAllQuizzes(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockBucket := &MockBucketHandle{}
-			mockObject := &MockObjectHandle{}
-			tt.setup(mockBucket, mockObject)
+			tt.setup(mockBucket)
 
 			client := &Client{
 				bucket: mockBucket,
@@ -104,6 +228,7 @@ func TestDeleteAllQuizzes(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 			}
+			mockBucket.AssertExpectations(t)
 		})
 	}
 }
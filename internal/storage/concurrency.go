@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrPreconditionFailed は条件付き書き込みの前提（世代番号）が満たされなかったことを表します
+// 読み込み後に他のリクエストがオブジェクトを更新した場合に返されます
+var ErrPreconditionFailed = errors.New("storage: precondition failed")
+
+// maxUpdateAttempts は競合時に読み込みからやり直す最大回数です
+const maxUpdateAttempts = 50
+
+var (
+	// updateBackoffBase は競合時の待機時間の基準値です
+	updateBackoffBase = 2 * time.Millisecond
+	// updateBackoffMax は競合時の待機時間の上限です
+	updateBackoffMax = 100 * time.Millisecond
+)
+
+// updateJSON はJSONオブジェクトを「読み込み → 変更 → 世代番号を条件に書き込み」で更新します
+// 他の書き込みと競合した場合は、最新の内容を読み直して mutate をやり直します
+func updateJSON[T any](ctx context.Context, c *Client, objectPath string, mutate func(v *T) error) (*T, error) {
+	for attempt := 1; ; attempt++ {
+		var v T
+		generation, err := c.readJSONWithGeneration(ctx, objectPath, &v)
+		if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, err
+		}
+
+		if err := mutate(&v); err != nil {
+			return nil, err
+		}
+
+		err = c.writeJSONIf(ctx, objectPath, &v, generation)
+		if err == nil {
+			return &v, nil
+		}
+		if !errors.Is(err, ErrPreconditionFailed) {
+			return nil, err
+		}
+		if attempt >= maxUpdateAttempts {
+			logging.Error("更新の競合が解消しません: path=%s, 試行回数=%d", objectPath, attempt)
+			return nil, fmt.Errorf("更新の競合が解消しません: %w", err)
+		}
+
+		logging.Debug("更新が競合したため再試行します: path=%s, 試行回数=%d", objectPath, attempt)
+		if err := waitBackoff(ctx, attempt); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readJSONWithGeneration はオブジェクトを読み込み、読み込んだ時点の世代番号を返します
+// オブジェクトが存在しない場合は世代番号 0 と storage.ErrObjectNotExist を返します
+func (c *Client) readJSONWithGeneration(ctx context.Context, objectPath string, v interface{}) (int64, error) {
+	attrs, err := c.bucket.Object(objectPath).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("属性の取得に失敗: %w", err)
+	}
+
+	// 属性取得後に更新された場合でも、書き込み時の世代番号チェックで検出されます
+	if err := c.readJSON(ctx, objectPath, v); err != nil {
+		return attrs.Generation, err
+	}
+	return attrs.Generation, nil
+}
+
+// writeJSONIf はオブジェクトの世代番号が generation と一致する場合のみ書き込みます
+// generation が 0 の場合は、オブジェクトが存在しないことを条件とします
+func (c *Client) writeJSONIf(ctx context.Context, objectPath string, v interface{}, generation int64) error {
+	conds := storage.Conditions{GenerationMatch: generation}
+	if generation == 0 {
+		conds = storage.Conditions{DoesNotExist: true}
+	}
+	return writeJSONTo(ctx, c.bucket.Object(objectPath).If(conds), v)
+}
+
+// waitBackoff は試行回数に応じたランダムな時間だけ待機します
+func waitBackoff(ctx context.Context, attempt int) error {
+	backoff := updateBackoffBase << uint(min(attempt, 6))
+	if backoff > updateBackoffMax {
+		backoff = updateBackoffMax
+	}
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff)) + 1))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// gcsWriter はCloud Storageのエラーをパッケージのエラーに変換するWriterです
+type gcsWriter struct {
+	w io.WriteCloser
+	// attrs は書き込むオブジェクトの属性です（Cloud Storage の Writer のもの）
+	attrs *storage.ObjectAttrs
+}
+
+// SetContentType は書き込むオブジェクトの Content-Type を指定します
+func (w *gcsWriter) SetContentType(contentType string) {
+	if w.attrs != nil {
+		w.attrs.ContentType = contentType
+	}
+}
+
+func (w *gcsWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	return n, translateError(err)
+}
+
+func (w *gcsWriter) Close() error {
+	return translateError(w.w.Close())
+}
+
+// translateError は前提条件の失敗（HTTP 412）を ErrPreconditionFailed に変換します
+func translateError(err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+		return fmt.Errorf("%w: %v", ErrPreconditionFailed, err)
+	}
+	return err
+}
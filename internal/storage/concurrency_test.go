@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+	"google.golang.org/api/googleapi"
+)
+
+// conflictingBucket は最初の n 回の条件付き書き込みを他者の更新と競合させるバケットです
+type conflictingBucket struct {
+	*MockBucket
+	conflicts int
+}
+
+func (b *conflictingBucket) Object(name string) ObjectHandle {
+	return &conflictingObject{MockObject: b.MockBucket.Object(name).(*MockObject), bucket: b}
+}
+
+type conflictingObject struct {
+	*MockObject
+	bucket *conflictingBucket
+}
+
+func (o *conflictingObject) If(conds storage.Conditions) ObjectHandle {
+	o.bucket.mu.Lock()
+	if o.bucket.conflicts > 0 {
+		o.bucket.conflicts--
+		// 読み込みから書き込みまでの間に他のリクエストが更新したことを再現
+		data, ok := o.bucket.objects[o.name]
+		if !ok {
+			data = []byte(`{"entries":[]}`)
+		}
+		o.bucket.put(o.name, data)
+	}
+	o.bucket.mu.Unlock()
+	return o.MockObject.If(conds)
+}
+
+func TestSaveQuiz_RetriesOnConflict(t *testing.T) {
+	bucket := &conflictingBucket{MockBucket: NewMockBucket(), conflicts: 3}
+	client := &Client{bucket: bucket}
+	ctx := context.Background()
+
+	if err := client.SaveQuiz(ctx, &models.Quiz{ID: "quiz_1", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveQuiz failed: %v", err)
+	}
+	if bucket.conflicts != 0 {
+		t.Errorf("expected all conflicts to be consumed, %d left", bucket.conflicts)
+	}
+
+	quizzes, err := client.GetQuizzes(ctx)
+	if err != nil {
+		t.Fatalf("GetQuizzes failed: %v", err)
+	}
+	if len(quizzes) != 1 {
+		t.Errorf("expected 1 quiz, got %d", len(quizzes))
+	}
+}
+
+func TestSaveQuiz_GivesUpAfterMaxAttempts(t *testing.T) {
+	base, maxBackoff := updateBackoffBase, updateBackoffMax
+	updateBackoffBase, updateBackoffMax = time.Microsecond, time.Microsecond
+	t.Cleanup(func() { updateBackoffBase, updateBackoffMax = base, maxBackoff })
+
+	bucket := &conflictingBucket{MockBucket: NewMockBucket(), conflicts: maxUpdateAttempts}
+	client := &Client{bucket: bucket}
+
+	err := client.SaveQuiz(context.Background(), &models.Quiz{ID: "quiz_1", CreatedAt: time.Now()})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+}
+
+func TestSaveQuiz_ConcurrentWritesAreNotLost(t *testing.T) {
+	const writers = 30
+
+	clients := map[string]func(t *testing.T) StorageClient{
+		"Cloud Storage": func(t *testing.T) StorageClient {
+			return &Client{bucket: NewMockBucket()}
+		},
+		"ローカル": func(t *testing.T) StorageClient {
+			client, err := NewLocalClient(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLocalClient failed: %v", err)
+			}
+			return client
+		},
+	}
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t)
+			ctx := context.Background()
+
+			var wg sync.WaitGroup
+			errs := make(chan error, writers)
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					quiz := &models.Quiz{ID: fmt.Sprintf("quiz_%d", i), CreatedAt: time.Now()}
+					if err := client.SaveQuiz(ctx, quiz); err != nil {
+						errs <- err
+					}
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Errorf("SaveQuiz failed: %v", err)
+			}
+
+			quizzes, err := client.GetQuizzes(ctx)
+			if err != nil {
+				t.Fatalf("GetQuizzes failed: %v", err)
+			}
+			if len(quizzes) != writers {
+				t.Errorf("expected %d quizzes, got %d", writers, len(quizzes))
+			}
+		})
+	}
+}
+
+func TestTranslateError(t *testing.T) {
+	err := translateError(&googleapi.Error{Code: http.StatusPreconditionFailed})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+
+	other := &googleapi.Error{Code: http.StatusInternalServerError}
+	if err := translateError(other); errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("unexpected ErrPreconditionFailed for %v", other)
+	}
+
+	if err := translateError(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+const (
+	// jobsPrefix はジョブの画像を置くオブジェクトの接頭辞です
+	jobsPrefix = "jobs/"
+	// jobMetadataPrefix はジョブを保存するオブジェクトの接頭辞です
+	jobMetadataPrefix = "metadata/jobs/"
+)
+
+// ErrJobNotFound は指定されたジョブが存在しないことを表します
+var ErrJobNotFound = errors.New("ジョブが見つかりません")
+
+// JobStore はクイズ作成ジョブのストレージ操作のインターフェースを定義します
+// Client と LocalClient が実装します
+type JobStore interface {
+	SaveJob(ctx context.Context, job *models.Job, imageData []byte) error
+	GetJob(ctx context.Context, jobID string) (*models.Job, error)
+	UpdateJob(ctx context.Context, jobID string, mutate func(job *models.Job) error) (*models.Job, error)
+	ListJobs(ctx context.Context) ([]*models.Job, error)
+	GetJobImage(ctx context.Context, jobID string) ([]byte, error)
+	DeleteJob(ctx context.Context, jobID string) error
+	DeleteJobImage(ctx context.Context, jobID string) error
+}
+
+// SaveJob はジョブと、ジョブで使う画像を保存します
+// 画像を先に保存するため、保存済みのジョブの画像は常に読み込めます
+func (c *Client) SaveJob(ctx context.Context, job *models.Job, imageData []byte) error {
+	if job == nil {
+		return fmt.Errorf("ジョブが必要です")
+	}
+	if err := validateObjectID(job.ID); err != nil {
+		return err
+	}
+	if err := c.writeObject(ctx, jobImagePath(job.ID), imageData, job.Input.ImageMimeType); err != nil {
+		logging.Error("ジョブの画像の保存に失敗: id=%s, err=%v", job.ID, err)
+		return fmt.Errorf("ジョブの画像の保存に失敗: %w", err)
+	}
+	if err := c.writeJSON(ctx, jobMetadataPath(job.ID), job); err != nil {
+		logging.Error("ジョブの保存に失敗: id=%s, err=%v", job.ID, err)
+		return fmt.Errorf("ジョブの保存に失敗: %w", err)
+	}
+	return nil
+}
+
+// GetJob はジョブを取得します
+func (c *Client) GetJob(ctx context.Context, jobID string) (*models.Job, error) {
+	if err := validateObjectID(jobID); err != nil {
+		return nil, err
+	}
+	var job models.Job
+	if err := c.readJSON(ctx, jobMetadataPath(jobID), &job); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+		}
+		return nil, fmt.Errorf("ジョブの読み込みに失敗: %w", err)
+	}
+	return &job, nil
+}
+
+// UpdateJob はジョブを読み込んで mutate で変更し、保存します
+// 他の更新と競合した場合は最新の内容で mutate をやり直します
+func (c *Client) UpdateJob(ctx context.Context, jobID string, mutate func(job *models.Job) error) (*models.Job, error) {
+	if err := validateObjectID(jobID); err != nil {
+		return nil, err
+	}
+	job, err := updateJSON(ctx, c, jobMetadataPath(jobID), func(job *models.Job) error {
+		if job.ID == "" {
+			return fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+		}
+		return mutate(job)
+	})
+	if err != nil {
+		logging.Error("ジョブの更新に失敗: id=%s, err=%v", jobID, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// ListJobs は保存されているすべてのジョブを返します
+// 読み込めないジョブはログに残して読み飛ばします
+func (c *Client) ListJobs(ctx context.Context) ([]*models.Job, error) {
+	names, err := c.bucket.List(ctx, jobMetadataPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("ジョブの一覧の取得に失敗: %w", err)
+	}
+	jobs := make([]*models.Job, 0, len(names))
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		var job models.Job
+		if err := c.readJSON(ctx, name, &job); err != nil {
+			logging.Warn("ジョブの読み込みに失敗したため読み飛ばします: path=%s, err=%v", name, err)
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// GetJobImage はジョブで使う画像を取得します
+func (c *Client) GetJobImage(ctx context.Context, jobID string) ([]byte, error) {
+	if err := validateObjectID(jobID); err != nil {
+		return nil, err
+	}
+	reader, err := c.bucket.Object(jobImagePath(jobID)).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: 画像がありません: %s", ErrJobNotFound, jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ジョブの画像の読み込みに失敗: %w", err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// DeleteJob はジョブと、ジョブで使う画像を削除します
+func (c *Client) DeleteJob(ctx context.Context, jobID string) error {
+	if err := validateObjectID(jobID); err != nil {
+		return err
+	}
+	return c.deleteObjects(ctx, []string{jobImagePath(jobID), jobMetadataPath(jobID)})
+}
+
+// DeleteJobImage はジョブで使う画像のみを削除します（終了したジョブの状態は残します）
+func (c *Client) DeleteJobImage(ctx context.Context, jobID string) error {
+	if err := validateObjectID(jobID); err != nil {
+		return err
+	}
+	return c.deleteObjects(ctx, []string{jobImagePath(jobID)})
+}
+
+// jobMetadataPath はジョブを保存するオブジェクトのパスを返します
+func jobMetadataPath(jobID string) string {
+	return jobMetadataPrefix + jobID + ".json"
+}
+
+// jobImagePath はジョブの画像を置くオブジェクトのパスを返します
+func jobImagePath(jobID string) string {
+	return jobsPrefix + jobID
+}
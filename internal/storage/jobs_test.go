@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+func TestJobs(t *testing.T) {
+	clients := map[string]func(t *testing.T) JobStore{
+		"Cloud Storage": func(t *testing.T) JobStore {
+			return &Client{bucket: NewMockBucket()}
+		},
+		"ローカル": func(t *testing.T) JobStore {
+			client, err := NewLocalClient(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLocalClient failed: %v", err)
+			}
+			return client
+		},
+	}
+	ctx := context.Background()
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t)
+			job := &models.Job{
+				ID:        "job_1",
+				Status:    models.JobPending,
+				AuthorID:  "author-1",
+				Input:     models.JobInput{ImageMimeType: "image/png", AuthorInterpretation: "解釈", Tags: []string{"夜"}},
+				CreatedAt: time.Now().UTC(),
+			}
+			if err := client.SaveJob(ctx, job, []byte("image data")); err != nil {
+				t.Fatalf("SaveJob failed: %v", err)
+			}
+			if err := client.SaveJob(ctx, &models.Job{ID: "job_2", Status: models.JobSucceeded}, []byte("other")); err != nil {
+				t.Fatalf("SaveJob failed: %v", err)
+			}
+
+			got, err := client.GetJob(ctx, "job_1")
+			if err != nil {
+				t.Fatalf("GetJob failed: %v", err)
+			}
+			if got.AuthorID != "author-1" || got.Input.AuthorInterpretation != "解釈" || len(got.Input.Tags) != 1 {
+				t.Errorf("unexpected job: %+v", got)
+			}
+			image, err := client.GetJobImage(ctx, "job_1")
+			if err != nil || string(image) != "image data" {
+				t.Errorf("unexpected job image: %q (err=%v)", image, err)
+			}
+
+			updated, err := client.UpdateJob(ctx, "job_1", func(job *models.Job) error {
+				job.Status = models.JobRunning
+				job.Attempts++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("UpdateJob failed: %v", err)
+			}
+			if updated.Status != models.JobRunning || updated.Attempts != 1 {
+				t.Errorf("unexpected updated job: %+v", updated)
+			}
+			if _, err := client.UpdateJob(ctx, "job_missing", func(job *models.Job) error { return nil }); !errors.Is(err, ErrJobNotFound) {
+				t.Errorf("expected ErrJobNotFound, got %v", err)
+			}
+
+			jobs, err := client.ListJobs(ctx)
+			if err != nil {
+				t.Fatalf("ListJobs failed: %v", err)
+			}
+			if len(jobs) != 2 {
+				t.Errorf("expected 2 jobs, got %d", len(jobs))
+			}
+
+			// 画像だけを削除しても状態は残る
+			if err := client.DeleteJobImage(ctx, "job_1"); err != nil {
+				t.Fatalf("DeleteJobImage failed: %v", err)
+			}
+			if _, err := client.GetJobImage(ctx, "job_1"); !errors.Is(err, ErrJobNotFound) {
+				t.Errorf("expected ErrJobNotFound for deleted image, got %v", err)
+			}
+			if _, err := client.GetJob(ctx, "job_1"); err != nil {
+				t.Errorf("expected job to remain, got %v", err)
+			}
+
+			if err := client.DeleteJob(ctx, "job_2"); err != nil {
+				t.Fatalf("DeleteJob failed: %v", err)
+			}
+			if _, err := client.GetJob(ctx, "job_2"); !errors.Is(err, ErrJobNotFound) {
+				t.Errorf("expected ErrJobNotFound, got %v", err)
+			}
+			if _, err := client.GetJob(ctx, "../job_1"); err == nil {
+				t.Error("expected error for invalid job ID, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+// ErrInvalidCursor はページングのカーソルが不正であることを表します
+var ErrInvalidCursor = errors.New("カーソルが不正です")
+
+// listCursor はページの最後のクイズを指すカーソルです
+// 作成日時とIDの組で位置を表すため、ページの間にクイズが追加・削除されても重複や欠落が起きません
+type listCursor struct {
+	createdAt time.Time
+	id        string
+}
+
+// encodeCursor はカーソルをクライアントに渡す文字列に変換します
+func encodeCursor(entry *models.QuizIndexEntry) string {
+	raw := strconv.FormatInt(entry.CreatedAt.UnixNano(), 10) + "/" + entry.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor はクライアントから受け取ったカーソルを解析します
+func decodeCursor(s string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), "/")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &listCursor{createdAt: time.Unix(0, n), id: id}, nil
+}
+
+// entryBefore は作成日時の古い順で a が b より前かを返します（同時刻はIDで比較）
+func entryBefore(a *models.QuizIndexEntry, createdAt time.Time, id string) bool {
+	if !a.CreatedAt.Equal(createdAt) {
+		return a.CreatedAt.Before(createdAt)
+	}
+	return a.ID < id
+}
+
+// ListQuizzes は条件に一致するクイズの概要を1ページ分取得します
+// インデックスと回答数のオブジェクトだけを読み込むため、ページの件数にかかわらず読み込みは2回で済みます
+func (c *Client) ListQuizzes(ctx context.Context, query *models.QuizListQuery) (*models.QuizPage, error) {
+	logging.Info("クイズ一覧の取得を開始: query=%+v", query)
+	if query.Limit <= 0 {
+		return nil, fmt.Errorf("取得件数は1以上を指定してください: %d", query.Limit)
+	}
+	var cursor *listCursor
+	if query.Cursor != "" {
+		var err error
+		if cursor, err = decodeCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	newestFirst := query.Order != models.SortOldestFirst
+
+	index, err := c.loadIndex(ctx)
+	if err != nil {
+		logging.Error("インデックスの読み込みに失敗: %v", err)
+		return nil, fmt.Errorf("クイズデータの読み込みに失敗: %w", err)
+	}
+
+	entries := make([]*models.QuizIndexEntry, 0, len(index.Entries))
+	for _, entry := range index.Entries {
+		if !query.Matches(entry) {
+			continue
+		}
+		// カーソルより後ろのエントリだけを残す
+		if cursor != nil {
+			if newestFirst && !entryBefore(entry, cursor.createdAt, cursor.id) {
+				continue
+			}
+			if !newestFirst && (entryBefore(entry, cursor.createdAt, cursor.id) || entry.ID == cursor.id) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if newestFirst {
+			return entryBefore(entries[j], entries[i].CreatedAt, entries[i].ID)
+		}
+		return entryBefore(entries[i], entries[j].CreatedAt, entries[j].ID)
+	})
+
+	page := &models.QuizPage{Quizzes: make([]*models.QuizSummary, 0, query.Limit)}
+	if len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+		page.NextCursor = encodeCursor(entries[len(entries)-1])
+	}
+
+	counts, err := c.loadPlayCounts(ctx)
+	if err != nil {
+		// 回答数が読めなくても一覧は表示できるようにする
+		logging.Warn("回答数の読み込みに失敗: %v", err)
+	}
+	for _, entry := range entries {
+		page.Quizzes = append(page.Quizzes, &models.QuizSummary{
+			QuizIndexEntry: *entry,
+			PlayCount:      counts[entry.ID],
+		})
+	}
+
+	logging.Info("クイズ一覧の取得に成功: クイズ数=%d", len(page.Quizzes))
+	return page, nil
+}
+
+// RebuildIndex はクイズ本体の内容でインデックスのエントリを作り直し、回答数を集計から数え直します
+// インデックスに項目を追加した際、既存のクイズに反映するために使用します
+func (c *Client) RebuildIndex(ctx context.Context) (int, error) {
+	logging.Info("インデックスの再構築を開始")
+
+	paths, err := c.bucket.List(ctx, quizzesPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("クイズの一覧の取得に失敗: %w", err)
+	}
+	quizzes := make([]*models.Quiz, 0, len(paths))
+	for _, path := range paths {
+		var quiz models.Quiz
+		if err := c.readJSON(ctx, path, &quiz); err != nil {
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				continue
+			}
+			return 0, fmt.Errorf("クイズの読み込みに失敗: path=%s: %w", path, err)
+		}
+		quizzes = append(quizzes, &quiz)
+	}
+
+	_, err = updateJSON(ctx, c, quizIndexPath, func(index *models.QuizIndex) error {
+		// 再構築中に保存されたクイズを失わないよう、既存のエントリは残したまま上書きする
+		for _, quiz := range quizzes {
+			upsertIndexEntry(index, quiz)
+		}
+		sort.SliceStable(index.Entries, func(i, j int) bool {
+			return index.Entries[i].CreatedAt.Before(index.Entries[j].CreatedAt)
+		})
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("インデックスの保存に失敗: %w", err)
+	}
+
+	if err := c.rebuildPlayCounts(ctx); err != nil {
+		return 0, err
+	}
+
+	logging.Info("インデックスの再構築に成功: クイズ数=%d", len(quizzes))
+	return len(quizzes), nil
+}
+
+// rebuildPlayCounts はクイズごとの集計から回答数を数え直します
+func (c *Client) rebuildPlayCounts(ctx context.Context) error {
+	paths, err := c.bucket.List(ctx, statsPrefix)
+	if err != nil {
+		return fmt.Errorf("集計の一覧の取得に失敗: %w", err)
+	}
+	counts := make(map[string]int, len(paths))
+	for _, path := range paths {
+		var stats models.QuizStats
+		if err := c.readJSON(ctx, path, &stats); err != nil {
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				continue
+			}
+			return fmt.Errorf("集計の読み込みに失敗: path=%s: %w", path, err)
+		}
+		if stats.Attempts > 0 {
+			counts[strings.TrimSuffix(strings.TrimPrefix(path, statsPrefix), ".json")] = stats.Attempts
+		}
+	}
+
+	_, err = updateJSON(ctx, c, playCountsPath, func(pc *playCounts) error {
+		pc.Counts = counts
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("回答数の保存に失敗: %w", err)
+	}
+	return nil
+}
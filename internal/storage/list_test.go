@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+func TestRebuildIndex(t *testing.T) {
+	client := &Client{bucket: NewMockBucket()}
+	ctx := context.Background()
+
+	// 作成者やタグを持たない古い形式のエントリを用意
+	quiz := &models.Quiz{ID: "quiz_1", AuthorID: "author-1", Tags: []string{"風景"}, CreatedAt: time.Now()}
+	if err := client.writeJSON(ctx, quizObjectPath(quiz.ID), quiz); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+	legacyIndex := &models.QuizIndex{Entries: []*models.QuizIndexEntry{{ID: quiz.ID, CreatedAt: quiz.CreatedAt}}}
+	if err := client.writeJSON(ctx, quizIndexPath, legacyIndex); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+
+	page, err := client.ListQuizzes(ctx, &models.QuizListQuery{AuthorID: "author-1", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListQuizzes failed: %v", err)
+	}
+	if len(page.Quizzes) != 0 {
+		t.Fatalf("expected legacy entry not to match, got %d", len(page.Quizzes))
+	}
+
+	// 回答数の複製が失われた場合も集計から数え直されること
+	stats := &models.QuizStats{QuizID: quiz.ID, Attempts: 4}
+	if err := client.writeJSON(ctx, statsObjectPath(quiz.ID), stats); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+
+	n, err := client.RebuildIndex(ctx)
+	if err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 quiz, got %d", n)
+	}
+
+	page, err = client.ListQuizzes(ctx, &models.QuizListQuery{AuthorID: "author-1", Tag: "風景", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListQuizzes failed: %v", err)
+	}
+	if len(page.Quizzes) != 1 || page.Quizzes[0].ID != quiz.ID {
+		t.Fatalf("expected rebuilt entry to match filters, got %+v", page.Quizzes)
+	}
+	if page.Quizzes[0].PlayCount != 4 {
+		t.Errorf("expected play count 4, got %d", page.Quizzes[0].PlayCount)
+	}
+}
+
+func TestListQuizzes_Status(t *testing.T) {
+	client := &Client{bucket: NewMockBucket()}
+	ctx := context.Background()
+
+	now := time.Now()
+	quizzes := []*models.Quiz{
+		{ID: "quiz_legacy", CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: "quiz_approved", Status: models.QuizApproved, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "quiz_flagged", Status: models.QuizFlagged, CreatedAt: now.Add(-time.Minute)},
+		{ID: "quiz_rejected", Status: models.QuizRejected, CreatedAt: now},
+		{ID: "quiz_reported", Reports: []models.QuizReport{{ReporterID: "player-1", Reason: "不適切"}}, CreatedAt: now.Add(-4 * time.Minute)},
+	}
+	for _, quiz := range quizzes {
+		if err := client.SaveQuiz(ctx, quiz); err != nil {
+			t.Fatalf("SaveQuiz failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query models.QuizListQuery
+		want  []string
+	}{
+		{name: "未指定の場合は公開されているクイズのみ", want: []string{"quiz_approved", "quiz_legacy", "quiz_reported"}},
+		{name: "保留中のクイズ", query: models.QuizListQuery{Status: models.QuizFlagged}, want: []string{"quiz_flagged"}},
+		{name: "拒否されたクイズ", query: models.QuizListQuery{Status: models.QuizRejected}, want: []string{"quiz_rejected"}},
+		{name: "すべてのクイズ", query: models.QuizListQuery{IncludeHidden: true}, want: []string{"quiz_rejected", "quiz_flagged", "quiz_approved", "quiz_legacy", "quiz_reported"}},
+		{name: "審査待ちのクイズ", query: models.QuizListQuery{IncludeHidden: true, NeedsReview: true}, want: []string{"quiz_flagged", "quiz_reported"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Order = models.SortNewestFirst
+			query.Limit = 10
+			page, err := client.ListQuizzes(ctx, &query)
+			if err != nil {
+				t.Fatalf("ListQuizzes failed: %v", err)
+			}
+			var got []string
+			for _, quiz := range page.Quizzes {
+				got = append(got, quiz.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("want %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+// LocalClient はローカルディレクトリをバケットとして扱うストレージクライアントです
+// GCP認証情報なしで開発できるように、Cloud Storageと同じオブジェクト配置を再現します
+// 画像の署名付きURLは発行できないため、画像は Cloud Storage の場合と同じくサーバーの画像プロキシで配信します
+type LocalClient struct {
+	*Client
+}
+
+// NewLocalClient は新しいローカルストレージクライアントを作成します
+func NewLocalClient(root string) (*LocalClient, error) {
+	logging.Info("ローカルストレージクライアントの初期化を開始: root=%s", root)
+	if root == "" {
+		return nil, fmt.Errorf("保存先ディレクトリが必要です")
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("保存先ディレクトリの解決に失敗: %w", err)
+	}
+	for _, dir := range []string{"images", "metadata"} {
+		if err := os.MkdirAll(filepath.Join(absRoot, dir), 0o755); err != nil {
+			logging.Error("ディレクトリの作成に失敗: %v", err)
+			return nil, fmt.Errorf("ディレクトリの作成に失敗: %w", err)
+		}
+	}
+
+	return &LocalClient{
+		Client: &Client{
+			bucket:  &localBucket{root: absRoot},
+			baseURL: "file://" + filepath.ToSlash(absRoot),
+		},
+	}, nil
+}
+
+// GenerateSignedURL はローカルストレージでは署名付きURLを発行できないため、画像のパスを確かめた上で ErrSigningUnavailable を返します
+// 呼び出し側はサーバーの画像プロキシで配信します
+func (c *LocalClient) GenerateSignedURL(ctx context.Context, objectPath string) (string, error) {
+	if objectPath == "" {
+		return "", fmt.Errorf("object path is empty")
+	}
+	if err := validateImagePath(objectPath); err != nil {
+		return "", fmt.Errorf("object is not public: %w", err)
+	}
+	return "", ErrSigningUnavailable
+}
+
+// GenerateUploadURL はローカルストレージでは署名付きURLを発行できないため、常に ErrSigningUnavailable を返します
+// クライアントはサーバーの分割アップロードのエンドポイントを使用します
+func (c *LocalClient) GenerateUploadURL(ctx context.Context, uploadID, contentType string, maxSize int64, expires time.Time) (string, map[string]string, error) {
+	return "", nil, ErrSigningUnavailable
+}
+
+// localBucket はローカルディレクトリを BucketHandle として扱います
+// 世代番号にはファイルの更新時刻（ナノ秒）を使用します
+type localBucket struct {
+	root string
+	// mu は世代番号の確認と書き込みを不可分にするためのロックです
+	mu sync.Mutex
+}
+
+// localObject はローカルファイルを ObjectHandle として扱います
+type localObject struct {
+	bucket *localBucket
+	path   string
+	err    error
+	conds  *storage.Conditions
+}
+
+func (b *localBucket) Object(name string) ObjectHandle {
+	path, err := b.resolve(name)
+	return &localObject{bucket: b, path: path, err: err}
+}
+
+func (b *localBucket) SignedURL(name string, opts *storage.SignedURLOptions) (string, error) {
+	return "", fmt.Errorf("local bucket does not support signed URLs")
+}
+
+func (b *localBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	// prefix を含むディレクトリだけを走査する
+	dir := b.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		resolved, err := b.resolve(prefix[:i])
+		if err != nil {
+			return nil, err
+		}
+		dir = resolved
+	}
+
+	var names []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		name := filepath.ToSlash(strings.TrimPrefix(path, b.root+string(filepath.Separator)))
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// resolve はオブジェクト名をルートディレクトリ配下のファイルパスに変換します
+func (b *localBucket) resolve(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("object name is empty")
+	}
+	path := filepath.Join(b.root, filepath.FromSlash(name))
+	if !strings.HasPrefix(path, b.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid object name: %s", name)
+	}
+	return path, nil
+}
+
+func (o *localObject) NewWriter(ctx context.Context) io.WriteCloser {
+	if o.err != nil {
+		return &localWriter{err: o.err}
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o755); err != nil {
+		return &localWriter{err: err}
+	}
+	// 書き込み途中のファイルが読まれないよう、一時ファイルに書いてからリネームします
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), ".tmp-*")
+	if err != nil {
+		return &localWriter{err: err}
+	}
+	return &localWriter{object: o, file: tmp}
+}
+
+func (o *localObject) NewReader(ctx context.Context) (io.ReadCloser, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	f, err := os.Open(o.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, storage.ErrObjectNotExist
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (o *localObject) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	reader, err := o.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := reader.(*os.File)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(f, length), Closer: f}, nil
+}
+
+// limitedReadCloser は読み出す長さを制限した ReadCloser です
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (o *localObject) Delete(ctx context.Context) error {
+	if o.err != nil {
+		return o.err
+	}
+	o.bucket.mu.Lock()
+	defer o.bucket.mu.Unlock()
+
+	if _, err := o.checkConditions(); err != nil {
+		return err
+	}
+	if err := os.Remove(o.path); err != nil {
+		if os.IsNotExist(err) {
+			return storage.ErrObjectNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+func (o *localObject) Attrs(ctx context.Context) (*storage.ObjectAttrs, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	info, err := os.Stat(o.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, storage.ErrObjectNotExist
+		}
+		return nil, err
+	}
+	return &storage.ObjectAttrs{
+		Name:        filepath.ToSlash(strings.TrimPrefix(o.path, o.bucket.root+string(filepath.Separator))),
+		Size:        info.Size(),
+		Generation:  info.ModTime().UnixNano(),
+		Updated:     info.ModTime(),
+		ContentType: mime.TypeByExtension(filepath.Ext(o.path)),
+	}, nil
+}
+
+func (o *localObject) If(conds storage.Conditions) ObjectHandle {
+	return &localObject{bucket: o.bucket, path: o.path, err: o.err, conds: &conds}
+}
+
+// checkConditions は条件付き操作の前提を確認し、現在の世代番号を返します
+// 呼び出し側で bucket.mu をロックしておく必要があります
+func (o *localObject) checkConditions() (int64, error) {
+	var current int64
+	info, err := os.Stat(o.path)
+	switch {
+	case err == nil:
+		current = info.ModTime().UnixNano()
+	case !os.IsNotExist(err):
+		return 0, err
+	}
+
+	if o.conds == nil {
+		return current, nil
+	}
+	if o.conds.DoesNotExist && current != 0 {
+		return current, fmt.Errorf("%w: object already exists", ErrPreconditionFailed)
+	}
+	if o.conds.GenerationMatch != 0 && o.conds.GenerationMatch != current {
+		return current, fmt.Errorf("%w: generation mismatch", ErrPreconditionFailed)
+	}
+	return current, nil
+}
+
+// localWriter はClose時に一時ファイルを目的のパスへ移動します
+type localWriter struct {
+	object *localObject
+	file   *os.File
+	err    error
+}
+
+func (w *localWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.file.Write(p)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *localWriter) Close() error {
+	if w.file == nil {
+		return w.err
+	}
+	defer os.Remove(w.file.Name())
+	if err := w.file.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	o := w.object
+	o.bucket.mu.Lock()
+	defer o.bucket.mu.Unlock()
+
+	current, err := o.checkConditions()
+	if err != nil {
+		return err
+	}
+	// 更新のたびに世代番号（更新時刻）が必ず増えるようにする
+	generation := time.Now()
+	if generation.UnixNano() <= current {
+		generation = time.Unix(0, current+1)
+	}
+	if err := os.Chtimes(w.file.Name(), generation, generation); err != nil {
+		return err
+	}
+	return os.Rename(w.file.Name(), o.path)
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLocalClient_GenerateSignedURL(t *testing.T) {
+	client, err := NewLocalClient(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+
+	// ローカルでは署名付きURLを作れないため、サービスが画像プロキシのURLに切り替える
+	if _, err := client.GenerateSignedURL(context.Background(), "images/quiz_1.jpg"); !errors.Is(err, ErrSigningUnavailable) {
+		t.Errorf("expected ErrSigningUnavailable, got %v", err)
+	}
+	if _, err := client.GenerateSignedURL(context.Background(), "metadata/quizzes.json"); err == nil || errors.Is(err, ErrSigningUnavailable) {
+		t.Errorf("expected validation error for non-image object, got %v", err)
+	}
+}
+
+func TestLocalBucket_RejectsPathTraversal(t *testing.T) {
+	bucket := &localBucket{root: t.TempDir()}
+
+	writer := bucket.Object("../outside.json").NewWriter(context.Background())
+	if _, err := writer.Write([]byte("data")); err == nil {
+		t.Error("expected write error for path traversal, got nil")
+	}
+	if err := writer.Close(); err == nil {
+		t.Error("expected close error for path traversal, got nil")
+	}
+
+	if _, err := bucket.Object("../outside.json").NewReader(context.Background()); err == nil {
+		t.Error("expected read error for path traversal, got nil")
+	}
+}
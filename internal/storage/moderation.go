@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+// authorBansPrefix は投稿者の利用停止を保存するオブジェクトの接頭辞です
+const authorBansPrefix = "metadata/bans/"
+
+// ErrBanNotFound は投稿者が利用停止されていないことを表します
+var ErrBanNotFound = errors.New("利用停止の記録がありません")
+
+// BanStore は投稿者の利用停止のストレージ操作のインターフェースを定義します
+// Client と LocalClient が実装します
+type BanStore interface {
+	SaveAuthorBan(ctx context.Context, ban *models.AuthorBan) error
+	GetAuthorBan(ctx context.Context, authorID string) (*models.AuthorBan, error)
+}
+
+// SaveAuthorBan は投稿者の利用停止を保存します（すでに利用停止されている場合は上書きします）
+func (c *Client) SaveAuthorBan(ctx context.Context, ban *models.AuthorBan) error {
+	if ban == nil || ban.AuthorID == "" {
+		return fmt.Errorf("利用停止する投稿者が必要です")
+	}
+	if err := c.writeJSON(ctx, authorBanPath(ban.AuthorID), ban); err != nil {
+		logging.Error("利用停止の保存に失敗: author=%s, err=%v", ban.AuthorID, err)
+		return fmt.Errorf("利用停止の保存に失敗: %w", err)
+	}
+	return nil
+}
+
+// GetAuthorBan は投稿者の利用停止を取得します
+// 利用停止されていない場合は ErrBanNotFound を返します
+func (c *Client) GetAuthorBan(ctx context.Context, authorID string) (*models.AuthorBan, error) {
+	if authorID == "" {
+		return nil, fmt.Errorf("%w: 投稿者が空です", ErrBanNotFound)
+	}
+	var ban models.AuthorBan
+	if err := c.readJSON(ctx, authorBanPath(authorID), &ban); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrBanNotFound, authorID)
+		}
+		return nil, fmt.Errorf("利用停止の読み込みに失敗: %w", err)
+	}
+	return &ban, nil
+}
+
+// authorBanPath は投稿者の利用停止を保存するオブジェクトのパスを返します
+// 利用者のIDには認証基盤によって / などが含まれるため、ハッシュ値をオブジェクト名にします
+func authorBanPath(authorID string) string {
+	sum := sha256.Sum256([]byte(authorID))
+	return authorBansPrefix + hex.EncodeToString(sum[:]) + ".json"
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+func TestAuthorBans(t *testing.T) {
+	clients := map[string]func(t *testing.T) BanStore{
+		"Cloud Storage": func(t *testing.T) BanStore {
+			return &Client{bucket: NewMockBucket()}
+		},
+		"ローカル": func(t *testing.T) BanStore {
+			client, err := NewLocalClient(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLocalClient failed: %v", err)
+			}
+			return client
+		},
+	}
+	ctx := context.Background()
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t)
+
+			// 認証基盤によっては / や | を含むIDもそのまま扱えること
+			authorID := "google-oauth2|../user/1"
+			if _, err := client.GetAuthorBan(ctx, authorID); !errors.Is(err, ErrBanNotFound) {
+				t.Fatalf("expected ErrBanNotFound, got %v", err)
+			}
+
+			ban := &models.AuthorBan{AuthorID: authorID, Reason: "スパム", BannedBy: "admin", CreatedAt: time.Now().UTC()}
+			if err := client.SaveAuthorBan(ctx, ban); err != nil {
+				t.Fatalf("SaveAuthorBan failed: %v", err)
+			}
+			got, err := client.GetAuthorBan(ctx, authorID)
+			if err != nil {
+				t.Fatalf("GetAuthorBan failed: %v", err)
+			}
+			if got.AuthorID != authorID || got.Reason != "スパム" || got.BannedBy != "admin" {
+				t.Errorf("unexpected ban: %+v", got)
+			}
+
+			if _, err := client.GetAuthorBan(ctx, "author-2"); !errors.Is(err, ErrBanNotFound) {
+				t.Errorf("expected ErrBanNotFound for other author, got %v", err)
+			}
+			if err := client.SaveAuthorBan(ctx, &models.AuthorBan{}); err == nil {
+				t.Error("expected error for empty author")
+			}
+		})
+	}
+}
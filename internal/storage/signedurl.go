@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+)
+
+const (
+	// DefaultSignedURLTTL は署名付きURLの既定の有効期間です
+	DefaultSignedURLTTL = 15 * time.Minute
+)
+
+// SigningMethod は画像URLの発行方法です
+type SigningMethod string
+
+const (
+	// SigningMethodV4 は V4 署名の署名付きURLを発行します（既定）
+	SigningMethodV4 SigningMethod = "v4"
+	// SigningMethodV2 は V2 署名の署名付きURLを発行します
+	SigningMethodV2 SigningMethod = "v2"
+	// SigningMethodProxy は署名せず、常にサーバーの画像プロキシで配信します
+	SigningMethodProxy SigningMethod = "proxy"
+)
+
+// ClientOption は Client の設定を変更します
+type ClientOption func(*Client)
+
+// WithSignedURLTTL は署名付きURLの有効期間を設定します（0 以下の場合は DefaultSignedURLTTL）
+func WithSignedURLTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.signedURLTTL = ttl
+	}
+}
+
+// WithSigningMethod は画像URLの発行方法を設定します
+func WithSigningMethod(method SigningMethod) ClientOption {
+	return func(c *Client) {
+		c.signingMethod = method
+	}
+}
+
+// GenerateSignedURL は、指定された画像を一定時間だけ読み取れる署名付きURLを生成します
+// 署名に必要な認証情報がない場合（サービスアカウントの鍵や signBlob の権限がない場合など）や SigningMethodProxy の場合は
+// ErrSigningUnavailable を返します（呼び出し側はサーバーの画像プロキシで配信します）
+func (c *Client) GenerateSignedURL(ctx context.Context, objectPath string) (string, error) {
+	if objectPath == "" {
+		return "", fmt.Errorf("object path is empty")
+	}
+	if err := validateImagePath(objectPath); err != nil {
+		return "", fmt.Errorf("object is not public: %w", err)
+	}
+
+	if c.signingMethod == SigningMethodProxy {
+		return "", ErrSigningUnavailable
+	}
+
+	ttl := c.signedURLTTL
+	if ttl <= 0 {
+		ttl = DefaultSignedURLTTL
+	}
+	scheme := storage.SigningSchemeV4
+	if c.signingMethod == SigningMethodV2 {
+		scheme = storage.SigningSchemeV2
+	}
+	signedURL, err := c.bucket.SignedURL(objectPath, &storage.SignedURLOptions{
+		Method:  http.MethodGet,
+		Expires: time.Now().Add(ttl),
+		Scheme:  scheme,
+	})
+	if err != nil {
+		logging.Warn("署名付きURLの生成に失敗したため画像プロキシで配信します: path=%s, err=%v", objectPath, err)
+		return "", fmt.Errorf("%w: %v", ErrSigningUnavailable, err)
+	}
+	logging.Debug("署名付きURLを生成しました: path=%s, ttl=%s", objectPath, ttl)
+	return signedURL, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// signingBucket は署名のオプションを記録するモックバケットです
+// err が設定されている場合は、認証情報がない環境と同じく署名に失敗します
+type signingBucket struct {
+	*MockBucket
+	err  error
+	opts *storage.SignedURLOptions
+}
+
+func (b *signingBucket) SignedURL(name string, opts *storage.SignedURLOptions) (string, error) {
+	b.opts = opts
+	if b.err != nil {
+		return "", b.err
+	}
+	return "https://storage.googleapis.com/test-bucket/" + name + "?X-Goog-Signature=abc", nil
+}
+
+func TestGenerateSignedURL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("既定は V4 署名", func(t *testing.T) {
+		bucket := &signingBucket{MockBucket: NewMockBucket()}
+		client := &Client{bucket: bucket}
+
+		before := time.Now()
+		got, err := client.GenerateSignedURL(ctx, "images/quiz_1.jpg")
+		if err != nil {
+			t.Fatalf("GenerateSignedURL failed: %v", err)
+		}
+		if got != "https://storage.googleapis.com/test-bucket/images/quiz_1.jpg?X-Goog-Signature=abc" {
+			t.Errorf("unexpected URL: %s", got)
+		}
+		if bucket.opts.Method != "GET" || bucket.opts.Scheme != storage.SigningSchemeV4 {
+			t.Errorf("unexpected options: method=%s, scheme=%v", bucket.opts.Method, bucket.opts.Scheme)
+		}
+		if ttl := bucket.opts.Expires.Sub(before); ttl < DefaultSignedURLTTL || ttl > DefaultSignedURLTTL+time.Minute {
+			t.Errorf("expected TTL about %s, got %s", DefaultSignedURLTTL, ttl)
+		}
+	})
+
+	t.Run("有効期間と署名方式の指定", func(t *testing.T) {
+		bucket := &signingBucket{MockBucket: NewMockBucket()}
+		client := &Client{bucket: bucket}
+		WithSignedURLTTL(time.Hour)(client)
+		WithSigningMethod(SigningMethodV2)(client)
+
+		before := time.Now()
+		if _, err := client.GenerateSignedURL(ctx, "images/quiz_1_thumbnail.jpg"); err != nil {
+			t.Fatalf("GenerateSignedURL failed: %v", err)
+		}
+		if bucket.opts.Scheme != storage.SigningSchemeV2 {
+			t.Errorf("expected V2 signing, got %v", bucket.opts.Scheme)
+		}
+		if ttl := bucket.opts.Expires.Sub(before); ttl < time.Hour || ttl > time.Hour+time.Minute {
+			t.Errorf("expected TTL about 1h, got %s", ttl)
+		}
+	})
+
+	t.Run("署名できない場合は画像プロキシ", func(t *testing.T) {
+		bucket := &signingBucket{MockBucket: NewMockBucket(), err: errors.New("storage: unable to detect default GoogleAccessID")}
+		client := &Client{bucket: bucket}
+
+		if _, err := client.GenerateSignedURL(ctx, "images/quiz_1.png"); !errors.Is(err, ErrSigningUnavailable) {
+			t.Errorf("expected ErrSigningUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("常に画像プロキシ", func(t *testing.T) {
+		bucket := &signingBucket{MockBucket: NewMockBucket()}
+		client := &Client{bucket: bucket}
+		WithSigningMethod(SigningMethodProxy)(client)
+
+		if _, err := client.GenerateSignedURL(ctx, "images/quiz_1.png"); !errors.Is(err, ErrSigningUnavailable) {
+			t.Errorf("expected ErrSigningUnavailable, got %v", err)
+		}
+		if bucket.opts != nil {
+			t.Error("expected SignedURL not to be called")
+		}
+	})
+
+	t.Run("画像以外のオブジェクトは拒否", func(t *testing.T) {
+		client := &Client{bucket: &signingBucket{MockBucket: NewMockBucket()}}
+		for _, path := range []string{"metadata/index.json", "images/../metadata/index.json"} {
+			if _, err := client.GenerateSignedURL(ctx, path); err == nil {
+				t.Errorf("expected error for %q, got nil", path)
+			}
+		}
+	})
+}
@@ -3,6 +3,9 @@ package storage
 import (
 	"context"
 	"io"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,14 +14,21 @@ import (
 )
 
 // MockBucket はCloud Storageのモック
+// 世代番号による条件付き書き込みを再現し、並行アクセスにも対応します
 type MockBucket struct {
-	objects map[string][]byte
+	mu           sync.Mutex
+	objects      map[string][]byte
+	generations  map[string]int64
+	contentTypes map[string]string
+	nextGen      int64
 }
 
 // NewMockBucket はテスト用のモックバケットを作成します
 func NewMockBucket() *MockBucket {
 	return &MockBucket{
-		objects: make(map[string][]byte),
+		objects:      make(map[string][]byte),
+		generations:  make(map[string]int64),
+		contentTypes: make(map[string]string),
 	}
 }
 
@@ -35,34 +45,133 @@ func (b *MockBucket) SignedURL(name string, opts *storage.SignedURLOptions) (str
 	return "https://example.com/" + name, nil
 }
 
+// List は prefix で始まるオブジェクトの名前を返します
+func (b *MockBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var names []string
+	for name := range b.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// put はオブジェクトを保存し、新しい世代番号を割り当てます（呼び出し側でロックすること）
+func (b *MockBucket) put(name string, data []byte) {
+	b.nextGen++
+	b.objects[name] = data
+	b.generations[name] = b.nextGen
+}
+
 // MockObject はCloud Storage Objectのモック
 type MockObject struct {
 	name   string
 	bucket *MockBucket
+	conds  *storage.Conditions
 }
 
 // NewWriter は新しいWriterを返します
 func (o *MockObject) NewWriter(ctx context.Context) io.WriteCloser {
 	return &MockWriter{
-		name:   o.name,
-		bucket: o.bucket,
+		object: o,
 	}
 }
 
 // NewReader は新しいReaderを返します
 func (o *MockObject) NewReader(ctx context.Context) (io.ReadCloser, error) {
+	o.bucket.mu.Lock()
+	defer o.bucket.mu.Unlock()
+
+	data, ok := o.bucket.objects[o.name]
+	if !ok {
+		return nil, storage.ErrObjectNotExist
+	}
+	return &MockReader{data: data}, nil
+}
+
+// NewRangeReader は offset バイト目から length バイトを読み出すReaderを返します
+func (o *MockObject) NewRangeReader(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	o.bucket.mu.Lock()
+	defer o.bucket.mu.Unlock()
+
 	data, ok := o.bucket.objects[o.name]
 	if !ok {
 		return nil, storage.ErrObjectNotExist
 	}
+	data = data[min(offset, int64(len(data))):]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
 	return &MockReader{data: data}, nil
 }
 
+// Delete はオブジェクトを削除します
+func (o *MockObject) Delete(ctx context.Context) error {
+	o.bucket.mu.Lock()
+	defer o.bucket.mu.Unlock()
+
+	if _, ok := o.bucket.objects[o.name]; !ok {
+		return storage.ErrObjectNotExist
+	}
+	if err := o.checkConditions(); err != nil {
+		return err
+	}
+	delete(o.bucket.objects, o.name)
+	delete(o.bucket.generations, o.name)
+	delete(o.bucket.contentTypes, o.name)
+	return nil
+}
+
+// Attrs はオブジェクトの属性を返します
+func (o *MockObject) Attrs(ctx context.Context) (*storage.ObjectAttrs, error) {
+	o.bucket.mu.Lock()
+	defer o.bucket.mu.Unlock()
+
+	data, ok := o.bucket.objects[o.name]
+	if !ok {
+		return nil, storage.ErrObjectNotExist
+	}
+	return &storage.ObjectAttrs{
+		Name:        o.name,
+		Size:        int64(len(data)),
+		Generation:  o.bucket.generations[o.name],
+		ContentType: o.bucket.contentTypes[o.name],
+	}, nil
+}
+
+// If は条件付きのオブジェクト参照を返します
+func (o *MockObject) If(conds storage.Conditions) ObjectHandle {
+	return &MockObject{name: o.name, bucket: o.bucket, conds: &conds}
+}
+
+// checkConditions は条件付き操作の前提を確認します（呼び出し側でロックすること）
+func (o *MockObject) checkConditions() error {
+	if o.conds == nil {
+		return nil
+	}
+	current := o.bucket.generations[o.name]
+	if o.conds.DoesNotExist && current != 0 {
+		return ErrPreconditionFailed
+	}
+	if o.conds.GenerationMatch != 0 && o.conds.GenerationMatch != current {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
 // MockWriter はCloud Storage Object Writerのモック
 type MockWriter struct {
-	name   string
-	bucket *MockBucket
-	data   []byte
+	object      *MockObject
+	data        []byte
+	contentType string
+}
+
+func (w *MockWriter) SetContentType(contentType string) {
+	w.contentType = contentType
 }
 
 func (w *MockWriter) Write(p []byte) (n int, err error) {
@@ -71,7 +180,15 @@ func (w *MockWriter) Write(p []byte) (n int, err error) {
 }
 
 func (w *MockWriter) Close() error {
-	w.bucket.objects[w.name] = w.data
+	bucket := w.object.bucket
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	if err := w.object.checkConditions(); err != nil {
+		return err
+	}
+	bucket.put(w.object.name, w.data)
+	bucket.contentTypes[w.object.name] = w.contentType
 	return nil
 }
 
@@ -96,19 +213,35 @@ func (r *MockReader) Close() error {
 
 func TestSaveImage(t *testing.T) {
 	tests := []struct {
-		name      string
-		imageData []byte
-		wantError bool
+		name        string
+		imageData   []byte
+		contentType string
+		wantExt     string
+		wantError   bool
 	}{
 		{
-			name:      "正常系：有効な画像データ",
-			imageData: []byte("test image data"),
-			wantError: false,
+			name:        "正常系：JPEG",
+			imageData:   []byte("test image data"),
+			contentType: "image/jpeg",
+			wantExt:     ".jpg",
+		},
+		{
+			name:        "正常系：PNG",
+			imageData:   []byte("test image data"),
+			contentType: "image/png",
+			wantExt:     ".png",
 		},
 		{
-			name:      "異常系：空の画像データ",
-			imageData: nil,
-			wantError: true,
+			name:        "異常系：空の画像データ",
+			imageData:   nil,
+			contentType: "image/jpeg",
+			wantError:   true,
+		},
+		{
+			name:        "異常系：対応していない形式",
+			imageData:   []byte("test image data"),
+			contentType: "text/plain; charset=utf-8",
+			wantError:   true,
 		},
 	}
 
@@ -120,7 +253,7 @@ func TestSaveImage(t *testing.T) {
 				baseURL: "gs://test-bucket",
 			}
 
-			imagePath, err := client.SaveImage(context.Background(), tt.imageData)
+			imagePath, err := client.SaveImage(context.Background(), tt.imageData, tt.contentType)
 
 			if tt.wantError {
 				if err == nil {
@@ -133,9 +266,16 @@ func TestSaveImage(t *testing.T) {
 				return
 			}
 
-			if !hasPrefix(imagePath, "images/") {
+			if !hasPrefix(imagePath, "images/") || !strings.HasSuffix(imagePath, tt.wantExt) {
 				t.Errorf("invalid image path format: %s", imagePath)
 			}
+			attrs, err := mockBucket.Object(imagePath).Attrs(context.Background())
+			if err != nil {
+				t.Fatalf("Attrs failed: %v", err)
+			}
+			if attrs.ContentType != tt.contentType {
+				t.Errorf("expected Content-Type %q, got %q", tt.contentType, attrs.ContentType)
+			}
 		})
 	}
 }
@@ -186,3 +326,74 @@ func TestSaveAndGetQuiz(t *testing.T) {
 func hasPrefix(s, prefix string) bool {
 	return len(s) >= len(prefix) && s[0:len(prefix)] == prefix
 }
+
+func TestGetQuizReadsSingleObject(t *testing.T) {
+	mockBucket := NewMockBucket()
+	client := &Client{bucket: mockBucket}
+
+	// インデックスを経由せず、クイズ本体のオブジェクトだけで取得できること
+	mockBucket.put("metadata/quizzes/direct.json", []byte(`{"id":"direct","author_interpretation":"投稿者の解釈"}`))
+
+	quiz, err := client.GetQuiz(context.Background(), "direct")
+	if err != nil {
+		t.Fatalf("GetQuiz failed: %v", err)
+	}
+	if quiz.AuthorInterpretation != "投稿者の解釈" {
+		t.Errorf("unexpected quiz: %+v", quiz)
+	}
+
+	if _, err := client.GetQuiz(context.Background(), "../index"); err == nil {
+		t.Error("expected error for invalid quiz ID, got nil")
+	}
+}
+
+func TestMigrateLegacyQuizzes(t *testing.T) {
+	mockBucket := NewMockBucket()
+	client := &Client{bucket: mockBucket}
+	ctx := context.Background()
+
+	mockBucket.put("metadata/quizzes.json", []byte(`{"quizzes":[
+		{"id":"quiz_2","author_interpretation":"二番目","created_at":"2024-03-21T10:00:00Z"},
+		{"id":"quiz_1","author_interpretation":"一番目","created_at":"2024-03-20T10:00:00Z"}
+	]}`))
+
+	migrated, err := client.MigrateLegacyQuizzes(ctx)
+	if err != nil {
+		t.Fatalf("MigrateLegacyQuizzes failed: %v", err)
+	}
+	if migrated != 2 {
+		t.Errorf("expected 2 migrated quizzes, got %d", migrated)
+	}
+
+	quiz, err := client.GetQuiz(ctx, "quiz_1")
+	if err != nil {
+		t.Fatalf("GetQuiz failed: %v", err)
+	}
+	if quiz.AuthorInterpretation != "一番目" {
+		t.Errorf("unexpected quiz: %+v", quiz)
+	}
+
+	quizzes, err := client.GetQuizzes(ctx)
+	if err != nil {
+		t.Fatalf("GetQuizzes failed: %v", err)
+	}
+	if len(quizzes) != 2 || quizzes[0].ID != "quiz_1" || quizzes[1].ID != "quiz_2" {
+		t.Errorf("expected quizzes ordered by created_at, got %+v", quizzes)
+	}
+
+	if _, ok := mockBucket.objects["metadata/quizzes.json"]; ok {
+		t.Error("expected legacy blob to be removed")
+	}
+	if _, ok := mockBucket.objects["metadata/quizzes.migrated.json"]; !ok {
+		t.Error("expected legacy blob to be backed up")
+	}
+
+	// 2回目の実行では何もしない
+	migrated, err = client.MigrateLegacyQuizzes(ctx)
+	if err != nil {
+		t.Fatalf("second MigrateLegacyQuizzes failed: %v", err)
+	}
+	if migrated != 0 {
+		t.Errorf("expected no quizzes on second run, got %d", migrated)
+	}
+}
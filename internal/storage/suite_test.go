@@ -0,0 +1,522 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+// runStorageClientSuite は StorageClient の実装が満たすべき振る舞いを検証します
+// Cloud Storage 版とローカル版の両方で同じテストを実行します
+func runStorageClientSuite(t *testing.T, newClient func(t *testing.T) StorageClient) {
+	ctx := context.Background()
+
+	t.Run("画像の保存", func(t *testing.T) {
+		client := newClient(t)
+
+		imagePath, err := client.SaveImage(ctx, []byte("test image data"), "image/png")
+		if err != nil {
+			t.Fatalf("SaveImage failed: %v", err)
+		}
+		if !hasPrefix(imagePath, "images/") || !strings.HasSuffix(imagePath, ".png") {
+			t.Errorf("invalid image path format: %s", imagePath)
+		}
+
+		if _, err := client.SaveImage(ctx, nil, "image/png"); err == nil {
+			t.Error("expected error for empty image, got nil")
+		}
+
+		variantPath, err := client.SaveImageVariant(ctx, imagePath, models.ImageThumbnail, []byte("thumbnail data"))
+		if err != nil {
+			t.Fatalf("SaveImageVariant failed: %v", err)
+		}
+		if want := models.VariantPath(imagePath, models.ImageThumbnail); variantPath != want {
+			t.Errorf("expected variant path %s, got %s", want, variantPath)
+		}
+		data, err := client.GetImage(ctx, variantPath)
+		if err != nil {
+			t.Fatalf("GetImage failed: %v", err)
+		}
+		if string(data) != "thumbnail data" {
+			t.Errorf("unexpected variant data: %q", data)
+		}
+
+		if _, err := client.SaveImageVariant(ctx, "metadata/index.json", models.ImageThumbnail, []byte("x")); err == nil {
+			t.Error("expected error for path outside images/, got nil")
+		}
+		if _, err := client.SaveImageVariant(ctx, imagePath, models.ImageFull, []byte("x")); err == nil {
+			t.Error("expected error for overwriting the original image, got nil")
+		}
+
+		// 保存していない派生画像が含まれていても、元の画像と保存済みの派生画像を削除できること
+		if err := client.DeleteImage(ctx, models.QuizImage{Path: imagePath, Variants: models.ImageVariants}); err != nil {
+			t.Fatalf("DeleteImage failed: %v", err)
+		}
+		for _, path := range []string{imagePath, variantPath} {
+			if _, err := client.GetImage(ctx, path); err == nil {
+				t.Errorf("expected image %s to be deleted", path)
+			}
+		}
+		if err := client.DeleteImage(ctx, models.QuizImage{Path: "metadata/index.json"}); err == nil {
+			t.Error("expected error for path outside images/, got nil")
+		}
+	})
+
+	t.Run("クイズの保存と取得", func(t *testing.T) {
+		client := newClient(t)
+
+		quiz := &models.Quiz{
+			ID:                   "suite-quiz",
+			ImagePath:            "images/suite.jpg",
+			AuthorInterpretation: "投稿者の解釈",
+			AIInterpretation:     "AIの解釈",
+			CreatedAt:            time.Now().UTC().Truncate(time.Second),
+		}
+		if err := client.SaveQuiz(ctx, quiz); err != nil {
+			t.Fatalf("SaveQuiz failed: %v", err)
+		}
+
+		got, err := client.GetQuiz(ctx, quiz.ID)
+		if err != nil {
+			t.Fatalf("GetQuiz failed: %v", err)
+		}
+		if got.ID != quiz.ID || got.ImagePath != quiz.ImagePath ||
+			got.AuthorInterpretation != quiz.AuthorInterpretation ||
+			got.AIInterpretation != quiz.AIInterpretation ||
+			!got.CreatedAt.Equal(quiz.CreatedAt) {
+			t.Errorf("saved quiz mismatch: want %+v, got %+v", quiz, got)
+		}
+	})
+
+	t.Run("存在しないクイズの取得", func(t *testing.T) {
+		client := newClient(t)
+
+		if _, err := client.GetQuiz(ctx, "missing"); err == nil {
+			t.Error("expected error for missing quiz, got nil")
+		}
+		if _, err := client.GetQuiz(ctx, ""); err == nil {
+			t.Error("expected error for empty quiz ID, got nil")
+		}
+	})
+
+	t.Run("クイズ一覧の取得", func(t *testing.T) {
+		client := newClient(t)
+
+		quizzes, err := client.GetQuizzes(ctx)
+		if err != nil {
+			t.Fatalf("GetQuizzes failed: %v", err)
+		}
+		if len(quizzes) != 0 {
+			t.Errorf("expected empty list, got %d quizzes", len(quizzes))
+		}
+
+		for _, id := range []string{"quiz-a", "quiz-b"} {
+			if err := client.SaveQuiz(ctx, &models.Quiz{ID: id, CreatedAt: time.Now()}); err != nil {
+				t.Fatalf("SaveQuiz failed: %v", err)
+			}
+		}
+
+		quizzes, err = client.GetQuizzes(ctx)
+		if err != nil {
+			t.Fatalf("GetQuizzes failed: %v", err)
+		}
+		if len(quizzes) != 2 {
+			t.Errorf("expected 2 quizzes, got %d", len(quizzes))
+		}
+	})
+
+	t.Run("クイズ一覧のページングと絞り込み", func(t *testing.T) {
+		client := newClient(t)
+
+		base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		for i, q := range []struct {
+			author string
+			tags   []string
+		}{
+			{"author-1", []string{"風景"}},
+			{"author-2", nil},
+			{"author-1", []string{"風景", "夜"}},
+			{"author-1", nil},
+			{"author-2", []string{"夜"}},
+		} {
+			quiz := &models.Quiz{
+				ID:        fmt.Sprintf("list-%d", i),
+				AuthorID:  q.author,
+				Tags:      q.tags,
+				CreatedAt: base.Add(time.Duration(i) * time.Hour),
+			}
+			if err := client.SaveQuiz(ctx, quiz); err != nil {
+				t.Fatalf("SaveQuiz failed: %v", err)
+			}
+		}
+
+		ids := func(page *models.QuizPage) []string {
+			var ids []string
+			for _, quiz := range page.Quizzes {
+				ids = append(ids, quiz.ID)
+			}
+			return ids
+		}
+
+		// 新しい順に2件ずつ取得し、カーソルで最後まで辿れること
+		var got []string
+		query := &models.QuizListQuery{Order: models.SortNewestFirst, Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("pagination did not terminate")
+			}
+			page, err := client.ListQuizzes(ctx, query)
+			if err != nil {
+				t.Fatalf("ListQuizzes failed: %v", err)
+			}
+			got = append(got, ids(page)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		want := []string{"list-4", "list-3", "list-2", "list-1", "list-0"}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("newest first: want %v, got %v", want, got)
+		}
+
+		tests := []struct {
+			name     string
+			query    models.QuizListQuery
+			want     []string
+			wantNext bool
+		}{
+			{
+				name:     "古い順",
+				query:    models.QuizListQuery{Order: models.SortOldestFirst, Limit: 3},
+				want:     []string{"list-0", "list-1", "list-2"},
+				wantNext: true,
+			},
+			{
+				name:  "作成者",
+				query: models.QuizListQuery{AuthorID: "author-1", Order: models.SortOldestFirst, Limit: 10},
+				want:  []string{"list-0", "list-2", "list-3"},
+			},
+			{
+				name:  "タグ",
+				query: models.QuizListQuery{Tag: "夜", Limit: 10},
+				want:  []string{"list-4", "list-2"},
+			},
+			{
+				name: "作成日時の範囲",
+				query: models.QuizListQuery{
+					CreatedFrom: base.Add(time.Hour),
+					CreatedTo:   base.Add(3 * time.Hour),
+					Order:       models.SortOldestFirst,
+					Limit:       10,
+				},
+				want: []string{"list-1", "list-2"},
+			},
+		}
+		for _, tt := range tests {
+			page, err := client.ListQuizzes(ctx, &tt.query)
+			if err != nil {
+				t.Fatalf("%s: ListQuizzes failed: %v", tt.name, err)
+			}
+			if fmt.Sprint(ids(page)) != fmt.Sprint(tt.want) || (page.NextCursor != "") != tt.wantNext {
+				t.Errorf("%s: want %v, got %v (next=%q)", tt.name, tt.want, ids(page), page.NextCursor)
+			}
+		}
+
+		// 古い順でもカーソルの続きから取得できること
+		first, err := client.ListQuizzes(ctx, &models.QuizListQuery{Order: models.SortOldestFirst, Limit: 3})
+		if err != nil {
+			t.Fatalf("ListQuizzes failed: %v", err)
+		}
+		second, err := client.ListQuizzes(ctx, &models.QuizListQuery{Order: models.SortOldestFirst, Limit: 3, Cursor: first.NextCursor})
+		if err != nil {
+			t.Fatalf("ListQuizzes failed: %v", err)
+		}
+		if want := []string{"list-3", "list-4"}; fmt.Sprint(ids(second)) != fmt.Sprint(want) || second.NextCursor != "" {
+			t.Errorf("oldest first second page: want %v, got %v", want, ids(second))
+		}
+
+		if _, err := client.ListQuizzes(ctx, &models.QuizListQuery{Limit: 1, Cursor: "!!"}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor, got %v", err)
+		}
+	})
+
+	t.Run("クイズ一覧の概要", func(t *testing.T) {
+		client := newClient(t)
+
+		quiz := &models.Quiz{
+			ID:                "summary-quiz",
+			Title:             "夜の街",
+			ImagePath:         "images/summary.jpg",
+			AuthorID:          "author-1",
+			AuthorName:        "投稿者",
+			AIInterpretations: []string{"AI1", "AI2"},
+			CreatedAt:         time.Now(),
+		}
+		if err := client.SaveQuiz(ctx, quiz); err != nil {
+			t.Fatalf("SaveQuiz failed: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			answer := &models.Answer{ID: fmt.Sprintf("answer_%d", i), QuizID: quiz.ID, PlayerID: "p1", AnsweredAt: time.Now()}
+			if err := client.SaveAnswer(ctx, answer); err != nil {
+				t.Fatalf("SaveAnswer failed: %v", err)
+			}
+		}
+
+		page, err := client.ListQuizzes(ctx, &models.QuizListQuery{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListQuizzes failed: %v", err)
+		}
+		if len(page.Quizzes) != 1 {
+			t.Fatalf("expected 1 quiz, got %d", len(page.Quizzes))
+		}
+		got := page.Quizzes[0]
+		if got.Title != quiz.Title || got.ImagePath != quiz.ImagePath || got.AuthorName != quiz.AuthorName ||
+			got.PlayCount != 3 || got.Difficulty() != models.DifficultyNormal {
+			t.Errorf("unexpected summary: %+v", got)
+		}
+	})
+
+	t.Run("全クイズの削除", func(t *testing.T) {
+		client := newClient(t)
+
+		imagePath, err := client.SaveImage(ctx, []byte("test image data"), "image/jpeg")
+		if err != nil {
+			t.Fatalf("SaveImage failed: %v", err)
+		}
+		if err := client.SaveQuiz(ctx, &models.Quiz{ID: "to-delete", ImagePath: imagePath, CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("SaveQuiz failed: %v", err)
+		}
+		// インデックスに載っていない画像も削除されること
+		orphan, err := client.SaveImage(ctx, []byte("orphan image"), "image/jpeg")
+		if err != nil {
+			t.Fatalf("SaveImage failed: %v", err)
+		}
+		// 作成中のジョブとアップロードも、削除の後にクイズにならないよう削除されること
+		pending, ok := client.(interface {
+			JobStore
+			UploadStore
+		})
+		if !ok {
+			t.Fatalf("client does not implement JobStore and UploadStore: %T", client)
+		}
+		if err := pending.SaveJob(ctx, &models.Job{ID: "job_1", Status: models.JobPending}, []byte("job image")); err != nil {
+			t.Fatalf("SaveJob failed: %v", err)
+		}
+		if err := pending.SaveUploadSession(ctx, &models.UploadSession{ID: "upload_1", Size: 5}); err != nil {
+			t.Fatalf("SaveUploadSession failed: %v", err)
+		}
+		if _, err := pending.AppendUpload(ctx, "upload_1", 0, []byte("image")); err != nil {
+			t.Fatalf("AppendUpload failed: %v", err)
+		}
+		if err := client.DeleteAllQuizzes(ctx); err != nil {
+			t.Fatalf("DeleteAllQuizzes failed: %v", err)
+		}
+
+		quizzes, err := client.GetQuizzes(ctx)
+		if err != nil {
+			t.Fatalf("GetQuizzes failed: %v", err)
+		}
+		if len(quizzes) != 0 {
+			t.Errorf("expected no quizzes after delete, got %d", len(quizzes))
+		}
+		if _, err := client.GetQuiz(ctx, "to-delete"); err == nil {
+			t.Error("expected deleted quiz to be missing")
+		}
+		for _, path := range []string{imagePath, orphan} {
+			if _, err := client.GetImage(ctx, path); err == nil {
+				t.Errorf("expected image %s to be deleted", path)
+			}
+		}
+		if _, err := pending.GetJob(ctx, "job_1"); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("expected job to be deleted, got %v", err)
+		}
+		if _, err := pending.GetJobImage(ctx, "job_1"); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("expected job image to be deleted, got %v", err)
+		}
+		if _, err := pending.GetUploadSession(ctx, "upload_1"); err == nil {
+			t.Error("expected upload session to be deleted")
+		}
+		if size, err := pending.UploadedSize(ctx, "upload_1"); err != nil || size != 0 {
+			t.Errorf("expected uploaded image to be deleted, got size=%d, err=%v", size, err)
+		}
+	})
+
+	t.Run("クイズの更新", func(t *testing.T) {
+		client := newClient(t)
+
+		if err := client.SaveQuiz(ctx, &models.Quiz{ID: "to-update", AuthorInterpretation: "元の解釈", CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("SaveQuiz failed: %v", err)
+		}
+
+		updated, err := client.UpdateQuiz(ctx, "to-update", func(quiz *models.Quiz) error {
+			quiz.AuthorInterpretation = "新しい解釈"
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateQuiz failed: %v", err)
+		}
+		if updated.AuthorInterpretation != "新しい解釈" {
+			t.Errorf("unexpected updated quiz: %+v", updated)
+		}
+
+		got, err := client.GetQuiz(ctx, "to-update")
+		if err != nil {
+			t.Fatalf("GetQuiz failed: %v", err)
+		}
+		if got.AuthorInterpretation != "新しい解釈" {
+			t.Errorf("update was not saved: %+v", got)
+		}
+
+		_, err = client.UpdateQuiz(ctx, "missing", func(quiz *models.Quiz) error { return nil })
+		if !errors.Is(err, ErrQuizNotFound) {
+			t.Errorf("expected ErrQuizNotFound, got %v", err)
+		}
+	})
+
+	t.Run("クイズの削除", func(t *testing.T) {
+		client := newClient(t)
+
+		imagePath, err := client.SaveImage(ctx, []byte("test image data"), "image/jpeg")
+		if err != nil {
+			t.Fatalf("SaveImage failed: %v", err)
+		}
+		thumbnailPath, err := client.SaveImageVariant(ctx, imagePath, models.ImageThumbnail, []byte("thumbnail data"))
+		if err != nil {
+			t.Fatalf("SaveImageVariant failed: %v", err)
+		}
+		for _, quiz := range []*models.Quiz{
+			{ID: "to-delete", ImagePath: imagePath, ImageVariants: []models.ImageVariant{models.ImageThumbnail}, CreatedAt: time.Now()},
+			{ID: "to-keep", CreatedAt: time.Now()},
+		} {
+			if err := client.SaveQuiz(ctx, quiz); err != nil {
+				t.Fatalf("SaveQuiz failed: %v", err)
+			}
+		}
+		answer := &models.Answer{ID: "answer_1", QuizID: "to-delete", PlayerID: "p1", DecoyIndex: 0, AnsweredAt: time.Now()}
+		if err := client.SaveAnswer(ctx, answer); err != nil {
+			t.Fatalf("SaveAnswer failed: %v", err)
+		}
+
+		if err := client.DeleteQuiz(ctx, "to-delete"); err != nil {
+			t.Fatalf("DeleteQuiz failed: %v", err)
+		}
+
+		quizzes, err := client.GetQuizzes(ctx)
+		if err != nil {
+			t.Fatalf("GetQuizzes failed: %v", err)
+		}
+		if len(quizzes) != 1 || quizzes[0].ID != "to-keep" {
+			t.Errorf("expected only to-keep to remain, got %+v", quizzes)
+		}
+		if _, err := client.GetQuiz(ctx, "to-delete"); !errors.Is(err, ErrQuizNotFound) {
+			t.Errorf("expected ErrQuizNotFound, got %v", err)
+		}
+		for _, path := range []string{imagePath, thumbnailPath} {
+			if _, err := client.GetImage(ctx, path); err == nil {
+				t.Errorf("expected image %s to be deleted", path)
+			}
+		}
+		stats, err := client.GetQuizStats(ctx, "to-delete")
+		if err != nil {
+			t.Fatalf("GetQuizStats failed: %v", err)
+		}
+		if stats.Attempts != 0 {
+			t.Errorf("expected stats to be deleted, got %+v", stats)
+		}
+
+		if err := client.DeleteQuiz(ctx, "to-delete"); !errors.Is(err, ErrQuizNotFound) {
+			t.Errorf("expected ErrQuizNotFound, got %v", err)
+		}
+	})
+
+	t.Run("回答の記録と集計", func(t *testing.T) {
+		client := newClient(t)
+
+		stats, err := client.GetQuizStats(ctx, "stats-quiz")
+		if err != nil {
+			t.Fatalf("GetQuizStats failed: %v", err)
+		}
+		if stats.Attempts != 0 {
+			t.Errorf("expected no attempts, got %d", stats.Attempts)
+		}
+
+		answers := []*models.Answer{
+			{ID: "answer_1", QuizID: "stats-quiz", PlayerID: "p1", DecoyIndex: 0, AnsweredAt: time.Now()},
+			{ID: "answer_2", QuizID: "stats-quiz", PlayerID: "p2", DecoyIndex: -1, IsCorrect: true, AnsweredAt: time.Now()},
+			// 同じプレイヤーの2回目の回答は人数に数えない
+			{ID: "answer_3", QuizID: "stats-quiz", PlayerID: "p1", DecoyIndex: -1, IsCorrect: true, AnsweredAt: time.Now()},
+		}
+		for _, answer := range answers {
+			if err := client.SaveAnswer(ctx, answer); err != nil {
+				t.Fatalf("SaveAnswer failed: %v", err)
+			}
+		}
+
+		stats, err = client.GetQuizStats(ctx, "stats-quiz")
+		if err != nil {
+			t.Fatalf("GetQuizStats failed: %v", err)
+		}
+		if stats.Attempts != 3 || stats.CorrectAttempts != 2 || stats.PlayerCount != 2 || stats.FooledPlayerCount != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+
+		if err := client.SaveAnswer(ctx, &models.Answer{ID: "answer_4", QuizID: "../escape"}); err == nil {
+			t.Error("expected error for invalid quiz ID, got nil")
+		}
+	})
+
+	t.Run("画像URLの生成", func(t *testing.T) {
+		client := newClient(t)
+
+		imagePath, err := client.SaveImage(ctx, []byte("test image data"), "image/jpeg")
+		if err != nil {
+			t.Fatalf("SaveImage failed: %v", err)
+		}
+
+		// 署名付きURLを発行できないストレージ（ローカルなど）は画像プロキシで配信する
+		url, err := client.GenerateSignedURL(ctx, imagePath)
+		if err != nil && !errors.Is(err, ErrSigningUnavailable) {
+			t.Fatalf("GenerateSignedURL failed: %v", err)
+		}
+		if err == nil && url == "" {
+			t.Error("expected non-empty URL")
+		}
+
+		if _, err := client.GenerateSignedURL(ctx, ""); err == nil {
+			t.Error("expected error for empty path, got nil")
+		}
+	})
+
+	t.Run("存在しない画像", func(t *testing.T) {
+		client := newClient(t)
+
+		if _, err := client.GetImage(ctx, "images/missing.jpg"); !errors.Is(err, ErrImageNotFound) {
+			t.Errorf("expected ErrImageNotFound, got %v", err)
+		}
+	})
+}
+
+func TestClientBehavior(t *testing.T) {
+	runStorageClientSuite(t, func(t *testing.T) StorageClient {
+		return &Client{
+			bucket:  NewMockBucket(),
+			baseURL: "gs://test-bucket",
+		}
+	})
+}
+
+func TestLocalClientBehavior(t *testing.T) {
+	runStorageClientSuite(t, func(t *testing.T) StorageClient {
+		client, err := NewLocalClient(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewLocalClient failed: %v", err)
+		}
+		return client
+	})
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/logging"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+const (
+	// uploadsPrefix はクイズの作成前にアップロードされた画像を一時的に置くオブジェクトの接頭辞です
+	// 放置されたオブジェクトはバケットのライフサイクルルールで削除します
+	uploadsPrefix = "uploads/"
+	// uploadSessionsPrefix はアップロードセッションを保存するオブジェクトの接頭辞です
+	uploadSessionsPrefix = "metadata/uploads/"
+)
+
+var (
+	// ErrUploadNotFound は指定されたアップロードセッションが存在しないことを表します
+	ErrUploadNotFound = errors.New("アップロードが見つかりません")
+	// ErrUploadOffsetMismatch は分割アップロードの開始位置が受信済みのバイト数と一致しないことを表します
+	ErrUploadOffsetMismatch = errors.New("アップロードの開始位置が受信済みのバイト数と一致しません")
+	// ErrSigningUnavailable は署名付きURLを発行できないことを表します
+	// 署名用の認証情報がない場合や、ローカルストレージ、SigningMethodProxy の場合に返されます
+	ErrSigningUnavailable = errors.New("署名付きURLを発行できません")
+)
+
+// UploadStore はアップロードセッションのストレージ操作のインターフェースを定義します
+// Client と LocalClient が実装します
+type UploadStore interface {
+	SaveUploadSession(ctx context.Context, session *models.UploadSession) error
+	GetUploadSession(ctx context.Context, uploadID string) (*models.UploadSession, error)
+	DeleteUploadSession(ctx context.Context, uploadID string) error
+	GenerateUploadURL(ctx context.Context, uploadID, contentType string, maxSize int64, expires time.Time) (string, map[string]string, error)
+	UploadedSize(ctx context.Context, uploadID string) (int64, error)
+	AppendUpload(ctx context.Context, uploadID string, offset int64, data []byte) (int64, error)
+	OpenUpload(ctx context.Context, uploadID string) (io.ReadCloser, error)
+}
+
+// UploadObjectPath はアップロードセッションの画像を一時的に置くオブジェクトのパスを返します
+func UploadObjectPath(uploadID string) string {
+	return uploadsPrefix + uploadID
+}
+
+// SaveUploadSession はアップロードセッションを保存します
+func (c *Client) SaveUploadSession(ctx context.Context, session *models.UploadSession) error {
+	if session == nil {
+		return fmt.Errorf("アップロードセッションが必要です")
+	}
+	if err := validateObjectID(session.ID); err != nil {
+		return err
+	}
+	if err := c.writeJSON(ctx, uploadSessionPath(session.ID), session); err != nil {
+		logging.Error("アップロードセッションの保存に失敗: id=%s, err=%v", session.ID, err)
+		return fmt.Errorf("アップロードセッションの保存に失敗: %w", err)
+	}
+	return nil
+}
+
+// GetUploadSession はアップロードセッションを取得します
+func (c *Client) GetUploadSession(ctx context.Context, uploadID string) (*models.UploadSession, error) {
+	if err := validateObjectID(uploadID); err != nil {
+		return nil, err
+	}
+	var session models.UploadSession
+	if err := c.readJSON(ctx, uploadSessionPath(uploadID), &session); err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrUploadNotFound, uploadID)
+		}
+		return nil, fmt.Errorf("アップロードセッションの読み込みに失敗: %w", err)
+	}
+	return &session, nil
+}
+
+// DeleteUploadSession はアップロードセッションと、アップロードされた画像を削除します
+func (c *Client) DeleteUploadSession(ctx context.Context, uploadID string) error {
+	if err := validateObjectID(uploadID); err != nil {
+		return err
+	}
+	return c.deleteObjects(ctx, []string{UploadObjectPath(uploadID), uploadSessionPath(uploadID)})
+}
+
+// GenerateUploadURL はアップロードセッションの画像をクライアントがバケットに直接 PUT するための署名付きURLを生成します
+// headers は PUT の際にクライアントが送る必要のあるヘッダーです（署名に含まれるため省略できません）
+// 署名できない場合は ErrSigningUnavailable を返します
+func (c *Client) GenerateUploadURL(ctx context.Context, uploadID, contentType string, maxSize int64, expires time.Time) (string, map[string]string, error) {
+	if err := validateObjectID(uploadID); err != nil {
+		return "", nil, err
+	}
+	if c.signingMethod == SigningMethodProxy {
+		return "", nil, ErrSigningUnavailable
+	}
+
+	// バケット側でもサイズの上限を検証させる
+	headers := map[string]string{
+		"Content-Type":                contentType,
+		"x-goog-content-length-range": fmt.Sprintf("0,%d", maxSize),
+	}
+	scheme := storage.SigningSchemeV4
+	if c.signingMethod == SigningMethodV2 {
+		scheme = storage.SigningSchemeV2
+	}
+	signedURL, err := c.bucket.SignedURL(UploadObjectPath(uploadID), &storage.SignedURLOptions{
+		Method:      http.MethodPut,
+		Expires:     expires,
+		Scheme:      scheme,
+		ContentType: contentType,
+		Headers:     []string{"x-goog-content-length-range:" + headers["x-goog-content-length-range"]},
+	})
+	if err != nil {
+		logging.Warn("アップロード用の署名付きURLの生成に失敗: id=%s, err=%v", uploadID, err)
+		return "", nil, fmt.Errorf("%w: %v", ErrSigningUnavailable, err)
+	}
+	return signedURL, headers, nil
+}
+
+// UploadedSize はアップロードセッションの受信済みのバイト数を返します（まだ何も受信していない場合は 0）
+func (c *Client) UploadedSize(ctx context.Context, uploadID string) (int64, error) {
+	if err := validateObjectID(uploadID); err != nil {
+		return 0, err
+	}
+	attrs, err := c.bucket.Object(UploadObjectPath(uploadID)).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("アップロードの属性の取得に失敗: %w", err)
+	}
+	return attrs.Size, nil
+}
+
+// AppendUpload はアップロードセッションの画像の末尾にデータを追加し、受信済みのバイト数を返します
+// offset が受信済みのバイト数と一致しない場合は、受信済みのバイト数と ErrUploadOffsetMismatch を返します
+// オブジェクトは追記できないため、受信済みのデータと合わせて書き直します（画像の大きさには上限があるため許容します）
+func (c *Client) AppendUpload(ctx context.Context, uploadID string, offset int64, data []byte) (int64, error) {
+	if err := validateObjectID(uploadID); err != nil {
+		return 0, err
+	}
+	objectPath := UploadObjectPath(uploadID)
+	obj := c.bucket.Object(objectPath)
+
+	var received []byte
+	var generation int64
+	attrs, err := obj.Attrs(ctx)
+	switch {
+	case errors.Is(err, storage.ErrObjectNotExist):
+	case err != nil:
+		return 0, fmt.Errorf("アップロードの属性の取得に失敗: %w", err)
+	default:
+		generation = attrs.Generation
+		reader, err := obj.NewReader(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("アップロードの読み込みに失敗: %w", err)
+		}
+		received, err = io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return 0, fmt.Errorf("アップロードの読み込みに失敗: %w", err)
+		}
+	}
+	if offset != int64(len(received)) {
+		return int64(len(received)), fmt.Errorf("%w: offset=%d, received=%d", ErrUploadOffsetMismatch, offset, len(received))
+	}
+
+	// 読み込み後に他のリクエストが追記した場合は、世代番号の条件で検出する
+	conds := storage.Conditions{GenerationMatch: generation}
+	if generation == 0 {
+		conds = storage.Conditions{DoesNotExist: true}
+	}
+	writer := obj.If(conds).NewWriter(ctx)
+	if _, err := io.Copy(writer, io.MultiReader(bytes.NewReader(received), bytes.NewReader(data))); err != nil {
+		writer.Close()
+		return 0, fmt.Errorf("書き込みに失敗: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		if errors.Is(err, ErrPreconditionFailed) {
+			size, _ := c.UploadedSize(ctx, uploadID)
+			return size, fmt.Errorf("%w: 他のリクエストと競合しました", ErrUploadOffsetMismatch)
+		}
+		return 0, fmt.Errorf("保存に失敗: %w", err)
+	}
+	return int64(len(received) + len(data)), nil
+}
+
+// OpenUpload はアップロードセッションの画像を読み出す Reader を返します
+// まだ何もアップロードされていない場合は ErrUploadNotFound を返します
+func (c *Client) OpenUpload(ctx context.Context, uploadID string) (io.ReadCloser, error) {
+	if err := validateObjectID(uploadID); err != nil {
+		return nil, err
+	}
+	reader, err := c.bucket.Object(UploadObjectPath(uploadID)).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: 画像がアップロードされていません: %s", ErrUploadNotFound, uploadID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("アップロードの読み込みに失敗: %w", err)
+	}
+	return reader, nil
+}
+
+// uploadSessionPath はアップロードセッションを保存するオブジェクトのパスを返します
+func uploadSessionPath(uploadID string) string {
+	return uploadSessionsPrefix + uploadID + ".json"
+}
+
+// validateObjectID はオブジェクトのパスに使えるID（アップロードID・ジョブID）かを検証します
+func validateObjectID(id string) error {
+	if id == "" || strings.ContainsAny(id, "/\\") || strings.Contains(id, "..") {
+		return fmt.Errorf("不正なIDです: %q", id)
+	}
+	return nil
+}
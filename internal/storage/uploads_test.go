@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/zenn-dev/zenn-ai-hackathon/internal/models"
+)
+
+func TestUploadSessions(t *testing.T) {
+	clients := map[string]func(t *testing.T) UploadStore{
+		"Cloud Storage": func(t *testing.T) UploadStore {
+			return &Client{bucket: NewMockBucket()}
+		},
+		"ローカル": func(t *testing.T) UploadStore {
+			client, err := NewLocalClient(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLocalClient failed: %v", err)
+			}
+			return client
+		},
+	}
+	ctx := context.Background()
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			client := newClient(t)
+			session := &models.UploadSession{
+				ID:        "upload_1",
+				AuthorID:  "author-1",
+				Filename:  "photo.png",
+				Size:      10,
+				ExpiresAt: time.Now().Add(time.Hour).UTC(),
+			}
+			if err := client.SaveUploadSession(ctx, session); err != nil {
+				t.Fatalf("SaveUploadSession failed: %v", err)
+			}
+			got, err := client.GetUploadSession(ctx, "upload_1")
+			if err != nil {
+				t.Fatalf("GetUploadSession failed: %v", err)
+			}
+			if got.AuthorID != "author-1" || got.Filename != "photo.png" || got.Size != 10 {
+				t.Errorf("unexpected session: %+v", got)
+			}
+
+			// まだ何もアップロードされていない
+			if size, err := client.UploadedSize(ctx, "upload_1"); err != nil || size != 0 {
+				t.Errorf("expected size 0, got %d (err=%v)", size, err)
+			}
+			if _, err := client.OpenUpload(ctx, "upload_1"); !errors.Is(err, ErrUploadNotFound) {
+				t.Errorf("expected ErrUploadNotFound, got %v", err)
+			}
+
+			// 分割して追記する
+			if received, err := client.AppendUpload(ctx, "upload_1", 0, []byte("hello ")); err != nil || received != 6 {
+				t.Fatalf("AppendUpload failed: received=%d, err=%v", received, err)
+			}
+			received, err := client.AppendUpload(ctx, "upload_1", 2, []byte("again"))
+			if !errors.Is(err, ErrUploadOffsetMismatch) || received != 6 {
+				t.Errorf("expected ErrUploadOffsetMismatch with received 6, got received=%d, err=%v", received, err)
+			}
+			if received, err := client.AppendUpload(ctx, "upload_1", 6, []byte("world")); err != nil || received != 11 {
+				t.Fatalf("AppendUpload failed: received=%d, err=%v", received, err)
+			}
+			if size, err := client.UploadedSize(ctx, "upload_1"); err != nil || size != 11 {
+				t.Errorf("expected size 11, got %d (err=%v)", size, err)
+			}
+
+			reader, err := client.OpenUpload(ctx, "upload_1")
+			if err != nil {
+				t.Fatalf("OpenUpload failed: %v", err)
+			}
+			data, _ := io.ReadAll(reader)
+			reader.Close()
+			if string(data) != "hello world" {
+				t.Errorf("unexpected upload: %q", data)
+			}
+
+			// セッションと画像の両方が削除される
+			if err := client.DeleteUploadSession(ctx, "upload_1"); err != nil {
+				t.Fatalf("DeleteUploadSession failed: %v", err)
+			}
+			if _, err := client.GetUploadSession(ctx, "upload_1"); !errors.Is(err, ErrUploadNotFound) {
+				t.Errorf("expected ErrUploadNotFound, got %v", err)
+			}
+			if _, err := client.OpenUpload(ctx, "upload_1"); !errors.Is(err, ErrUploadNotFound) {
+				t.Errorf("expected ErrUploadNotFound after delete, got %v", err)
+			}
+
+			for _, id := range []string{"", "../upload_1", "a/b"} {
+				if _, err := client.GetUploadSession(ctx, id); err == nil {
+					t.Errorf("expected error for upload ID %q, got nil", id)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateUploadURL(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+
+	t.Run("署名付きの PUT", func(t *testing.T) {
+		bucket := &signingBucket{MockBucket: NewMockBucket()}
+		client := &Client{bucket: bucket}
+
+		got, headers, err := client.GenerateUploadURL(ctx, "upload_1", "image/png", 1024, expires)
+		if err != nil {
+			t.Fatalf("GenerateUploadURL failed: %v", err)
+		}
+		if got != "https://storage.googleapis.com/test-bucket/uploads/upload_1?X-Goog-Signature=abc" {
+			t.Errorf("unexpected URL: %s", got)
+		}
+		if bucket.opts.Method != "PUT" || bucket.opts.ContentType != "image/png" || !bucket.opts.Expires.Equal(expires) {
+			t.Errorf("unexpected options: %+v", bucket.opts)
+		}
+		if len(bucket.opts.Headers) != 1 || bucket.opts.Headers[0] != "x-goog-content-length-range:0,1024" {
+			t.Errorf("expected content length range to be signed, got %v", bucket.opts.Headers)
+		}
+		if headers["Content-Type"] != "image/png" || headers["x-goog-content-length-range"] != "0,1024" {
+			t.Errorf("unexpected headers: %v", headers)
+		}
+	})
+
+	t.Run("署名できない場合", func(t *testing.T) {
+		bucket := &signingBucket{MockBucket: NewMockBucket(), err: errors.New("no credentials")}
+		client := &Client{bucket: bucket}
+		if _, _, err := client.GenerateUploadURL(ctx, "upload_1", "image/png", 1024, expires); !errors.Is(err, ErrSigningUnavailable) {
+			t.Errorf("expected ErrSigningUnavailable, got %v", err)
+		}
+
+		client = &Client{bucket: &signingBucket{MockBucket: NewMockBucket()}, signingMethod: SigningMethodProxy}
+		if _, _, err := client.GenerateUploadURL(ctx, "upload_1", "image/png", 1024, expires); !errors.Is(err, ErrSigningUnavailable) {
+			t.Errorf("expected ErrSigningUnavailable for proxy, got %v", err)
+		}
+
+		local, err := NewLocalClient(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewLocalClient failed: %v", err)
+		}
+		if _, _, err := local.GenerateUploadURL(ctx, "upload_1", "image/png", 1024, expires); !errors.Is(err, ErrSigningUnavailable) {
+			t.Errorf("expected ErrSigningUnavailable for local, got %v", err)
+		}
+	})
+
+	t.Run("V2 署名", func(t *testing.T) {
+		bucket := &signingBucket{MockBucket: NewMockBucket()}
+		client := &Client{bucket: bucket, signingMethod: SigningMethodV2}
+		if _, _, err := client.GenerateUploadURL(ctx, "upload_1", "image/png", 1024, expires); err != nil {
+			t.Fatalf("GenerateUploadURL failed: %v", err)
+		}
+		if bucket.opts.Scheme != storage.SigningSchemeV2 {
+			t.Errorf("expected V2 scheme, got %v", bucket.opts.Scheme)
+		}
+	})
+}